@@ -47,6 +47,8 @@ func toAppResponse(app *model.App) model.AppResponse {
 		Status:      app.Status,
 		CreatedAt:   app.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   app.UpdatedAt.Format(time.RFC3339),
+
+		Branding: app.Branding,
 	}
 }
 
@@ -117,6 +119,9 @@ func (h *AppHandler) UpdateApp(c *gin.Context) {
 	app.Name = req.Name
 	app.Description = req.Description
 	app.Status = req.Status
+	if req.Branding != nil {
+		app.Branding = *req.Branding
+	}
 
 	if err := h.appService.UpdateApp(c.Request.Context(), app); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -6,6 +6,7 @@ import (
 
 	"lauth/internal/model"
 	"lauth/internal/service"
+	"lauth/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -63,6 +64,8 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid username or password"})
 		case service.ErrUserDisabled:
 			c.JSON(http.StatusForbidden, gin.H{"error": "user is disabled"})
+		case service.ErrUnmetAuthenticationRequirements:
+			c.JSON(http.StatusForbidden, gin.H{"error": model.ErrorUnmetAuthenticationRequirements})
 		case service.ErrPluginRequired:
 			// 当需要插件验证时，返回验证相关信息
 			c.JSON(http.StatusAccepted, gin.H{
@@ -200,36 +203,26 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 // ValidateToken 验证Token并返回用户信息
 func (h *AuthHandler) ValidateToken(c *gin.Context) {
-	var accessToken string
-
-	// 优先从Cookie中获取access_token
-	accessToken, err := c.Cookie("access_token")
-	if err != nil {
-		// 如果Cookie中没有，则从Authorization头获取
-		auth := c.GetHeader("Authorization")
-		if auth == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing access token"})
-			return
-		}
-
-		if !strings.HasPrefix(auth, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization scheme"})
-			return
-		}
-
-		accessToken = auth[len("Bearer "):]
+	accessToken, dpop, ok := extractAccessToken(c)
+	if !ok {
+		return
 	}
 
-	// 验证Token并获取用户信息
-	userInfo, err := h.authService.ValidateTokenAndGetUser(c.Request.Context(), accessToken)
+	// 验证Token并获取用户信息，资源服务器通过resource参数传入自身的资源标识
+	userInfo, err := h.authService.ValidateTokenAndGetUser(c.Request.Context(), accessToken, c.Query("resource"), dpop)
 	if err != nil {
 		switch err {
 		case service.ErrInvalidToken:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		case service.ErrInvalidAudience:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid audience"})
 		case service.ErrTokenExpired:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "token expired"})
 		case service.ErrTokenRevoked:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
+		case service.ErrInvalidDPoPProof:
+			c.Header("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid dpop proof"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate token"})
 		}
@@ -241,29 +234,15 @@ func (h *AuthHandler) ValidateToken(c *gin.Context) {
 
 // ValidateTokenAndRuleRequest 组合验证请求
 type ValidateTokenAndRuleRequest struct {
-	Data map[string]interface{} `json:"data" binding:"required"`
+	Data     map[string]interface{} `json:"data" binding:"required"`
+	Resource string                 `json:"resource"` // 调用方的资源标识(RFC 8707)
 }
 
 // ValidateTokenAndRule 组合验证令牌和规则
 func (h *AuthHandler) ValidateTokenAndRule(c *gin.Context) {
-	var accessToken string
-
-	// 优先从Cookie中获取access_token
-	accessToken, err := c.Cookie("access_token")
-	if err != nil {
-		// 如果Cookie中没有，则从Authorization头获取
-		auth := c.GetHeader("Authorization")
-		if auth == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing access token"})
-			return
-		}
-
-		if !strings.HasPrefix(auth, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization scheme"})
-			return
-		}
-
-		accessToken = auth[len("Bearer "):]
+	accessToken, dpop, ok := extractAccessToken(c)
+	if !ok {
+		return
 	}
 
 	// 解析请求体
@@ -274,15 +253,20 @@ func (h *AuthHandler) ValidateTokenAndRule(c *gin.Context) {
 	}
 
 	// 验证Token并获取用户信息
-	userInfo, err := h.authService.ValidateTokenAndRuleWithUser(c.Request.Context(), accessToken, req.Data)
+	userInfo, err := h.authService.ValidateTokenAndRuleWithUser(c.Request.Context(), accessToken, req.Resource, dpop, req.Data)
 	if err != nil {
 		switch err {
 		case service.ErrInvalidToken:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		case service.ErrInvalidAudience:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid audience"})
 		case service.ErrTokenExpired:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "token expired"})
 		case service.ErrTokenRevoked:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
+		case service.ErrInvalidDPoPProof:
+			c.Header("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid dpop proof"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate"})
 		}
@@ -291,3 +275,24 @@ func (h *AuthHandler) ValidateTokenAndRule(c *gin.Context) {
 
 	c.JSON(http.StatusOK, userInfo)
 }
+
+// extractAccessToken 获取待验证的访问令牌，优先从Cookie中获取，其次从Authorization头获取
+// 以DPoP方案携带时同时返回请求附带的证明，获取失败时已写入错误响应
+func extractAccessToken(c *gin.Context) (string, *model.DPoPPresentation, bool) {
+	if accessToken, err := c.Cookie(middleware.CookieAccessToken); err == nil {
+		return accessToken, nil, true
+	}
+
+	auth := c.GetHeader("Authorization")
+	switch {
+	case auth == "":
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing access token"})
+	case strings.HasPrefix(auth, middleware.BearerSchema):
+		return auth[len(middleware.BearerSchema):], nil, true
+	case strings.HasPrefix(auth, middleware.DPoPSchema):
+		return auth[len(middleware.DPoPSchema):], middleware.NewDPoPPresentation(c), true
+	default:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization scheme"})
+	}
+	return "", nil, false
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 
+	"lauth/internal/audit"
 	"lauth/internal/model"
 	"lauth/internal/service"
 	"lauth/pkg/middleware"
@@ -14,13 +16,21 @@ import (
 
 // AuthorizationHandler 授权处理器
 type AuthorizationHandler struct {
-	authService service.AuthorizationService
+	authService   service.AuthorizationService
+	deviceService service.DeviceAuthorizationService
+	logoutService service.LogoutService
 }
 
 // NewAuthorizationHandler 创建授权处理器实例
-func NewAuthorizationHandler(authService service.AuthorizationService) *AuthorizationHandler {
+func NewAuthorizationHandler(
+	authService service.AuthorizationService,
+	deviceService service.DeviceAuthorizationService,
+	logoutService service.LogoutService,
+) *AuthorizationHandler {
 	return &AuthorizationHandler{
-		authService: authService,
+		authService:   authService,
+		deviceService: deviceService,
+		logoutService: logoutService,
 	}
 }
 
@@ -29,9 +39,29 @@ func (h *AuthorizationHandler) Register(group *gin.RouterGroup, authMiddleware *
 	oauth := group.Group("/oauth")
 	{
 		// 授权端点
-		oauth.GET("/authorize", authMiddleware.HandleAuth(), h.HandleAuthorize)
+		// 未登录时由授权服务根据prompt返回login_required
+		oauth.GET("/authorize", authMiddleware.HandleOptionalAuth(), h.HandleAuthorize)
+		// 授权同意端点
+		oauth.POST("/consent", authMiddleware.HandleAuth(), h.HandleConsent)
+		// 用户已授权客户端管理
+		oauth.GET("/consents", authMiddleware.HandleAuth(), h.ListConsents)
+		oauth.DELETE("/consents/:client_id", authMiddleware.HandleAuth(), h.RevokeConsent)
 		// 令牌端点
 		oauth.POST("/token", h.HandleToken)
+		// 推送授权请求端点(RFC 9126)
+		oauth.POST("/par", h.HandlePushedAuthorization)
+		// 令牌内省端点
+		oauth.POST("/introspect", h.HandleIntrospect)
+		// 令牌吊销端点
+		oauth.POST("/revoke", h.HandleRevoke)
+		// 设备授权端点
+		oauth.POST("/device_authorization", h.HandleDeviceAuthorization)
+		// 设备授权用户确认端点
+		oauth.GET("/device", authMiddleware.HandleAuth(), h.HandleGetDeviceCode)
+		oauth.POST("/device", authMiddleware.HandleAuth(), h.HandleVerifyDeviceCode)
+		// 登出端点(OIDC RP-Initiated Logout)
+		oauth.GET("/logout", authMiddleware.HandleOptionalAuth(), h.HandleEndSession)
+		oauth.POST("/logout", authMiddleware.HandleOptionalAuth(), h.HandleEndSession)
 	}
 }
 
@@ -43,37 +73,156 @@ func (h *AuthorizationHandler) HandleAuthorize(c *gin.Context) {
 		return
 	}
 
-	// 从认证中间件获取用户信息
-	log.Printf("Authorization header: %s", c.GetHeader("Authorization"))
+	// 从认证中间件获取用户信息，未登录时为nil
 	claims := middleware.GetUserFromContext(c)
 	log.Printf("User claims from context: %+v", claims)
+
+	// 处理授权请求
+	result, err := h.authService.Authorize(c.Request.Context(), newAuthContext(claims), &req)
+	auditRedirectURIRejection(c, err, req.ClientID, req.RedirectURI)
+	if err == service.ErrConsentRequired {
+		// 返回授权同意信息，由前端展示同意页面后调用同意端点
+		prompt, err := h.authService.GetConsentPrompt(c.Request.Context(), newAuthContext(claims), &req)
+		if err != nil {
+			h.handleAuthorizeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, prompt)
+		return
+	}
+	if err != nil {
+		h.handleAuthorizeError(c, err)
+		return
+	}
+
+	// 返回重定向URL而不是直接重定向，form_post模式下由前端提交form_params
+	c.JSON(http.StatusOK, result)
+}
+
+// handleAuthorizeError 处理授权错误响应
+func (h *AuthorizationHandler) handleAuthorizeError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidClient:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_client"})
+	case service.ErrInvalidRedirectURI:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_redirect_uri"})
+	case service.ErrInvalidScope:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_scope"})
+	case service.ErrUnsupportedGrantType:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+	case service.ErrInvalidRequest:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+	case service.ErrLoginRequired:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": model.ErrorLoginRequired})
+	case service.ErrInvalidRequestURI:
+		c.JSON(http.StatusBadRequest, gin.H{"error": model.ErrorInvalidRequestURI})
+	case service.ErrInvalidRequestObject:
+		c.JSON(http.StatusBadRequest, gin.H{"error": model.ErrorInvalidRequestObject})
+	case service.ErrInvalidAuthorizationDetails:
+		c.JSON(http.StatusBadRequest, gin.H{"error": model.ErrorInvalidAuthorizationDetails})
+	case service.ErrInvalidTarget:
+		c.JSON(http.StatusBadRequest, gin.H{"error": model.ErrorInvalidTarget})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+	}
+}
+
+// newAuthContext 根据访问令牌构建用户认证上下文，未登录时返回nil
+func newAuthContext(claims *model.TokenClaims) *model.AuthContext {
+	if claims == nil {
+		return nil
+	}
+	// 旧令牌没有auth_time时以签发时间代替
+	authTime := claims.AuthTime
+	if authTime.IsZero() {
+		authTime = claims.IssuedAt
+	}
+	// 旧令牌没有sid时以登录令牌族作为会话
+	sessionID := claims.SessionID
+	if sessionID == "" {
+		sessionID = claims.FamilyID
+	}
+	return &model.AuthContext{
+		UserID:    claims.UserID,
+		AppID:     claims.AppID,
+		SessionID: sessionID,
+		AuthTime:  authTime,
+		ACR:       claims.ACR,
+		AMR:       claims.AMR,
+	}
+}
+
+// auditRedirectURIRejection 将被拒绝的重定向URI记录到本次请求的审计日志
+func auditRedirectURIRejection(c *gin.Context, err error, clientID, redirectURI string) {
+	if err != service.ErrInvalidRedirectURI {
+		return
+	}
+	middleware.SetAuditEvent(c, audit.EventRedirectURIRejected, map[string]interface{}{
+		"client_id":    clientID,
+		"redirect_uri": redirectURI,
+	})
+}
+
+// HandleConsent 处理用户的授权同意决定
+func (h *AuthorizationHandler) HandleConsent(c *gin.Context) {
+	var req model.ConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims := middleware.GetUserFromContext(c)
 	if claims == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	// 处理授权请求
-	redirectURL, err := h.authService.Authorize(c.Request.Context(), claims.UserID, &req)
+	result, err := h.authService.Consent(c.Request.Context(), newAuthContext(claims), &req)
+	auditRedirectURIRejection(c, err, req.ClientID, req.RedirectURI)
+	if err != nil {
+		h.handleAuthorizeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListConsents 获取当前用户已授权的客户端列表
+func (h *AuthorizationHandler) ListConsents(c *gin.Context) {
+	claims := middleware.GetUserFromContext(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	consents, err := h.authService.ListConsents(c.Request.Context(), claims.UserID)
 	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, consents)
+}
+
+// RevokeConsent 撤销当前用户对客户端的授权
+func (h *AuthorizationHandler) RevokeConsent(c *gin.Context) {
+	claims := middleware.GetUserFromContext(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.authService.RevokeConsent(c.Request.Context(), claims.UserID, c.Param("client_id")); err != nil {
 		switch err {
-		case service.ErrInvalidClient:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_client"})
-		case service.ErrInvalidRedirectURI:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_redirect_uri"})
-		case service.ErrInvalidScope:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_scope"})
-		case service.ErrUnsupportedGrantType:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+		case service.ErrConsentNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
 		return
 	}
 
-	// 返回重定向URL而不是直接重定向
-	c.JSON(http.StatusOK, gin.H{
-		"redirect_url": redirectURL,
-	})
+	c.Status(http.StatusNoContent)
 }
 
 // validateTokenRequest 验证令牌请求参数
@@ -89,10 +238,29 @@ func (h *AuthorizationHandler) validateTokenRequest(c *gin.Context) (*model.Toke
 	req.RedirectURI = c.Request.PostForm.Get("redirect_uri")
 	req.ClientID = c.Request.PostForm.Get("client_id")
 	req.ClientSecret = c.Request.PostForm.Get("client_secret")
+	req.ClientAssertionType = c.Request.PostForm.Get("client_assertion_type")
+	req.ClientAssertion = c.Request.PostForm.Get("client_assertion")
 	req.RefreshToken = c.Request.PostForm.Get("refresh_token")
+	req.CodeVerifier = c.Request.PostForm.Get("code_verifier")
+	req.Scope = c.Request.PostForm.Get("scope")
+	req.DeviceCode = c.Request.PostForm.Get("device_code")
+	req.SubjectToken = c.Request.PostForm.Get("subject_token")
+	req.SubjectTokenType = c.Request.PostForm.Get("subject_token_type")
+	req.ActorToken = c.Request.PostForm.Get("actor_token")
+	req.ActorTokenType = c.Request.PostForm.Get("actor_token_type")
+	req.RequestedTokenType = c.Request.PostForm.Get("requested_token_type")
+	req.Audience = c.Request.PostForm["audience"]
+	req.AuthorizationDetails = c.Request.PostForm.Get("authorization_details")
+	req.Resource = c.Request.PostForm["resource"]
+	req.DPoPProof = middleware.DPoPProof(c)
+	req.DPoPURL = middleware.RequestURL(c)
+
+	if err := bindClientAuthentication(c, &req.ClientAuthentication); err != nil {
+		return nil, err
+	}
 
-	// 验证必填字段
-	if req.GrantType == "" || req.ClientID == "" || req.ClientSecret == "" {
+	// 验证必填字段(公开客户端使用PKCE时无需client_secret，使用断言认证时client_id可省略)
+	if req.GrantType == "" || (req.ClientID == "" && req.ClientAssertion == "") {
 		return nil, fmt.Errorf("missing required parameters")
 	}
 
@@ -105,6 +273,14 @@ func (h *AuthorizationHandler) validateTokenRequest(c *gin.Context) (*model.Toke
 		if req.RefreshToken == "" {
 			return nil, fmt.Errorf("refresh_token is required for refresh_token grant type")
 		}
+	} else if req.GrantType == model.GrantTypeDeviceCode {
+		if req.DeviceCode == "" {
+			return nil, fmt.Errorf("device_code is required for device_code grant type")
+		}
+	} else if req.GrantType == model.GrantTypeTokenExchange {
+		if req.SubjectToken == "" || req.SubjectTokenType == "" {
+			return nil, fmt.Errorf("subject_token and subject_token_type are required for token exchange grant type")
+		}
 	}
 
 	return &req, nil
@@ -134,6 +310,78 @@ func (h *AuthorizationHandler) handleTokenError(c *gin.Context, err error) {
 			Error:            model.ErrorUnsupportedGrantType,
 			ErrorDescription: "unsupported grant type",
 		}
+	case service.ErrUnauthorizedClient:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorUnauthorizedClient,
+			ErrorDescription: "client is not authorized to use this grant type",
+		}
+	case service.ErrInvalidScope:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorInvalidScope,
+			ErrorDescription: "requested scope is invalid",
+		}
+	case service.ErrAuthorizationPending:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorAuthorizationPending,
+			ErrorDescription: "the user has not yet completed authorization",
+		}
+	case service.ErrSlowDown:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorSlowDown,
+			ErrorDescription: "polling too frequently, increase the interval by 5 seconds",
+		}
+	case service.ErrExpiredToken:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorExpiredToken,
+			ErrorDescription: "the device code has expired",
+		}
+	case service.ErrAccessDenied:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorAccessDenied,
+			ErrorDescription: "the user denied the authorization request",
+		}
+	case service.ErrInvalidRequest:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: "the request is missing or has invalid parameters",
+		}
+	case service.ErrInvalidRedirectURI:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: "redirect_uri is not registered for the client",
+		}
+	case service.ErrInvalidTarget:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorInvalidTarget,
+			ErrorDescription: "the requested resource or audience is not registered or was not authorized for the client",
+		}
+	case service.ErrInvalidRequestObject:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorInvalidRequestObject,
+			ErrorDescription: "the request object is invalid or its signature could not be verified",
+		}
+	case service.ErrInvalidAuthorizationDetails:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorInvalidAuthorizationDetails,
+			ErrorDescription: "authorization_details is malformed, uses an unregistered type or exceeds what was approved",
+		}
+	case service.ErrInvalidDPoPProof:
+		statusCode = http.StatusBadRequest
+		tokenError = model.TokenError{
+			Error:            model.ErrorInvalidDPoPProof,
+			ErrorDescription: "the DPoP proof is invalid, has already been used or does not match the token binding",
+		}
 	default:
 		statusCode = http.StatusInternalServerError
 		tokenError = model.TokenError{
@@ -145,6 +393,36 @@ func (h *AuthorizationHandler) handleTokenError(c *gin.Context, err error) {
 	c.JSON(statusCode, tokenError)
 }
 
+// bindClientAuthentication 合并HTTP Basic认证中的客户端凭证(RFC 6749 2.3.1)
+// 客户端只能使用一种认证方式，Basic认证不能与表单中的密钥或断言同时出现
+func bindClientAuthentication(c *gin.Context, creds *model.ClientAuthentication) error {
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if !ok {
+		return nil
+	}
+	if creds.ClientSecret != "" || creds.ClientAssertion != "" {
+		return fmt.Errorf("multiple client authentication methods are not allowed")
+	}
+
+	// Basic认证中的凭证先经过application/x-www-form-urlencoded编码
+	if decoded, err := url.QueryUnescape(clientID); err == nil {
+		clientID = decoded
+	}
+	if decoded, err := url.QueryUnescape(clientSecret); err == nil {
+		clientSecret = decoded
+	}
+	if creds.ClientID != "" && creds.ClientID != clientID {
+		return fmt.Errorf("client_id does not match the authenticated client")
+	}
+
+	creds.ClientID = clientID
+	creds.ClientSecret = clientSecret
+	if clientSecret != "" {
+		creds.AuthMethod = model.TokenEndpointAuthClientSecretBasic
+	}
+	return nil
+}
+
 // HandleToken 处理令牌请求
 func (h *AuthorizationHandler) HandleToken(c *gin.Context) {
 	// 验证请求参数
@@ -166,3 +444,234 @@ func (h *AuthorizationHandler) HandleToken(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+// HandleIntrospect 处理令牌内省请求(RFC 7662)
+func (h *AuthorizationHandler) HandleIntrospect(c *gin.Context) {
+	var req model.IntrospectionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	if err := bindClientAuthentication(c, &req.ClientAuthentication); err != nil {
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.authService.IntrospectToken(c.Request.Context(), &req)
+	if err != nil {
+		h.handleTokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleRevoke 处理令牌吊销请求(RFC 7009)
+func (h *AuthorizationHandler) HandleRevoke(c *gin.Context) {
+	var req model.RevocationRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	if err := bindClientAuthentication(c, &req.ClientAuthentication); err != nil {
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+	if req.ClientID == "" && req.ClientAssertion == "" {
+		h.handleTokenError(c, service.ErrInvalidClient)
+		return
+	}
+
+	if err := h.authService.RevokeToken(c.Request.Context(), &req); err != nil {
+		h.handleTokenError(c, err)
+		return
+	}
+
+	// 无论令牌是否有效都返回200，避免泄露令牌状态
+	c.Status(http.StatusOK)
+}
+
+// HandlePushedAuthorization 处理推送授权请求(RFC 9126)
+func (h *AuthorizationHandler) HandlePushedAuthorization(c *gin.Context) {
+	var req model.PushedAuthorizationRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	// client_id同时是授权参数与客户端认证信息
+	req.Client = model.ClientAuthentication{
+		ClientID:            req.ClientID,
+		ClientSecret:        c.PostForm("client_secret"),
+		ClientAssertionType: c.PostForm("client_assertion_type"),
+		ClientAssertion:     c.PostForm("client_assertion"),
+	}
+	if err := bindClientAuthentication(c, &req.Client); err != nil {
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.authService.PushAuthorizationRequest(c.Request.Context(), &req)
+	auditRedirectURIRejection(c, err, req.ClientID, req.RedirectURI)
+	if err != nil {
+		h.handleTokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// HandleDeviceAuthorization 处理设备授权请求(RFC 8628)
+func (h *AuthorizationHandler) HandleDeviceAuthorization(c *gin.Context) {
+	var req model.DeviceAuthorizationRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	if err := bindClientAuthentication(c, &req.ClientAuthentication); err != nil {
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+	if req.ClientID == "" && req.ClientAssertion == "" {
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: "client_id is required",
+		})
+		return
+	}
+
+	resp, err := h.authService.DeviceAuthorization(c.Request.Context(), &req)
+	if err != nil {
+		h.handleTokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleGetDeviceCode 获取待用户确认的设备授权信息
+func (h *AuthorizationHandler) HandleGetDeviceCode(c *gin.Context) {
+	claims := middleware.GetUserFromContext(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userCode := c.Query("user_code")
+	if userCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_code is required"})
+		return
+	}
+
+	info, err := h.deviceService.GetDeviceCodeInfo(c.Request.Context(), claims.AppID, userCode)
+	if err != nil {
+		h.handleDeviceVerificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// HandleVerifyDeviceCode 用户批准或拒绝设备授权
+func (h *AuthorizationHandler) HandleVerifyDeviceCode(c *gin.Context) {
+	claims := middleware.GetUserFromContext(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req model.DeviceVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.deviceService.VerifyUserCode(c.Request.Context(), newAuthContext(claims), &req); err != nil {
+		h.handleDeviceVerificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "device authorization completed"})
+}
+
+// handleDeviceVerificationError 处理设备授权确认错误响应
+func (h *AuthorizationHandler) handleDeviceVerificationError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidUserCode:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_user_code"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+	}
+}
+
+// HandleEndSession 处理客户端发起的登出请求
+func (h *AuthorizationHandler) HandleEndSession(c *gin.Context) {
+	var req model.EndSessionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims := middleware.GetUserFromContext(c)
+	resp, err := h.logoutService.RPInitiatedLogout(c.Request.Context(), newAuthContext(claims), &req)
+	auditRedirectURIRejection(c, err, req.ClientID, req.PostLogoutRedirectURI)
+	if err == service.ErrLogoutConfirmationRequired {
+		// 由托管的登出确认页面请用户确认后再结束会话
+		c.Redirect(http.StatusFound, hostedPath+"/logout?"+endSessionQuery(&req).Encode())
+		return
+	}
+	if err != nil {
+		h.handleAuthorizeError(c, err)
+		return
+	}
+
+	// 清除登录会话Cookie
+	c.SetCookie("access_token", "", -1, "/", "", false, true)
+	c.SetCookie("refresh_token", "", -1, "/", "", false, true)
+
+	// 返回重定向URL与前端通道登出URL，由前端加载登出URL后再重定向
+	c.JSON(http.StatusOK, resp)
+}
+
+// endSessionQuery 将登出请求编码为查询参数
+func endSessionQuery(req *model.EndSessionRequest) url.Values {
+	query := url.Values{}
+	for name, value := range map[string]string{
+		"id_token_hint":            req.IDTokenHint,
+		"client_id":                req.ClientID,
+		"post_logout_redirect_uri": req.PostLogoutRedirectURI,
+		"state":                    req.State,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	return query
+}
@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"lauth/internal/model"
+	"lauth/internal/service"
+	"lauth/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientRegistrationHandler 动态客户端注册处理器(RFC 7591/7592)
+type ClientRegistrationHandler struct {
+	service service.OAuthClientService
+}
+
+// NewClientRegistrationHandler 创建动态客户端注册处理器实例
+func NewClientRegistrationHandler(clientService service.OAuthClientService) *ClientRegistrationHandler {
+	return &ClientRegistrationHandler{
+		service: clientService,
+	}
+}
+
+// Register 注册路由
+// 注册端点使用初始访问令牌或注册访问令牌认证，不经过用户认证中间件
+func (h *ClientRegistrationHandler) Register(group *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	oauth := group.Group("/oauth")
+	{
+		oauth.POST("/register", h.RegisterClient)
+		oauth.GET("/register/:client_id", h.GetRegistration)
+		oauth.PUT("/register/:client_id", h.UpdateRegistration)
+		oauth.DELETE("/register/:client_id", h.DeleteRegistration)
+	}
+}
+
+// RegisterClient 动态注册客户端
+func (h *ClientRegistrationHandler) RegisterClient(c *gin.Context) {
+	token := extractBearerToken(c)
+	if token == "" {
+		h.handleRegistrationError(c, service.ErrInvalidRegistrationToken)
+		return
+	}
+
+	var req model.ClientRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidClientMetadata,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.service.RegisterClient(c.Request.Context(), token, &req)
+	if err != nil {
+		h.handleRegistrationError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetRegistration 读取客户端注册信息
+func (h *ClientRegistrationHandler) GetRegistration(c *gin.Context) {
+	resp, err := h.service.GetClientRegistration(c.Request.Context(), c.Param("client_id"), extractBearerToken(c))
+	if err != nil {
+		h.handleRegistrationError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateRegistration 更新客户端注册信息
+func (h *ClientRegistrationHandler) UpdateRegistration(c *gin.Context) {
+	var req model.ClientRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidClientMetadata,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.service.UpdateClientRegistration(c.Request.Context(), c.Param("client_id"), extractBearerToken(c), &req)
+	if err != nil {
+		h.handleRegistrationError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteRegistration 删除客户端注册
+func (h *ClientRegistrationHandler) DeleteRegistration(c *gin.Context) {
+	if err := h.service.DeleteClientRegistration(c.Request.Context(), c.Param("client_id"), extractBearerToken(c)); err != nil {
+		h.handleRegistrationError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleRegistrationError 处理注册错误响应
+func (h *ClientRegistrationHandler) handleRegistrationError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidRegistrationToken:
+		c.JSON(http.StatusUnauthorized, model.TokenError{
+			Error:            model.ErrorInvalidToken,
+			ErrorDescription: "invalid or expired access token",
+		})
+	case service.ErrInvalidRedirectURI:
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidRedirectURI,
+			ErrorDescription: "one or more redirect_uri values are invalid",
+		})
+	case service.ErrInvalidClientMetadata:
+		c.JSON(http.StatusBadRequest, model.TokenError{
+			Error:            model.ErrorInvalidClientMetadata,
+			ErrorDescription: "the value of one of the client metadata fields is invalid",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, model.TokenError{
+			Error:            "server_error",
+			ErrorDescription: "internal server error",
+		})
+	}
+}
+
+// extractBearerToken 从Authorization头获取Bearer令牌
+func extractBearerToken(c *gin.Context) string {
+	auth := c.GetHeader("Authorization")
+	if !strings.HasPrefix(auth, middleware.BearerSchema) {
+		return ""
+	}
+	return strings.TrimSpace(auth[len(middleware.BearerSchema):])
+}
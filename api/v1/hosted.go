@@ -0,0 +1,544 @@
+package v1
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"lauth/internal/model"
+	"lauth/internal/service"
+	"lauth/pkg/i18n"
+	"lauth/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// interactionCookie 保存托管页面交互ID的Cookie
+	interactionCookie = "lauth_interaction"
+	// logoutCSRFCookie 保存登出确认页面CSRF令牌的Cookie，与表单中的令牌比对
+	logoutCSRFCookie = "lauth_logout"
+	// logoutConfirmationExpiry 登出确认页面的有效期
+	logoutConfirmationExpiry = 10 * time.Minute
+	// hostedPath 托管页面的路径前缀
+	hostedPath = "/oauth"
+)
+
+// HostedHandler 托管页面处理器
+// 浏览器直接访问授权端点时，由Lauth渲染登录、插件验证与授权同意页面，完成后重定向回客户端
+type HostedHandler struct {
+	hostedService service.HostedLoginService
+	authService   service.AuthorizationService
+	tokenService  service.TokenService
+	logoutService service.LogoutService
+
+	// issuer 为https时会话与交互Cookie始终设置Secure
+	issuer string
+	// refreshExpiry 刷新令牌有效期，用作refresh_token Cookie的有效期
+	refreshExpiry time.Duration
+}
+
+// NewHostedHandler 创建托管页面处理器实例
+func NewHostedHandler(
+	hostedService service.HostedLoginService,
+	authService service.AuthorizationService,
+	tokenService service.TokenService,
+	logoutService service.LogoutService,
+	issuer string,
+	refreshExpiry time.Duration,
+) *HostedHandler {
+	return &HostedHandler{
+		hostedService: hostedService,
+		authService:   authService,
+		tokenService:  tokenService,
+		logoutService: logoutService,
+		issuer:        issuer,
+		refreshExpiry: refreshExpiry,
+	}
+}
+
+// Register 注册路由
+func (h *HostedHandler) Register(engine *gin.Engine, authMiddleware *middleware.AuthMiddleware) {
+	hosted := engine.Group(hostedPath)
+	{
+		// 浏览器授权端点，已登录的用户通过访问令牌Cookie识别
+		hosted.GET("/authorize", authMiddleware.HandleOptionalAuth(), h.HandleAuthorize)
+		// 登录页面
+		hosted.GET("/login", h.ShowLogin)
+		hosted.POST("/login", h.Login)
+		// 插件验证页面
+		hosted.GET("/verify", h.ShowVerify)
+		hosted.POST("/verify", h.Verify)
+		// 授权同意页面
+		hosted.GET("/consent", authMiddleware.HandleOptionalAuth(), h.ShowConsent)
+		hosted.POST("/consent", authMiddleware.HandleOptionalAuth(), h.Consent)
+		// 浏览器登出端点(OIDC RP-Initiated Logout)，不能证明来自当前会话的请求需要用户确认
+		hosted.GET("/logout", authMiddleware.HandleOptionalAuth(), h.HandleLogout)
+		hosted.POST("/logout", authMiddleware.HandleOptionalAuth(), h.HandleLogout)
+	}
+}
+
+// HandleAuthorize 处理浏览器的授权请求
+// 需要登录或确认授权时保存请求并跳转到对应页面，否则直接重定向回客户端
+func (h *HostedHandler) HandleAuthorize(c *gin.Context) {
+	var req model.AuthorizationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Printf("Invalid hosted authorization request: %v", err)
+		h.renderError(c, nil, service.ErrInvalidRequest)
+		return
+	}
+
+	claims := middleware.GetUserFromContext(c)
+	result, err := h.authService.Authorize(c.Request.Context(), newAuthContext(claims), &req)
+	auditRedirectURIRejection(c, err, req.ClientID, req.RedirectURI)
+	switch err {
+	case nil:
+		h.respond(c, result)
+	case service.ErrLoginRequired, service.ErrConsentRequired:
+		// 保存的是解析request_uri与请求对象后的授权请求，后续页面无需再次解析
+		interaction, startErr := h.hostedService.StartInteraction(c.Request.Context(), &req)
+		if startErr != nil {
+			h.renderError(c, nil, startErr)
+			return
+		}
+		h.setInteractionCookie(c, interaction)
+		if err == service.ErrLoginRequired {
+			c.Redirect(http.StatusFound, hostedPath+"/login")
+			return
+		}
+		c.Redirect(http.StatusFound, hostedPath+"/consent")
+	default:
+		h.renderError(c, nil, err)
+	}
+}
+
+// ShowLogin 显示登录页面
+func (h *HostedHandler) ShowLogin(c *gin.Context) {
+	interaction, ok := h.loadInteraction(c)
+	if !ok {
+		return
+	}
+	h.renderLogin(c, http.StatusOK, interaction, interaction.Request.LoginHint, "")
+}
+
+// Login 处理登录页面提交的用户名密码
+func (h *HostedHandler) Login(c *gin.Context) {
+	interaction, ok := h.loadInteraction(c)
+	if !ok {
+		return
+	}
+	var form model.HostedLoginRequest
+	if err := c.ShouldBind(&form); err != nil {
+		h.renderLogin(c, http.StatusBadRequest, interaction, form.Username, "login.invalid")
+		return
+	}
+	if !h.checkCSRF(c, interaction, form.CSRFToken) {
+		return
+	}
+
+	req := newHostedLoginRequest(c)
+	req.Username = form.Username
+	req.Password = form.Password
+
+	resp, err := h.hostedService.Login(c.Request.Context(), interaction, req)
+	switch err {
+	case nil:
+		h.completeLogin(c, interaction, resp)
+	case service.ErrPluginRequired:
+		c.Redirect(http.StatusSeeOther, hostedPath+"/verify")
+	case service.ErrInvalidCredentials:
+		h.renderLogin(c, http.StatusUnauthorized, interaction, form.Username, "login.invalid")
+	case service.ErrUserDisabled:
+		h.renderLogin(c, http.StatusForbidden, interaction, form.Username, "login.disabled")
+	case service.ErrUnmetAuthenticationRequirements:
+		h.reject(c, interaction, model.ErrorUnmetAuthenticationRequirements)
+	default:
+		h.renderError(c, interaction, err)
+	}
+}
+
+// ShowVerify 显示插件验证页面
+func (h *HostedHandler) ShowVerify(c *gin.Context) {
+	interaction, ok := h.loadInteraction(c)
+	if !ok {
+		return
+	}
+	if interaction.VerificationSessionID == "" {
+		c.Redirect(http.StatusFound, hostedPath+"/login")
+		return
+	}
+	h.renderVerify(c, http.StatusOK, interaction, "", "")
+}
+
+// Verify 处理插件验证页面提交的发送或验证操作
+func (h *HostedHandler) Verify(c *gin.Context) {
+	interaction, ok := h.loadInteraction(c)
+	if !ok {
+		return
+	}
+	var form model.HostedVerifyRequest
+	if err := c.ShouldBind(&form); err != nil {
+		h.renderError(c, interaction, service.ErrInvalidRequest)
+		return
+	}
+	if !h.checkCSRF(c, interaction, form.CSRFToken) {
+		return
+	}
+
+	resp, err := h.hostedService.ExecutePlugin(c.Request.Context(), interaction, &form, newHostedLoginRequest(c))
+	switch {
+	case err == nil && resp == nil:
+		// 验证码已发送，等待用户输入
+		h.renderVerify(c, http.StatusOK, interaction, "verify.sent", "")
+	case err == nil:
+		h.completeLogin(c, interaction, resp)
+	case err == service.ErrPluginRequired:
+		c.Redirect(http.StatusSeeOther, hostedPath+"/verify")
+	case err == service.ErrUnmetAuthenticationRequirements:
+		h.reject(c, interaction, model.ErrorUnmetAuthenticationRequirements)
+	case err == service.ErrVerificationFailed && form.Operation == "verify":
+		h.renderVerify(c, http.StatusBadRequest, interaction, "", "verify.invalid")
+	case err == service.ErrVerificationFailed:
+		h.renderVerify(c, http.StatusBadRequest, interaction, "", "error.server_error")
+	default:
+		h.renderError(c, interaction, err)
+	}
+}
+
+// ShowConsent 显示授权同意页面
+func (h *HostedHandler) ShowConsent(c *gin.Context) {
+	interaction, ok := h.loadInteraction(c)
+	if !ok {
+		return
+	}
+	claims := middleware.GetUserFromContext(c)
+	if claims == nil {
+		c.Redirect(http.StatusFound, hostedPath+"/login")
+		return
+	}
+
+	prompt, err := h.authService.GetConsentPrompt(c.Request.Context(), newAuthContext(claims), &interaction.Request)
+	if err == service.ErrLoginRequired {
+		// 登录会话已不满足授权请求的认证要求，重新登录后继续同一交互
+		c.Redirect(http.StatusFound, hostedPath+"/login")
+		return
+	}
+	if err != nil {
+		h.renderError(c, interaction, err)
+		return
+	}
+
+	data, messages := h.pageData(c, interaction)
+	data["Title"] = messages["consent.title"]
+	data["Subtitle"] = fmt.Sprintf(messages["consent.subtitle"], displayClientName(interaction, prompt))
+	data["Prompt"] = prompt
+	c.HTML(http.StatusOK, "consent.html", data)
+}
+
+// Consent 处理授权同意页面提交的决定
+func (h *HostedHandler) Consent(c *gin.Context) {
+	interaction, ok := h.loadInteraction(c)
+	if !ok {
+		return
+	}
+	var form model.HostedConsentRequest
+	if err := c.ShouldBind(&form); err != nil {
+		h.renderError(c, interaction, service.ErrInvalidRequest)
+		return
+	}
+	if !h.checkCSRF(c, interaction, form.CSRFToken) {
+		return
+	}
+	claims := middleware.GetUserFromContext(c)
+	if claims == nil {
+		c.Redirect(http.StatusSeeOther, hostedPath+"/login")
+		return
+	}
+
+	result, err := h.authService.Consent(c.Request.Context(), newAuthContext(claims), &model.ConsentRequest{
+		AuthorizationRequest: interaction.Request,
+		Approve:              form.Approve,
+	})
+	if err == service.ErrLoginRequired {
+		c.Redirect(http.StatusSeeOther, hostedPath+"/login")
+		return
+	}
+	h.finishInteraction(c, interaction)
+	if err != nil {
+		h.renderError(c, interaction, err)
+		return
+	}
+	h.respond(c, result)
+}
+
+// HandleLogout 处理浏览器的登出请求
+// 确认页面以POST提交，表单中的CSRF令牌必须与Cookie一致；登出后加载前端通道登出URL并重定向回客户端
+func (h *HostedHandler) HandleLogout(c *gin.Context) {
+	var req model.EndSessionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.renderError(c, nil, service.ErrInvalidRequest)
+		return
+	}
+	if c.Request.Method == http.MethodPost {
+		csrfToken, err := c.Cookie(logoutCSRFCookie)
+		if err != nil || subtle.ConstantTimeCompare([]byte(csrfToken), []byte(c.PostForm("csrf_token"))) != 1 {
+			log.Printf("CSRF token mismatch for logout confirmation")
+			h.renderError(c, nil, service.ErrInteractionNotFound)
+			return
+		}
+		req.Confirmed = true
+		h.setCookie(c, logoutCSRFCookie, "", -1, hostedPath, http.SameSiteStrictMode)
+	}
+
+	claims := middleware.GetUserFromContext(c)
+	resp, err := h.logoutService.RPInitiatedLogout(c.Request.Context(), newAuthContext(claims), &req)
+	auditRedirectURIRejection(c, err, req.ClientID, req.PostLogoutRedirectURI)
+	switch err {
+	case nil:
+	case service.ErrLogoutConfirmationRequired:
+		h.renderLogoutConfirmation(c, &req)
+		return
+	default:
+		h.renderError(c, nil, err)
+		return
+	}
+
+	// 清除登录会话Cookie
+	h.setCookie(c, "access_token", "", -1, "/", http.SameSiteLaxMode)
+	h.setCookie(c, "refresh_token", "", -1, "/", http.SameSiteLaxMode)
+
+	data, messages := h.pageData(c, nil)
+	data["Title"] = messages["logout.done"]
+	data["Response"] = resp
+	c.HTML(http.StatusOK, "logout.html", data)
+}
+
+// renderLogoutConfirmation 渲染登出确认页面，CSRF令牌同时写入Cookie
+func (h *HostedHandler) renderLogoutConfirmation(c *gin.Context, req *model.EndSessionRequest) {
+	csrfToken, err := generateCSRFToken()
+	if err != nil {
+		h.renderError(c, nil, err)
+		return
+	}
+	h.setCookie(c, logoutCSRFCookie, csrfToken, int(logoutConfirmationExpiry.Seconds()), hostedPath, http.SameSiteStrictMode)
+
+	data, messages := h.pageData(c, nil)
+	data["Title"] = messages["logout.title"]
+	data["Subtitle"] = messages["logout.subtitle"]
+	data["CSRFToken"] = csrfToken
+	data["Request"] = req
+	c.HTML(http.StatusOK, "logout.html", data)
+}
+
+// completeLogin 登录完成后设置会话Cookie，并以新的登录会话继续处理授权请求
+func (h *HostedHandler) completeLogin(c *gin.Context, interaction *model.Interaction, resp *model.ExtendedLoginResponse) {
+	h.setCookie(c, "access_token", resp.AccessToken, int(resp.ExpiresIn), "/", http.SameSiteLaxMode)
+	h.setCookie(c, "refresh_token", resp.RefreshToken, int(h.refreshExpiry.Seconds()), "/", http.SameSiteLaxMode)
+
+	claims, err := h.tokenService.ValidateToken(c.Request.Context(), resp.AccessToken, model.AccessToken)
+	if err != nil {
+		h.finishInteraction(c, interaction)
+		h.renderError(c, interaction, err)
+		return
+	}
+
+	req := interaction.Request
+	result, err := h.authService.Authorize(c.Request.Context(), newAuthContext(claims), &req)
+	switch err {
+	case nil:
+		h.finishInteraction(c, interaction)
+		h.respond(c, result)
+	case service.ErrConsentRequired:
+		c.Redirect(http.StatusSeeOther, hostedPath+"/consent")
+	default:
+		// 刚登录的会话仍不满足授权请求(如login_hint指定了其他用户)时不再重复要求登录
+		h.finishInteraction(c, interaction)
+		h.renderError(c, interaction, err)
+	}
+}
+
+// reject 交互无法完成时结束交互，并将错误码返回给客户端
+func (h *HostedHandler) reject(c *gin.Context, interaction *model.Interaction, errorCode string) {
+	h.finishInteraction(c, interaction)
+	result, err := h.authService.Reject(c.Request.Context(), &interaction.Request, errorCode)
+	if err != nil {
+		h.renderError(c, interaction, err)
+		return
+	}
+	h.respond(c, result)
+}
+
+// respond 将授权结果返回给客户端，form_post模式下渲染自动提交的表单
+func (h *HostedHandler) respond(c *gin.Context, result *model.AuthorizationResult) {
+	if result.ResponseMode == model.ResponseModeFormPost {
+		data, messages := h.pageData(c, nil)
+		data["Title"] = messages["redirect.title"]
+		data["Result"] = result
+		c.HTML(http.StatusOK, "form_post.html", data)
+		return
+	}
+	c.Redirect(http.StatusFound, result.RedirectURL)
+}
+
+// loadInteraction 根据Cookie获取交互，交互不存在或已过期时显示错误页面
+func (h *HostedHandler) loadInteraction(c *gin.Context) (*model.Interaction, bool) {
+	id, _ := c.Cookie(interactionCookie)
+	interaction, err := h.hostedService.GetInteraction(c.Request.Context(), id)
+	if err != nil {
+		h.renderError(c, nil, err)
+		return nil, false
+	}
+	return interaction, true
+}
+
+// checkCSRF 校验表单中的CSRF令牌
+func (h *HostedHandler) checkCSRF(c *gin.Context, interaction *model.Interaction, token string) bool {
+	if subtle.ConstantTimeCompare([]byte(token), []byte(interaction.CSRFToken)) != 1 {
+		log.Printf("CSRF token mismatch for interaction %s", interaction.ID)
+		h.renderError(c, interaction, service.ErrInteractionNotFound)
+		return false
+	}
+	return true
+}
+
+// setInteractionCookie 设置交互Cookie，有效期与交互一致
+func (h *HostedHandler) setInteractionCookie(c *gin.Context, interaction *model.Interaction) {
+	h.setCookie(c, interactionCookie, interaction.ID, int(time.Until(interaction.ExpiresAt).Seconds()), hostedPath, http.SameSiteLaxMode)
+}
+
+// finishInteraction 删除交互与交互Cookie
+func (h *HostedHandler) finishInteraction(c *gin.Context, interaction *model.Interaction) {
+	if err := h.hostedService.FinishInteraction(c.Request.Context(), interaction.ID); err != nil {
+		log.Printf("Failed to delete interaction %s: %v", interaction.ID, err)
+	}
+	h.setCookie(c, interactionCookie, "", -1, hostedPath, http.SameSiteLaxMode)
+}
+
+// setCookie 设置HttpOnly Cookie，SameSite显式指定；issuer或当前请求为https时设置Secure
+func (h *HostedHandler) setCookie(c *gin.Context, name, value string, maxAge int, path string, sameSite http.SameSite) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    url.QueryEscape(value),
+		MaxAge:   maxAge,
+		Path:     path,
+		Secure:   strings.HasPrefix(h.issuer, "https://") || c.Request.TLS != nil,
+		HttpOnly: true,
+		SameSite: sameSite,
+	})
+}
+
+// renderLogin 渲染登录页面
+func (h *HostedHandler) renderLogin(c *gin.Context, status int, interaction *model.Interaction, username, errorKey string) {
+	data, messages := h.pageData(c, interaction)
+	data["Title"] = messages["login.title"]
+	data["Subtitle"] = fmt.Sprintf(messages["login.subtitle"], data["Brand"])
+	data["Username"] = username
+	data["Error"] = messages[errorKey]
+	c.HTML(status, "login.html", data)
+}
+
+// renderVerify 渲染插件验证页面
+func (h *HostedHandler) renderVerify(c *gin.Context, status int, interaction *model.Interaction, noticeKey, errorKey string) {
+	data, messages := h.pageData(c, interaction)
+	data["Title"] = messages["verify.title"]
+	data["Subtitle"] = messages["verify.subtitle"]
+	data["Plugins"] = h.hostedService.VerificationPlugins(interaction)
+	data["Notice"] = messages[noticeKey]
+	data["Error"] = messages[errorKey]
+	c.HTML(status, "verify.html", data)
+}
+
+// renderError 渲染错误页面
+func (h *HostedHandler) renderError(c *gin.Context, interaction *model.Interaction, err error) {
+	status, key := hostedErrorMessage(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("Hosted authorization failed: %v", err)
+	}
+	data, messages := h.pageData(c, interaction)
+	data["Title"] = messages["error.title"]
+	data["Error"] = messages[key]
+	c.HTML(status, "error.html", data)
+}
+
+// pageData 构建页面的公共数据：语言、文案、应用品牌与CSRF令牌，同时返回所选语言的文案
+// 语言按ui_locales、浏览器Accept-Language、应用默认语言的顺序选择
+func (h *HostedHandler) pageData(c *gin.Context, interaction *model.Interaction) (gin.H, map[string]string) {
+	uiLocales := c.Query("ui_locales")
+	var branding model.AppBranding
+	brand := "Lauth"
+	csrfToken := ""
+	if interaction != nil {
+		uiLocales = interaction.Request.UILocales
+		branding = interaction.Branding
+		brand = interaction.AppName
+		if branding.DisplayName != "" {
+			brand = branding.DisplayName
+		}
+		csrfToken = interaction.CSRFToken
+	}
+
+	locale := i18n.Match(uiLocales, c.GetHeader("Accept-Language"), branding.DefaultLocale)
+	messages := i18n.Messages(locale)
+	return gin.H{
+		"Lang":         locale,
+		"T":            messages,
+		"Brand":        brand,
+		"LogoURL":      branding.LogoURL,
+		"PrimaryColor": branding.PrimaryColor,
+		"CSRFToken":    csrfToken,
+	}, messages
+}
+
+// generateCSRFToken 生成随机的CSRF令牌
+func generateCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// newHostedLoginRequest 收集托管页面登录的验证上下文信息
+func newHostedLoginRequest(c *gin.Context) *model.LoginRequest {
+	return &model.LoginRequest{
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// displayClientName 授权同意页面展示的客户端名称
+func displayClientName(interaction *model.Interaction, prompt *model.ConsentPrompt) string {
+	if prompt.ClientName != "" {
+		return prompt.ClientName
+	}
+	if interaction.ClientName != "" {
+		return interaction.ClientName
+	}
+	return prompt.ClientID
+}
+
+// hostedErrorMessage 将授权错误映射为错误页面的状态码与文案
+func hostedErrorMessage(err error) (int, string) {
+	switch err {
+	case service.ErrInvalidClient:
+		return http.StatusBadRequest, "error.invalid_client"
+	case service.ErrInvalidScope:
+		return http.StatusBadRequest, "error.invalid_scope"
+	case service.ErrInvalidRequest, service.ErrInvalidRedirectURI, service.ErrUnsupportedGrantType,
+		service.ErrInvalidRequestURI, service.ErrInvalidRequestObject,
+		service.ErrInvalidAuthorizationDetails, service.ErrInvalidTarget:
+		return http.StatusBadRequest, "error.invalid_request"
+	case service.ErrLoginRequired:
+		return http.StatusForbidden, "error.login_required"
+	case service.ErrInteractionNotFound, service.ErrInvalidCredentials:
+		return http.StatusBadRequest, "error.session_expired"
+	default:
+		return http.StatusInternalServerError, "error.server_error"
+	}
+}
@@ -13,13 +13,21 @@ import (
 
 // OAuthClientHandler OAuth客户端处理器
 type OAuthClientHandler struct {
-	service service.OAuthClientService
+	service         service.OAuthClientService
+	detailService   service.AuthorizationDetailService
+	resourceService service.ProtectedResourceService
 }
 
 // NewOAuthClientHandler 创建OAuth客户端处理器实例
-func NewOAuthClientHandler(clientService service.OAuthClientService) *OAuthClientHandler {
+func NewOAuthClientHandler(
+	clientService service.OAuthClientService,
+	detailService service.AuthorizationDetailService,
+	resourceService service.ProtectedResourceService,
+) *OAuthClientHandler {
 	return &OAuthClientHandler{
-		service: clientService,
+		service:         clientService,
+		detailService:   detailService,
+		resourceService: resourceService,
 	}
 }
 
@@ -38,6 +46,21 @@ func (h *OAuthClientHandler) Register(group *gin.RouterGroup, authMiddleware *mi
 		apps.POST("/:id/oauth/clients/:client_id/secrets", authMiddleware.HandleAuth(), h.CreateClientSecret)
 		apps.DELETE("/:id/oauth/clients/:client_id/secrets/:secret_id", authMiddleware.HandleAuth(), h.DeleteClientSecret)
 		apps.GET("/:id/oauth/clients/:client_id/secrets", authMiddleware.HandleAuth(), h.ListClientSecrets)
+
+		// 动态客户端注册初始访问令牌管理
+		apps.POST("/:id/oauth/initial-access-tokens", authMiddleware.HandleAuth(), h.CreateInitialAccessToken)
+		apps.GET("/:id/oauth/initial-access-tokens", authMiddleware.HandleAuth(), h.ListInitialAccessTokens)
+		apps.DELETE("/:id/oauth/initial-access-tokens/:token_id", authMiddleware.HandleAuth(), h.DeleteInitialAccessToken)
+
+		// 授权详情类型管理(RFC 9396)
+		apps.POST("/:id/oauth/authorization-detail-types", authMiddleware.HandleAuth(), h.CreateAuthorizationDetailType)
+		apps.GET("/:id/oauth/authorization-detail-types", authMiddleware.HandleAuth(), h.ListAuthorizationDetailTypes)
+		apps.DELETE("/:id/oauth/authorization-detail-types/:type", authMiddleware.HandleAuth(), h.DeleteAuthorizationDetailType)
+
+		// 受保护资源管理(RFC 8707)
+		apps.POST("/:id/oauth/resources", authMiddleware.HandleAuth(), h.CreateProtectedResource)
+		apps.GET("/:id/oauth/resources", authMiddleware.HandleAuth(), h.ListProtectedResources)
+		apps.DELETE("/:id/oauth/resources/:resource_id", authMiddleware.HandleAuth(), h.DeleteProtectedResource)
 	}
 }
 
@@ -55,6 +78,8 @@ func (h *OAuthClientHandler) CreateClient(c *gin.Context) {
 		switch err {
 		case service.ErrClientExists:
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case service.ErrInvalidClientMetadata, service.ErrInvalidRedirectURI:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
@@ -96,6 +121,8 @@ func (h *OAuthClientHandler) UpdateClient(c *gin.Context) {
 		switch err {
 		case service.ErrClientNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrInvalidClientMetadata, service.ErrInvalidRedirectURI:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
@@ -193,3 +220,152 @@ func (h *OAuthClientHandler) ListClientSecrets(c *gin.Context) {
 
 	c.JSON(http.StatusOK, secrets)
 }
+
+// CreateInitialAccessToken 创建动态注册初始访问令牌
+func (h *OAuthClientHandler) CreateInitialAccessToken(c *gin.Context) {
+	appID := c.Param("id")
+	var req model.CreateInitialAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.CreateInitialAccessToken(c.Request.Context(), appID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListInitialAccessTokens 获取动态注册初始访问令牌列表
+func (h *OAuthClientHandler) ListInitialAccessTokens(c *gin.Context) {
+	appID := c.Param("id")
+
+	tokens, err := h.service.ListInitialAccessTokens(c.Request.Context(), appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// DeleteInitialAccessToken 删除动态注册初始访问令牌
+func (h *OAuthClientHandler) DeleteInitialAccessToken(c *gin.Context) {
+	appID := c.Param("id")
+	tokenID := c.Param("token_id")
+
+	if err := h.service.DeleteInitialAccessToken(c.Request.Context(), appID, tokenID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateAuthorizationDetailType 登记授权详情类型
+func (h *OAuthClientHandler) CreateAuthorizationDetailType(c *gin.Context) {
+	appID := c.Param("id")
+	var req model.CreateAuthorizationDetailTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	detailType, err := h.detailService.CreateType(c.Request.Context(), appID, &req)
+	if err != nil {
+		if err == service.ErrAuthorizationDetailTypeExists {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, detailType)
+}
+
+// ListAuthorizationDetailTypes 获取应用登记的授权详情类型
+func (h *OAuthClientHandler) ListAuthorizationDetailTypes(c *gin.Context) {
+	appID := c.Param("id")
+
+	types, err := h.detailService.ListTypes(c.Request.Context(), appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, types)
+}
+
+// DeleteAuthorizationDetailType 删除授权详情类型
+func (h *OAuthClientHandler) DeleteAuthorizationDetailType(c *gin.Context) {
+	appID := c.Param("id")
+
+	if err := h.detailService.DeleteType(c.Request.Context(), appID, c.Param("type")); err != nil {
+		if err == service.ErrAuthorizationDetailTypeNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateProtectedResource 登记受保护资源
+func (h *OAuthClientHandler) CreateProtectedResource(c *gin.Context) {
+	appID := c.Param("id")
+	var req model.CreateProtectedResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resource, err := h.resourceService.CreateResource(c.Request.Context(), appID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrProtectedResourceExists:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case service.ErrInvalidResourceIdentifier:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, resource)
+}
+
+// ListProtectedResources 获取应用登记的受保护资源
+func (h *OAuthClientHandler) ListProtectedResources(c *gin.Context) {
+	appID := c.Param("id")
+
+	resources, err := h.resourceService.ListResources(c.Request.Context(), appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resources)
+}
+
+// DeleteProtectedResource 删除受保护资源
+func (h *OAuthClientHandler) DeleteProtectedResource(c *gin.Context) {
+	appID := c.Param("id")
+
+	if err := h.resourceService.DeleteResource(c.Request.Context(), appID, c.Param("resource_id")); err != nil {
+		if err == service.ErrProtectedResourceNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -12,15 +12,15 @@ import (
 
 // OIDCHandler OIDC处理器
 type OIDCHandler struct {
-	oidcService  service.OIDCService
-	tokenService service.TokenService
+	oidcService service.OIDCService
+	keyService  service.SigningKeyService
 }
 
 // NewOIDCHandler 创建OIDC处理器实例
-func NewOIDCHandler(oidcService service.OIDCService, tokenService service.TokenService) *OIDCHandler {
+func NewOIDCHandler(oidcService service.OIDCService, keyService service.SigningKeyService) *OIDCHandler {
 	return &OIDCHandler{
-		oidcService:  oidcService,
-		tokenService: tokenService,
+		oidcService: oidcService,
+		keyService:  keyService,
 	}
 }
 
@@ -37,6 +37,12 @@ func (h *OIDCHandler) Register(group *gin.RouterGroup, authMiddleware *middlewar
 	}
 }
 
+// RegisterKeyRoutes 注册签名密钥管理路由，调用方负责添加超级管理员权限检查
+func (h *OIDCHandler) RegisterKeyRoutes(group *gin.RouterGroup) {
+	group.GET("", h.ListSigningKeys)
+	group.POST("/rotate", h.RotateSigningKeys)
+}
+
 // GetConfiguration 处理OIDC配置请求
 func (h *OIDCHandler) GetConfiguration(c *gin.Context) {
 	config, err := h.oidcService.GetConfiguration(c.Request.Context())
@@ -59,34 +65,52 @@ func (h *OIDCHandler) GetJWKS(c *gin.Context) {
 
 // GetUserInfo 处理UserInfo请求
 func (h *OIDCHandler) GetUserInfo(c *gin.Context) {
-	// 从认证中间件获取用户信息
+	// 从认证中间件获取令牌声明，中间件已完成受众与DPoP绑定的校验
 	claims := middleware.GetUserFromContext(c)
 	if claims == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	// 从Authorization头获取访问令牌
-	auth := c.GetHeader("Authorization")
-	if auth == "" || len(auth) <= 7 || auth[:7] != "Bearer " {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+	// 获取用户信息
+	userInfo, err := h.oidcService.GetUserInfo(c.Request.Context(), claims.UserID, claims.ClientID, claims.Scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
 		return
 	}
-	token := auth[7:]
 
-	// 获取令牌关联的scope
-	tokenClaims, err := h.tokenService.ValidateToken(c.Request.Context(), token, model.AccessToken)
+	c.JSON(http.StatusOK, userInfo)
+}
+
+// ListSigningKeys 获取签名密钥列表
+func (h *OIDCHandler) ListSigningKeys(c *gin.Context) {
+	keys, err := h.keyService.ListKeys(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list signing keys"})
 		return
 	}
+	c.JSON(http.StatusOK, keys)
+}
 
-	// 获取用户信息
-	userInfo, err := h.oidcService.GetUserInfo(c.Request.Context(), claims.UserID, tokenClaims.Scope)
+// RotateSigningKeys 轮换签名密钥
+func (h *OIDCHandler) RotateSigningKeys(c *gin.Context) {
+	var req model.RotateSigningKeysRequest
+	// 请求体可以为空，此时沿用当前密钥的算法
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	keys, err := h.keyService.RotateKeys(c.Request.Context(), req.Algorithm)
+	if err == service.ErrSigningKeyLocked {
+		c.JSON(http.StatusConflict, gin.H{"error": "signing keys are being rotated by another instance"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rotate signing keys"})
 		return
 	}
-
-	c.JSON(http.StatusOK, userInfo)
+	c.JSON(http.StatusOK, keys)
 }
@@ -36,6 +36,9 @@ const (
 	EventClientCreate EventType = "client_create"
 	EventClientUpdate EventType = "client_update"
 	EventClientDelete EventType = "client_delete"
+
+	// EventRedirectURIRejected 授权或登出请求的重定向URI未登记或不匹配
+	EventRedirectURIRejected EventType = "redirect_uri_rejected"
 )
 
 // AuditLog 审计日志结构
@@ -27,7 +27,12 @@ func InitDB(cfg *database.Config) (*gorm.DB, error) {
 		&model.RuleCondition{},
 		&model.OAuthClient{},
 		&model.OAuthClientSecret{},
-		&model.AuthorizationCode{},
+		&model.InitialAccessToken{},
+		&model.OAuthConsent{},
+		&model.AuthorizationDetailType{},
+		&model.ProtectedResource{},
+		&model.SigningKey{},
+		&model.PairwiseSubject{},
 		&model.PluginStatus{},
 		&model.PluginConfig{},
 		&model.VerificationSession{},
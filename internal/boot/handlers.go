@@ -1,6 +1,9 @@
 package boot
 
 import (
+	"path/filepath"
+	"time"
+
 	v1 "lauth/api/v1"
 	"lauth/internal/service"
 	"lauth/pkg/config"
@@ -20,6 +23,8 @@ type Handlers struct {
 	RuleHandler          *v1.RuleHandler
 	OAuthClientHandler   *v1.OAuthClientHandler
 	AuthorizationHandler *v1.AuthorizationHandler
+	HostedHandler        *v1.HostedHandler
+	RegistrationHandler  *v1.ClientRegistrationHandler
 	ProfileHandler       *v1.ProfileHandler
 	FileHandler          *v1.FileHandler
 	OIDCHandler          *v1.OIDCHandler
@@ -38,11 +43,13 @@ func InitHandlers(services *Services, repos *Repositories, auditComponents *Audi
 		RoleHandler:          v1.NewRoleHandler(services.RoleService),
 		PermissionHandler:    v1.NewPermissionHandler(services.PermissionService),
 		RuleHandler:          v1.NewRuleHandler(services.RuleService),
-		OAuthClientHandler:   v1.NewOAuthClientHandler(services.OAuthClientService),
-		AuthorizationHandler: v1.NewAuthorizationHandler(services.AuthorizationService),
+		OAuthClientHandler:   v1.NewOAuthClientHandler(services.OAuthClientService, services.AuthorizationDetailService, services.ProtectedResourceService),
+		AuthorizationHandler: v1.NewAuthorizationHandler(services.AuthorizationService, services.DeviceAuthorizationService, services.LogoutService),
+		HostedHandler:        v1.NewHostedHandler(services.HostedLoginService, services.AuthorizationService, services.TokenService, services.LogoutService, cfg.OIDC.Issuer, time.Duration(cfg.JWT.RefreshTokenExpire)*time.Second),
+		RegistrationHandler:  v1.NewClientRegistrationHandler(services.OAuthClientService),
 		ProfileHandler:       v1.NewProfileHandler(services.ProfileService),
 		FileHandler:          v1.NewFileHandler(services.FileService),
-		OIDCHandler:          v1.NewOIDCHandler(services.OIDCService, services.TokenService),
+		OIDCHandler:          v1.NewOIDCHandler(services.OIDCService, services.SigningKeyService),
 		AuditHandler:         v1.NewAuditHandler(auditComponents.Reader, auditComponents.WebSocketServer),
 		PluginHandler: v1.NewPluginHandler(
 			services.PluginManager,
@@ -67,7 +74,7 @@ func InitRouter(
 	services *Services,
 ) *router.Router {
 	// 初始化认证中间件
-	authMiddleware := middleware.NewAuthMiddleware(tokenService, cfg.Server.AuthEnabled)
+	authMiddleware := middleware.NewAuthMiddleware(tokenService, services.DPoPService, cfg.Server.AuthEnabled)
 
 	// 初始化超级管理员中间件
 	superAdminMiddleware := middleware.NewSuperAdminMiddleware(tokenService, services.SuperAdminService)
@@ -79,6 +86,13 @@ func InitRouter(
 		ipLocationService,
 	)
 
+	// 加载托管登录页面模板
+	templatePath := cfg.Server.TemplatePath
+	if templatePath == "" {
+		templatePath = "templates/pages"
+	}
+	engine.LoadHTMLGlob(filepath.Join(templatePath, "*.html"))
+
 	// 添加全局中间件
 	engine.Use(middleware.CORSMiddleware())
 	engine.Use(auditMiddleware.Handle())
@@ -95,6 +109,8 @@ func InitRouter(
 		handlers.RuleHandler,
 		handlers.OAuthClientHandler,
 		handlers.AuthorizationHandler,
+		handlers.HostedHandler,
+		handlers.RegistrationHandler,
 		handlers.ProfileHandler,
 		handlers.FileHandler,
 		handlers.OIDCHandler,
@@ -16,7 +16,12 @@ type Repositories struct {
 	RuleRepo                     repository.RuleRepository
 	OAuthClientRepo              repository.OAuthClientRepository
 	OAuthClientSecretRepo        repository.OAuthClientSecretRepository
-	AuthCodeRepo                 repository.AuthorizationCodeRepository
+	InitialAccessTokenRepo       repository.InitialAccessTokenRepository
+	OAuthConsentRepo             repository.OAuthConsentRepository
+	AuthorizationDetailTypeRepo  repository.AuthorizationDetailTypeRepository
+	ProtectedResourceRepo        repository.ProtectedResourceRepository
+	SigningKeyRepo               repository.SigningKeyRepository
+	PairwiseSubjectRepo          repository.PairwiseSubjectRepository
 	PluginStatusRepo             repository.PluginStatusRepository
 	PluginConfigRepo             repository.PluginConfigRepository
 	VerificationSessionRepo      repository.VerificationSessionRepository
@@ -38,7 +43,12 @@ func InitRepositories(db *gorm.DB, mongodb *database.MongoClient) *Repositories
 		RuleRepo:                     repository.NewRuleRepository(db),
 		OAuthClientRepo:              repository.NewOAuthClientRepository(db),
 		OAuthClientSecretRepo:        repository.NewOAuthClientSecretRepository(db),
-		AuthCodeRepo:                 repository.NewAuthorizationCodeRepository(db),
+		InitialAccessTokenRepo:       repository.NewInitialAccessTokenRepository(db),
+		OAuthConsentRepo:             repository.NewOAuthConsentRepository(db),
+		AuthorizationDetailTypeRepo:  repository.NewAuthorizationDetailTypeRepository(db),
+		ProtectedResourceRepo:        repository.NewProtectedResourceRepository(db),
+		SigningKeyRepo:               repository.NewSigningKeyRepository(db),
+		PairwiseSubjectRepo:          repository.NewPairwiseSubjectRepository(db),
 		PluginStatusRepo:             repository.NewPluginStatusRepository(db),
 		PluginConfigRepo:             repository.NewPluginConfigRepository(db),
 		VerificationSessionRepo:      repository.NewVerificationSessionRepository(db),
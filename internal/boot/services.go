@@ -30,11 +30,18 @@ type Services struct {
 	PermissionService            service.PermissionService
 	OAuthClientService           service.OAuthClientService
 	OIDCService                  service.OIDCService
+	SigningKeyService            service.SigningKeyService
 	AuthorizationService         service.AuthorizationService
+	AuthorizationDetailService   service.AuthorizationDetailService
+	ProtectedResourceService     service.ProtectedResourceService
+	DeviceAuthorizationService   service.DeviceAuthorizationService
+	DPoPService                  service.DPoPService
 	IPLocationService            service.IPLocationService
 	LoginLocationService         service.LoginLocationService
+	LogoutService                service.LogoutService
 	TokenService                 service.TokenService
 	SuperAdminService            service.SuperAdminService
+	HostedLoginService           service.HostedLoginService
 	PluginManager                types.Manager
 	PluginUserConfigRepo         repository.PluginUserConfigRepository
 	PluginVerificationRecordRepo repository.PluginVerificationRecordRepository
@@ -48,16 +55,37 @@ func InitServices(cfg *config.Config, repos *Repositories, redisClient *redis.Cl
 	// 初始化登录位置服务
 	loginLocationService := service.NewLoginLocationService(repos.LoginLocationRepo, ipLocationService)
 
+	// 初始化签名密钥，首次启动时导入配置文件中的密钥作为当前签名密钥
+	privateKey, _, err := crypto.LoadRSAKeys(cfg.OIDC.PrivateKeyPath, cfg.OIDC.PublicKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	signingKeyService := service.NewSigningKeyService(
+		repos.SigningKeyRepo,
+		redisClient,
+		time.Duration(cfg.JWT.AccessTokenExpire)*time.Hour,
+	)
+	if err := signingKeyService.EnsureKeys(context.Background(), privateKey); err != nil {
+		return nil, err
+	}
+
 	// 初始化Token服务
 	tokenService := service.NewTokenService(
 		redisClient,
 		cfg.JWT.Secret,
 		time.Duration(cfg.JWT.AccessTokenExpire)*time.Hour,
 		time.Duration(cfg.JWT.RefreshTokenExpire)*time.Second,
+		signingKeyService,
+		cfg.OIDC.Issuer,
+		cfg.JWT.AccessTokenFormat,
+		repos.PairwiseSubjectRepo,
 	)
 
+	// 初始化DPoP证明验证服务
+	dpopService := service.NewDPoPService(redisClient)
+
 	// 初始化认证中间件
-	authMiddleware := middleware.NewAuthMiddleware(tokenService, cfg.Server.AuthEnabled)
+	authMiddleware := middleware.NewAuthMiddleware(tokenService, dpopService, cfg.Server.AuthEnabled)
 
 	// 初始化规则引擎
 	ruleParser := engine.NewParser()
@@ -91,6 +119,13 @@ func InitServices(cfg *config.Config, repos *Repositories, redisClient *redis.Cl
 	userService := service.NewUserService(repos.UserRepo, repos.AppRepo, profileService)
 	ruleService := service.NewRuleService(repos.RuleRepo, ruleEngine)
 	verificationService := service.NewVerificationService(pluginManager, repos.PluginStatusRepo, repos.VerificationSessionRepo)
+
+	// 初始化OIDC服务
+	oidcService := service.NewOIDCService(repos.UserRepo, repos.OAuthClientRepo, repos.PairwiseSubjectRepo, tokenService, cfg, signingKeyService)
+
+	// 初始化登出服务
+	logoutService := service.NewLogoutService(repos.OAuthClientRepo, tokenService, oidcService, cfg.OIDC.Issuer)
+
 	authService := service.NewAuthService(
 		repos.UserRepo,
 		repos.AppRepo,
@@ -100,27 +135,76 @@ func InitServices(cfg *config.Config, repos *Repositories, redisClient *redis.Cl
 		profileService,
 		loginLocationService,
 		superAdminService,
+		logoutService,
+		dpopService,
 		db,
 	)
 	roleService := service.NewRoleService(repos.RoleRepo, repos.PermissionRepo, superAdminService)
 	permissionService := service.NewPermissionService(repos.PermissionRepo, repos.RoleRepo)
-	oauthClientService := service.NewOAuthClientService(repos.OAuthClientRepo, repos.OAuthClientSecretRepo)
+	oauthClientService := service.NewOAuthClientService(
+		repos.OAuthClientRepo,
+		repos.OAuthClientSecretRepo,
+		repos.InitialAccessTokenRepo,
+		cfg.OIDC.Issuer,
+	)
 
-	// 初始化OIDC服务
-	privateKey, publicKey, err := crypto.LoadRSAKeys(cfg.OIDC.PrivateKeyPath, cfg.OIDC.PublicKeyPath)
-	if err != nil {
-		return nil, err
-	}
-	oidcService := service.NewOIDCService(repos.UserRepo, tokenService, cfg, privateKey, publicKey)
+	// 初始化设备授权服务
+	deviceAuthorizationService := service.NewDeviceAuthorizationService(redisClient, cfg.OIDC.Issuer, cfg.OIDC.DeviceVerificationURI)
+
+	// 初始化推送授权请求服务
+	pushedAuthorizationService := service.NewPushedAuthorizationService(redisClient)
+
+	// 初始化客户端公钥服务
+	clientKeyService := service.NewClientKeyService(cfg.OIDC.Issuer)
+
+	// 初始化客户端认证器
+	clientAuthenticator := service.NewClientAuthenticator(
+		repos.OAuthClientRepo,
+		repos.OAuthClientSecretRepo,
+		clientKeyService,
+		redisClient,
+		cfg.OIDC.Issuer,
+	)
+
+	// 初始化授权详情服务
+	authorizationDetailService := service.NewAuthorizationDetailService(repos.AuthorizationDetailTypeRepo)
+
+	// 初始化受保护资源服务
+	protectedResourceService := service.NewProtectedResourceService(repos.ProtectedResourceRepo, cfg.OIDC.Issuer)
+
+	// 初始化授权码服务，授权码令牌族的记录保留到其颁发的刷新令牌过期
+	authorizationCodeService := service.NewAuthorizationCodeService(
+		redisClient,
+		tokenService,
+		time.Duration(cfg.JWT.RefreshTokenExpire)*time.Second,
+	)
 
 	// 初始化授权服务
 	authorizationService := service.NewAuthorizationService(
 		repos.OAuthClientRepo,
-		repos.OAuthClientSecretRepo,
-		repos.AuthCodeRepo,
 		repos.UserRepo,
+		repos.OAuthConsentRepo,
 		tokenService,
+		authorizationCodeService,
 		oidcService,
+		deviceAuthorizationService,
+		pushedAuthorizationService,
+		clientKeyService,
+		clientAuthenticator,
+		authorizationDetailService,
+		dpopService,
+		protectedResourceService,
+	)
+
+	// 初始化托管登录服务
+	hostedLoginService := service.NewHostedLoginService(
+		redisClient,
+		repos.OAuthClientRepo,
+		repos.AppRepo,
+		repos.UserRepo,
+		authService,
+		verificationService,
+		pluginManager,
 	)
 
 	return &Services{
@@ -135,11 +219,18 @@ func InitServices(cfg *config.Config, repos *Repositories, redisClient *redis.Cl
 		PermissionService:            permissionService,
 		OAuthClientService:           oauthClientService,
 		OIDCService:                  oidcService,
+		SigningKeyService:            signingKeyService,
 		AuthorizationService:         authorizationService,
+		AuthorizationDetailService:   authorizationDetailService,
+		ProtectedResourceService:     protectedResourceService,
+		DeviceAuthorizationService:   deviceAuthorizationService,
+		DPoPService:                  dpopService,
 		IPLocationService:            ipLocationService,
 		LoginLocationService:         loginLocationService,
+		LogoutService:                logoutService,
 		TokenService:                 tokenService,
 		SuperAdminService:            superAdminService,
+		HostedLoginService:           hostedLoginService,
 		PluginManager:                pluginManager,
 		PluginUserConfigRepo:         repos.PluginUserConfigRepo,
 		PluginVerificationRecordRepo: repos.PluginVerificationRecordRepo,
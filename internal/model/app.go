@@ -25,6 +25,17 @@ type App struct {
 	Status      AppStatus `gorm:"type:int;default:1" json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Branding 托管登录页面使用的应用品牌
+	Branding AppBranding `gorm:"type:jsonb;serializer:json" json:"branding"`
+}
+
+// AppBranding 托管登录、插件验证与授权同意页面的应用品牌
+type AppBranding struct {
+	DisplayName   string `json:"display_name,omitempty"`                                      // 页面显示的名称，为空时使用应用名称
+	LogoURL       string `json:"logo_url,omitempty" binding:"omitempty,url"`                  // Logo图片地址
+	PrimaryColor  string `json:"primary_color,omitempty" binding:"omitempty,hexcolor"`        // 主题色，如#1677ff
+	DefaultLocale string `json:"default_locale,omitempty" binding:"omitempty,oneof=en zh-CN"` // 授权请求未指定ui_locales时的页面语言
 }
 
 // BeforeCreate GORM的钩子，在创建记录前自动生成UUID和密钥
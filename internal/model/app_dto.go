@@ -11,6 +11,9 @@ type UpdateAppRequest struct {
 	Name        string    `json:"name" binding:"required"`
 	Description string    `json:"description"`
 	Status      AppStatus `json:"status"`
+
+	// Branding 托管页面的应用品牌，未提供时保持不变
+	Branding *AppBranding `json:"branding"`
 }
 
 // AppResponse 应用响应
@@ -21,6 +24,8 @@ type AppResponse struct {
 	Status      AppStatus `json:"status"`
 	CreatedAt   string    `json:"created_at"`
 	UpdatedAt   string    `json:"updated_at"`
+
+	Branding AppBranding `json:"branding"`
 }
 
 // AppCredentialsResponse 应用凭证响应
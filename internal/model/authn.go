@@ -0,0 +1,15 @@
+package model
+
+// 认证方式引用值(RFC 8176)，写入令牌的amr声明
+const (
+	AMRPassword = "pwd"   // 密码认证
+	AMROTP      = "otp"   // 一次性口令(TOTP)
+	AMREmail    = "email" // 邮箱验证码
+	AMRMFA      = "mfa"   // 多因素认证
+)
+
+// 认证上下文类，写入令牌的acr声明，数值越大认证强度越高
+const (
+	ACRSingleFactor = "1" // 仅完成密码认证
+	ACRMultiFactor  = "2" // 密码之外至少完成一种验证插件
+)
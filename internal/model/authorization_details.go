@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// AuthorizationDetail 授权详情中的一项(RFC 9396)，type决定其余字段的含义
+type AuthorizationDetail map[string]interface{}
+
+// Type 获取授权详情的类型
+func (d AuthorizationDetail) Type() string {
+	t, _ := d["type"].(string)
+	return t
+}
+
+// AuthorizationDetailType 应用登记的授权详情类型
+// 授权请求中的每一项授权详情都必须属于客户端所在应用登记的类型，并满足该类型的字段约束
+type AuthorizationDetailType struct {
+	ID             string         `json:"id" gorm:"primaryKey;type:uuid"`
+	AppID          string         `json:"app_id" gorm:"type:uuid;uniqueIndex:idx_authorization_detail_type_app_type"`
+	Type           string         `json:"type" gorm:"type:varchar(255);uniqueIndex:idx_authorization_detail_type_app_type"`
+	Description    string         `json:"description" gorm:"type:varchar(200)"`
+	RequiredFields pq.StringArray `json:"required_fields" gorm:"type:text[]"` // 必须出现的字段
+	AllowedFields  pq.StringArray `json:"allowed_fields" gorm:"type:text[]"`  // 允许出现的字段，为空时不限制
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// BeforeCreate GORM的钩子，在创建记录前自动生成UUID
+func (t *AuthorizationDetailType) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName 指定表名
+func (AuthorizationDetailType) TableName() string {
+	return "oauth_authorization_detail_types"
+}
+
+// CreateAuthorizationDetailTypeRequest 登记授权详情类型请求
+type CreateAuthorizationDetailTypeRequest struct {
+	Type           string   `json:"type" binding:"required"`
+	Description    string   `json:"description"`
+	RequiredFields []string `json:"required_fields"`
+	AllowedFields  []string `json:"allowed_fields"`
+}
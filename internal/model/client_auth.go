@@ -0,0 +1,17 @@
+package model
+
+// ClientAssertionTypeJWTBearer 使用JWT断言认证客户端(RFC 7523)
+const ClientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// ClientAuthentication 请求中携带的客户端认证信息
+// 令牌、内省、吊销、推送授权与设备授权端点共用
+type ClientAuthentication struct {
+	ClientID            string `form:"client_id"`             // 使用JWT断言时可省略，取自断言的sub
+	ClientSecret        string `form:"client_secret"`         // 公开客户端可为空
+	ClientAssertionType string `form:"client_assertion_type"` // 客户端断言类型
+	ClientAssertion     string `form:"client_assertion"`      // 客户端签名的JWT断言
+
+	// AuthMethod 请求实际使用的认证方式(token_endpoint_auth_method)
+	// 处理器在凭证来自HTTP Basic认证时预先设置，其余情况由客户端认证器确定
+	AuthMethod string `form:"-"`
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 客户端认证方式(RFC 7591 token_endpoint_auth_method)
+const (
+	TokenEndpointAuthNone              = "none"
+	TokenEndpointAuthClientSecretBasic = "client_secret_basic"
+	TokenEndpointAuthClientSecretPost  = "client_secret_post"
+	TokenEndpointAuthClientSecretJWT   = "client_secret_jwt"
+	TokenEndpointAuthPrivateKeyJWT     = "private_key_jwt"
+)
+
+// InitialAccessToken 动态客户端注册的初始访问令牌，由管理员为应用签发
+type InitialAccessToken struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:uuid"`
+	AppID       string    `json:"app_id" gorm:"index;type:uuid"`
+	TokenHash   string    `json:"-" gorm:"type:varchar(64);uniqueIndex"` // 令牌的SHA-256摘要，不保存明文
+	Description string    `json:"description" gorm:"type:varchar(200)"`
+	MaxUses     int       `json:"max_uses" gorm:"default:0"` // 最大使用次数，0表示不限
+	UsedCount   int       `json:"used_count" gorm:"default:0"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BeforeCreate GORM的钩子，在创建记录前自动生成UUID
+func (t *InitialAccessToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName 指定表名
+func (InitialAccessToken) TableName() string {
+	return "oauth_initial_access_tokens"
+}
+
+// CreateInitialAccessTokenRequest 创建初始访问令牌请求
+type CreateInitialAccessTokenRequest struct {
+	Description string `json:"description" binding:"required"`
+	ExpiresIn   int64  `json:"expires_in" binding:"required,min=60"` // 有效期(秒)
+	MaxUses     int    `json:"max_uses" binding:"min=0"`
+}
+
+// InitialAccessTokenResponse 初始访问令牌响应
+type InitialAccessTokenResponse struct {
+	ID          string `json:"id"`
+	Token       string `json:"token,omitempty"` // 仅在创建时返回
+	Description string `json:"description"`
+	MaxUses     int    `json:"max_uses"`
+	UsedCount   int    `json:"used_count"`
+	ExpiresAt   string `json:"expires_at"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// ClientRegistrationRequest 客户端注册元数据(RFC 7591)
+type ClientRegistrationRequest struct {
+	ClientID                string   `json:"client_id,omitempty"` // 仅在更新注册时使用(RFC 7592)
+	ClientName              string   `json:"client_name"`
+	RedirectURIs            []string `json:"redirect_uris"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+	GrantTypes              []string `json:"grant_types"`
+	ResponseTypes           []string `json:"response_types"`
+	Scope                   string   `json:"scope"`
+
+	RequirePushedAuthorizationRequests bool `json:"require_pushed_authorization_requests,omitempty"`
+
+	JWKSURI                 string          `json:"jwks_uri,omitempty"`
+	JWKS                    json.RawMessage `json:"jwks,omitempty"`
+	RequestObjectSigningAlg string          `json:"request_object_signing_alg,omitempty"`
+
+	SubjectType         string `json:"subject_type,omitempty"`
+	SectorIdentifierURI string `json:"sector_identifier_uri,omitempty"`
+
+	PostLogoutRedirectURIs            []string `json:"post_logout_redirect_uris,omitempty"`
+	FrontchannelLogoutURI             string   `json:"frontchannel_logout_uri,omitempty"`
+	FrontchannelLogoutSessionRequired bool     `json:"frontchannel_logout_session_required,omitempty"`
+	BackchannelLogoutURI              string   `json:"backchannel_logout_uri,omitempty"`
+	BackchannelLogoutSessionRequired  bool     `json:"backchannel_logout_session_required,omitempty"`
+}
+
+// ClientRegistrationResponse 客户端注册响应(RFC 7591/7592)
+type ClientRegistrationResponse struct {
+	ClientID                string   `json:"client_id"`
+	ClientSecret            string   `json:"client_secret,omitempty"`
+	ClientIDIssuedAt        int64    `json:"client_id_issued_at"`
+	ClientSecretExpiresAt   int64    `json:"client_secret_expires_at,omitempty"`
+	RegistrationAccessToken string   `json:"registration_access_token,omitempty"`
+	RegistrationClientURI   string   `json:"registration_client_uri"`
+	ClientName              string   `json:"client_name"`
+	RedirectURIs            []string `json:"redirect_uris"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+	GrantTypes              []string `json:"grant_types"`
+	ResponseTypes           []string `json:"response_types"`
+	Scope                   string   `json:"scope"`
+
+	RequirePushedAuthorizationRequests bool `json:"require_pushed_authorization_requests,omitempty"`
+
+	JWKSURI                 string          `json:"jwks_uri,omitempty"`
+	JWKS                    json.RawMessage `json:"jwks,omitempty"`
+	RequestObjectSigningAlg string          `json:"request_object_signing_alg,omitempty"`
+
+	SubjectType         string `json:"subject_type,omitempty"`
+	SectorIdentifierURI string `json:"sector_identifier_uri,omitempty"`
+
+	PostLogoutRedirectURIs            []string `json:"post_logout_redirect_uris,omitempty"`
+	FrontchannelLogoutURI             string   `json:"frontchannel_logout_uri,omitempty"`
+	FrontchannelLogoutSessionRequired bool     `json:"frontchannel_logout_session_required,omitempty"`
+	BackchannelLogoutURI              string   `json:"backchannel_logout_uri,omitempty"`
+	BackchannelLogoutSessionRequired  bool     `json:"backchannel_logout_session_required,omitempty"`
+}
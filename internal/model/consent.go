@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// OAuthConsent 用户对OAuth客户端的授权同意记录
+type OAuthConsent struct {
+	ID        string         `json:"id" gorm:"primaryKey;type:uuid"`
+	UserID    string         `json:"user_id" gorm:"type:uuid;uniqueIndex:idx_oauth_consent_user_client"`
+	ClientID  string         `json:"client_id" gorm:"type:varchar(100);uniqueIndex:idx_oauth_consent_user_client"`
+	AppID     string         `json:"app_id" gorm:"index;type:uuid"`
+	Scopes    pq.StringArray `json:"scopes" gorm:"type:text[]"` // 用户已同意授予的权限范围
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+
+	// AuthorizationDetails 用户已批准的授权详情(RFC 9396)
+	AuthorizationDetails []AuthorizationDetail `json:"authorization_details,omitempty" gorm:"type:jsonb;serializer:json"`
+}
+
+// BeforeCreate GORM的钩子，在创建记录前自动生成UUID
+func (c *OAuthConsent) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName 指定表名
+func (OAuthConsent) TableName() string {
+	return "oauth_consents"
+}
+
+// ConsentRequest 用户对授权请求的同意决定，携带原始授权请求参数
+type ConsentRequest struct {
+	AuthorizationRequest
+	Approve bool `json:"approve" form:"approve"` // true同意，false拒绝
+}
+
+// ConsentPrompt 需要用户确认的授权同意信息
+type ConsentPrompt struct {
+	ConsentRequired bool     `json:"consent_required"`
+	ClientID        string   `json:"client_id"`
+	ClientName      string   `json:"client_name"`
+	RequestedScopes []string `json:"requested_scopes"`
+	GrantedScopes   []string `json:"granted_scopes"` // 之前已同意的权限范围
+
+	// 请求的与之前已批准的授权详情(RFC 9396)
+	RequestedAuthorizationDetails []AuthorizationDetail `json:"requested_authorization_details,omitempty"`
+	GrantedAuthorizationDetails   []AuthorizationDetail `json:"granted_authorization_details,omitempty"`
+}
+
+// ConsentResponse 用户已授权的客户端
+type ConsentResponse struct {
+	ClientID   string   `json:"client_id"`
+	ClientName string   `json:"client_name"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at"`
+
+	AuthorizationDetails []AuthorizationDetail `json:"authorization_details,omitempty"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// DeviceCodeStatus 设备授权状态
+type DeviceCodeStatus string
+
+const (
+	DeviceCodePending  DeviceCodeStatus = "pending"  // 等待用户确认
+	DeviceCodeApproved DeviceCodeStatus = "approved" // 用户已批准
+	DeviceCodeDenied   DeviceCodeStatus = "denied"   // 用户已拒绝
+)
+
+// DeviceCode 设备授权会话(RFC 8628)，以JSON形式存储于Redis
+type DeviceCode struct {
+	DeviceCode string           `json:"device_code"`
+	UserCode   string           `json:"user_code"`
+	ClientID   string           `json:"client_id"`
+	ClientName string           `json:"client_name"`
+	AppID      string           `json:"app_id"`
+	Scope      string           `json:"scope"`
+	Status     DeviceCodeStatus `json:"status"`
+	UserID     string           `json:"user_id,omitempty"` // 批准或拒绝授权的用户
+	AuthTime   time.Time        `json:"auth_time"`         // 批准用户完成认证的时间
+	ACR        string           `json:"acr,omitempty"`
+	AMR        []string         `json:"amr,omitempty"`
+	SessionID  string           `json:"sid,omitempty"` // 批准用户的登录会话
+	Interval   int              `json:"interval"`      // 最小轮询间隔(秒)
+	ExpiresAt  time.Time        `json:"expires_at"`
+}
+
+// DeviceAuthorizationRequest 设备授权请求
+type DeviceAuthorizationRequest struct {
+	ClientAuthentication
+	Scope string `form:"scope"`
+}
+
+// DeviceAuthorizationResponse 设备授权响应
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// DeviceCodeInfo 展示给用户确认的设备授权信息
+type DeviceCodeInfo struct {
+	UserCode   string    `json:"user_code"`
+	ClientID   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	Scope      string    `json:"scope"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// DeviceVerificationRequest 用户确认设备授权请求
+type DeviceVerificationRequest struct {
+	UserCode string `json:"user_code" binding:"required"`
+	Approve  bool   `json:"approve"` // true批准，false拒绝
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Interaction 托管页面上等待用户登录、完成插件验证或确认授权的授权请求
+// 保存在Redis中，通过浏览器Cookie关联，授权完成或终止后删除
+type Interaction struct {
+	ID         string               `json:"id"`
+	AppID      string               `json:"app_id"`      // 客户端所在的应用
+	ClientName string               `json:"client_name"` // 客户端名称
+	Branding   AppBranding          `json:"branding"`    // 应用品牌
+	AppName    string               `json:"app_name"`    // 应用名称，品牌未设置显示名称时使用
+	Request    AuthorizationRequest `json:"request"`     // 解析后的授权请求
+	CSRFToken  string               `json:"csrf_token"`  // 页面表单必须携带的CSRF令牌
+	ExpiresAt  time.Time            `json:"expires_at"`
+
+	// 密码验证通过但需要完成插件验证的登录
+	UserID                string              `json:"user_id,omitempty"`
+	VerificationSessionID string              `json:"verification_session_id,omitempty"`
+	Plugins               []PluginRequirement `json:"plugins,omitempty"`
+}
+
+// HostedLoginRequest 托管登录页面提交的表单
+type HostedLoginRequest struct {
+	Username  string `form:"username" binding:"required"`
+	Password  string `form:"password" binding:"required"`
+	CSRFToken string `form:"csrf_token" binding:"required"`
+}
+
+// HostedVerifyRequest 托管插件验证页面提交的表单
+type HostedVerifyRequest struct {
+	Plugin    string `form:"plugin" binding:"required"`
+	Operation string `form:"operation" binding:"required,oneof=send verify"`
+	Code      string `form:"code"`
+	CSRFToken string `form:"csrf_token" binding:"required"`
+}
+
+// HostedConsentRequest 托管授权同意页面提交的表单
+type HostedConsentRequest struct {
+	Approve   bool   `form:"approve"`
+	CSRFToken string `form:"csrf_token" binding:"required"`
+}
+
+// HostedVerificationPlugin 托管插件验证页面展示的验证插件
+type HostedVerificationPlugin struct {
+	Name      string // 插件名称
+	Completed bool   // 是否已完成验证
+	CanSend   bool   // 是否支持由页面触发发送验证码
+}
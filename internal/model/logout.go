@@ -0,0 +1,22 @@
+package model
+
+// BackchannelLogoutEvent 后端通道登出令牌的事件类型(OIDC Back-Channel Logout 2.4)
+const BackchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// EndSessionRequest RP发起的登出请求(OIDC RP-Initiated Logout)
+type EndSessionRequest struct {
+	IDTokenHint           string `json:"id_token_hint" form:"id_token_hint"`                       // 之前颁发给客户端的ID Token
+	ClientID              string `json:"client_id" form:"client_id"`                               // 未携带id_token_hint时用于识别客户端
+	PostLogoutRedirectURI string `json:"post_logout_redirect_uri" form:"post_logout_redirect_uri"` // 登出后重定向URI，必须已在客户端注册
+	State                 string `json:"state" form:"state"`                                       // 原样附加到登出后重定向URI
+
+	// Confirmed 用户已在登出确认页面确认登出
+	Confirmed bool `json:"-" form:"-"`
+}
+
+// EndSessionResponse 登出响应
+type EndSessionResponse struct {
+	RedirectURL string `json:"redirect_url,omitempty"` // 登出后重定向URL，未指定时为空
+	// FrontchannelLogoutURIs 需要由浏览器加载(如iframe)的前端通道登出URL
+	FrontchannelLogoutURIs []string `json:"frontchannel_logout_uris"`
+}
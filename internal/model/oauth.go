@@ -3,8 +3,7 @@ package model
 import (
 	"time"
 
-	"encoding/base64"
-	"math/rand"
+	"encoding/json"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -31,15 +30,26 @@ const (
 	Password               OAuthGrantType = "password"
 	Implicit               OAuthGrantType = "implicit"
 	RefreshTokenGrant      OAuthGrantType = "refresh_token"
+	DeviceCodeGrant        OAuthGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	TokenExchangeGrant     OAuthGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
 )
 
 // ResponseType 响应类型
 type ResponseType string
 
 const (
-	CodeResponse        ResponseType = "code"
-	IDTokenResponse     ResponseType = "id_token"
-	CodeIDTokenResponse ResponseType = "code id_token"
+	CodeResponse             ResponseType = "code"
+	IDTokenResponse          ResponseType = "id_token"
+	IDTokenTokenResponse     ResponseType = "id_token token"
+	CodeIDTokenResponse      ResponseType = "code id_token"
+	CodeIDTokenTokenResponse ResponseType = "code id_token token"
+)
+
+// 授权响应参数的返回方式(OAuth 2.0 Multiple Response Types/Form Post Response Mode)
+const (
+	ResponseModeQuery    = "query"
+	ResponseModeFragment = "fragment"
+	ResponseModeFormPost = "form_post"
 )
 
 // OAuthClient OAuth客户端
@@ -53,9 +63,36 @@ type OAuthClient struct {
 	GrantTypes   pq.StringArray  `json:"grant_types" gorm:"type:text[]"`
 	RedirectURIs pq.StringArray  `json:"redirect_uris" gorm:"type:text[]"`
 	Scopes       pq.StringArray  `json:"scopes" gorm:"type:text[]"`
-	Status       bool            `json:"status" gorm:"default:true"`
-	CreatedAt    time.Time       `json:"created_at"`
-	UpdatedAt    time.Time       `json:"updated_at"`
+	RequirePKCE  bool            `json:"require_pkce" gorm:"default:false"` // 是否强制使用PKCE(公开客户端始终强制)
+	// RedirectURIPatterns 管理员配置的重定向URI通配模式(如https://*.preview.example.com/callback)，动态注册不能设置
+	RedirectURIPatterns pq.StringArray `json:"redirect_uri_patterns" gorm:"type:text[]"`
+	// RequirePushedAuthorizationRequests 是否只接受推送的授权请求(RFC 9126)
+	RequirePushedAuthorizationRequests bool `json:"require_pushed_authorization_requests" gorm:"default:false"`
+	// TokenEndpointAuthMethod 客户端认证方式，为空时按客户端类型接受密钥认证或不认证
+	TokenEndpointAuthMethod string `json:"token_endpoint_auth_method" gorm:"type:varchar(30)"`
+	// 客户端公钥(RFC 7591 jwks_uri/jwks)，用于验证客户端签名的请求对象与认证断言，两者只能设置其一
+	JWKSURI string `json:"jwks_uri" gorm:"type:varchar(500)"`
+	JWKS    string `json:"jwks" gorm:"type:text"` // JWK Set JSON
+	// RequestObjectSigningAlg 请求对象的签名算法，为空时接受所有支持的非对称算法
+	RequestObjectSigningAlg string `json:"request_object_signing_alg" gorm:"type:varchar(10)"`
+	// TokenExchangeAudiences 令牌交换(RFC 8693)时客户端可以申请的目标受众
+	TokenExchangeAudiences pq.StringArray `json:"token_exchange_audiences" gorm:"type:text[]"`
+	// AuthorizationDetailsTypes 客户端凭证授权时客户端可以申请的授权详情类型(RFC 9396 10)
+	AuthorizationDetailsTypes pq.StringArray `json:"authorization_details_types" gorm:"type:text[]"`
+	// 主体标识类型(OIDC Core 8)，为空时等同public；pairwise按扇区标识为用户派生不同的sub
+	SubjectType         string `json:"subject_type" gorm:"type:varchar(20)"`
+	SectorIdentifierURI string `json:"sector_identifier_uri" gorm:"type:varchar(500)"`
+	// 登出配置(OIDC RP-Initiated/Front-Channel/Back-Channel Logout)
+	PostLogoutRedirectURIs            pq.StringArray `json:"post_logout_redirect_uris" gorm:"type:text[]"`
+	FrontchannelLogoutURI             string         `json:"frontchannel_logout_uri" gorm:"type:varchar(500)"`
+	FrontchannelLogoutSessionRequired bool           `json:"frontchannel_logout_session_required" gorm:"default:false"`
+	BackchannelLogoutURI              string         `json:"backchannel_logout_uri" gorm:"type:varchar(500)"`
+	BackchannelLogoutSessionRequired  bool           `json:"backchannel_logout_session_required" gorm:"default:false"`
+	// RegistrationAccessTokenHash 动态注册客户端的注册访问令牌摘要，管理员创建的客户端为空
+	RegistrationAccessTokenHash string    `json:"-" gorm:"type:varchar(64);index"`
+	Status                      bool      `json:"status" gorm:"default:true"`
+	CreatedAt                   time.Time `json:"created_at"`
+	UpdatedAt                   time.Time `json:"updated_at"`
 }
 
 // BeforeCreate GORM的钩子，在创建记录前自动生成UUID和客户端凭证
@@ -81,18 +118,64 @@ func (OAuthClient) TableName() string {
 type CreateOAuthClientRequest struct {
 	Name         string          `json:"name" binding:"required"`
 	Type         OAuthClientType `json:"type" binding:"required,oneof=confidential public"`
-	GrantTypes   []string        `json:"grant_types" binding:"required,dive,oneof=authorization_code client_credentials password implicit refresh_token"`
+	GrantTypes   []string        `json:"grant_types" binding:"required,dive,oneof=authorization_code client_credentials password implicit refresh_token urn:ietf:params:oauth:grant-type:device_code urn:ietf:params:oauth:grant-type:token-exchange"`
 	RedirectURIs []string        `json:"redirect_uris" binding:"omitempty,required_unless=Type public,dive,url"`
 	Scopes       []string        `json:"scopes" binding:"required"`
+	RequirePKCE  bool            `json:"require_pkce"` // 机密客户端是否强制使用PKCE
+
+	RedirectURIPatterns []string `json:"redirect_uri_patterns"` // *只能作为主机名最左侧的一级子域名
+
+	RequirePushedAuthorizationRequests bool `json:"require_pushed_authorization_requests"`
+
+	TokenEndpointAuthMethod string          `json:"token_endpoint_auth_method" binding:"omitempty,oneof=none client_secret_basic client_secret_post client_secret_jwt private_key_jwt"`
+	JWKSURI                 string          `json:"jwks_uri" binding:"omitempty,url"`
+	JWKS                    json.RawMessage `json:"jwks"`
+	RequestObjectSigningAlg string          `json:"request_object_signing_alg"`
+
+	TokenExchangeAudiences []string `json:"token_exchange_audiences"`
+
+	AuthorizationDetailsTypes []string `json:"authorization_details_types"`
+
+	SubjectType         string `json:"subject_type" binding:"omitempty,oneof=public pairwise"`
+	SectorIdentifierURI string `json:"sector_identifier_uri" binding:"omitempty,url"`
+
+	PostLogoutRedirectURIs            []string `json:"post_logout_redirect_uris" binding:"omitempty,dive,url"`
+	FrontchannelLogoutURI             string   `json:"frontchannel_logout_uri" binding:"omitempty,url"`
+	FrontchannelLogoutSessionRequired bool     `json:"frontchannel_logout_session_required"`
+	BackchannelLogoutURI              string   `json:"backchannel_logout_uri" binding:"omitempty,url"`
+	BackchannelLogoutSessionRequired  bool     `json:"backchannel_logout_session_required"`
 }
 
 // UpdateOAuthClientRequest 更新OAuth客户端请求
 type UpdateOAuthClientRequest struct {
 	Name         string   `json:"name"`
-	GrantTypes   []string `json:"grant_types" binding:"omitempty,dive,oneof=authorization_code client_credentials password implicit refresh_token"`
+	GrantTypes   []string `json:"grant_types" binding:"omitempty,dive,oneof=authorization_code client_credentials password implicit refresh_token urn:ietf:params:oauth:grant-type:device_code urn:ietf:params:oauth:grant-type:token-exchange"`
 	RedirectURIs []string `json:"redirect_uris" binding:"omitempty,dive,url"`
 	Scopes       []string `json:"scopes"`
+	RequirePKCE  *bool    `json:"require_pkce"`
 	Status       *bool    `json:"status"`
+
+	RedirectURIPatterns []string `json:"redirect_uri_patterns"` // 传空数组表示清除
+
+	RequirePushedAuthorizationRequests *bool `json:"require_pushed_authorization_requests"`
+
+	TokenEndpointAuthMethod *string         `json:"token_endpoint_auth_method" binding:"omitempty,oneof=none client_secret_basic client_secret_post client_secret_jwt private_key_jwt"`
+	JWKSURI                 *string         `json:"jwks_uri"`                   // 传空字符串表示清除
+	JWKS                    json.RawMessage `json:"jwks"`                       // 传null表示清除
+	RequestObjectSigningAlg *string         `json:"request_object_signing_alg"` // 传空字符串表示接受所有支持的算法
+
+	TokenExchangeAudiences []string `json:"token_exchange_audiences"` // 传空数组表示禁止令牌交换
+
+	AuthorizationDetailsTypes []string `json:"authorization_details_types"` // 传空数组表示客户端凭证授权不能申请授权详情
+
+	SubjectType         *string `json:"subject_type" binding:"omitempty,oneof=public pairwise"`
+	SectorIdentifierURI *string `json:"sector_identifier_uri"` // 传空字符串表示清除
+
+	PostLogoutRedirectURIs            []string `json:"post_logout_redirect_uris" binding:"omitempty,dive,url"` // 传空数组表示清除
+	FrontchannelLogoutURI             *string  `json:"frontchannel_logout_uri"`                                // 传空字符串表示清除
+	FrontchannelLogoutSessionRequired *bool    `json:"frontchannel_logout_session_required"`
+	BackchannelLogoutURI              *string  `json:"backchannel_logout_uri"` // 传空字符串表示清除
+	BackchannelLogoutSessionRequired  *bool    `json:"backchannel_logout_session_required"`
 }
 
 // OAuthClientResponse OAuth客户端响应
@@ -105,9 +188,32 @@ type OAuthClientResponse struct {
 	GrantTypes   []string        `json:"grant_types"`
 	RedirectURIs []string        `json:"redirect_uris"`
 	Scopes       []string        `json:"scopes"`
+	RequirePKCE  bool            `json:"require_pkce"`
 	Status       bool            `json:"status"`
 	CreatedAt    string          `json:"created_at"`
 	UpdatedAt    string          `json:"updated_at"`
+
+	RedirectURIPatterns []string `json:"redirect_uri_patterns"`
+
+	RequirePushedAuthorizationRequests bool `json:"require_pushed_authorization_requests"`
+
+	TokenEndpointAuthMethod string          `json:"token_endpoint_auth_method,omitempty"`
+	JWKSURI                 string          `json:"jwks_uri,omitempty"`
+	JWKS                    json.RawMessage `json:"jwks,omitempty"`
+	RequestObjectSigningAlg string          `json:"request_object_signing_alg,omitempty"`
+
+	TokenExchangeAudiences []string `json:"token_exchange_audiences"`
+
+	AuthorizationDetailsTypes []string `json:"authorization_details_types"`
+
+	SubjectType         string `json:"subject_type,omitempty"`
+	SectorIdentifierURI string `json:"sector_identifier_uri,omitempty"`
+
+	PostLogoutRedirectURIs            []string `json:"post_logout_redirect_uris"`
+	FrontchannelLogoutURI             string   `json:"frontchannel_logout_uri,omitempty"`
+	FrontchannelLogoutSessionRequired bool     `json:"frontchannel_logout_session_required"`
+	BackchannelLogoutURI              string   `json:"backchannel_logout_uri,omitempty"`
+	BackchannelLogoutSessionRequired  bool     `json:"backchannel_logout_session_required"`
 }
 
 // OAuthClientSecret OAuth客户端秘钥
@@ -143,68 +249,128 @@ type ClientSecretResponse struct {
 }
 
 // AuthorizationRequest OAuth授权请求
+// 使用request_uri(RFC 9126)时其余参数来自推送的授权请求，因此除client_id外的必填参数由服务层校验
 type AuthorizationRequest struct {
-	ResponseType ResponseType `json:"response_type" form:"response_type" binding:"required,oneof=code 'id_token' 'code id_token'"` // 响应类型
-	ClientID     string       `json:"client_id" form:"client_id" binding:"required"`                                               // 客户端ID
-	RedirectURI  string       `json:"redirect_uri" form:"redirect_uri" binding:"required,url"`                                     // 重定向URI
-	Scope        string       `json:"scope" form:"scope" binding:"required"`                                                       // 申请的权限范围
-	State        string       `json:"state" form:"state" binding:"required"`                                                       // 状态参数
+	ResponseType ResponseType `json:"response_type" form:"response_type" binding:"omitempty,oneof=code 'id_token' 'id_token token' 'code id_token' 'code id_token token'"` // 响应类型
+	ResponseMode string       `json:"response_mode" form:"response_mode" binding:"omitempty,oneof=query fragment form_post"`                                               // 响应参数返回方式，默认由响应类型决定
+	ClientID     string       `json:"client_id" form:"client_id" binding:"required"`                                                                                       // 客户端ID
+	RedirectURI  string       `json:"redirect_uri" form:"redirect_uri" binding:"omitempty,url"`                                                                            // 重定向URI
+	Scope        string       `json:"scope" form:"scope"`                                                                                                                  // 申请的权限范围
+	State        string       `json:"state" form:"state"`                                                                                                                  // 状态参数
+	RequestURI   string       `json:"request_uri" form:"request_uri"`                                                                                                      // 推送授权请求的引用(RFC 9126)
+	Request      string       `json:"request" form:"request"`                                                                                                              // 客户端签名的请求对象(RFC 9101)
 
 	// OIDC特定参数
 	Nonce       string `json:"nonce" form:"nonce"`                 // OIDC nonce参数
 	Display     string `json:"display" form:"display"`             // 显示类型(page, popup, touch, wap)
 	Prompt      string `json:"prompt" form:"prompt"`               // 提示类型(none, login, consent, select_account)
-	MaxAge      int    `json:"max_age" form:"max_age"`             // 最大认证时间(秒)
+	MaxAge      *int   `json:"max_age" form:"max_age"`             // 最大认证时间(秒)，未提供时为nil
 	UILocales   string `json:"ui_locales" form:"ui_locales"`       // UI语言偏好
 	IDTokenHint string `json:"id_token_hint" form:"id_token_hint"` // 之前颁发的ID Token
 	LoginHint   string `json:"login_hint" form:"login_hint"`       // 登录提示
 	ACRValues   string `json:"acr_values" form:"acr_values"`       // 请求的认证上下文类型
+
+	// PKCE参数(RFC 7636)
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge"`               // 授权码挑战值
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method"` // 挑战值计算方法(plain, S256)
+
+	// AuthorizationDetails JSON编码的授权详情数组(RFC 9396)
+	AuthorizationDetails string `json:"authorization_details,omitempty" form:"authorization_details"`
+	// Resource 访问令牌的目标资源(RFC 8707)，可以有多个
+	Resource []string `json:"resource,omitempty" form:"resource"`
 }
 
-// AuthorizationCode OAuth授权码
-type AuthorizationCode struct {
-	ID          string    `json:"id" gorm:"primaryKey;type:uuid"`
-	Code        string    `json:"code" gorm:"type:varchar(100);uniqueIndex"` // 授权码
-	ClientID    string    `json:"client_id" gorm:"type:varchar(100);index"`  // 客户端ID
-	UserID      string    `json:"user_id" gorm:"type:varchar(100);index"`    // 用户ID
-	RedirectURI string    `json:"redirect_uri" gorm:"type:varchar(500)"`     // 重定向URI
-	Scope       string    `json:"scope" gorm:"type:varchar(500)"`            // 授权范围
-	ExpiresAt   time.Time `json:"expires_at"`                                // 过期时间
-	CreatedAt   time.Time `json:"created_at"`                                // 创建时间
+// AuthContext 发起授权请求的用户认证上下文
+type AuthContext struct {
+	UserID    string
+	AppID     string
+	SessionID string    // 用户的登录会话ID
+	AuthTime  time.Time // 用户完成认证的时间
+	ACR       string    // 认证上下文类
+	AMR       []string  // 认证方式引用
 }
 
-// TableName 指定表名
-func (AuthorizationCode) TableName() string {
-	return "oauth_authorization_codes"
+// IDTokenOptions 生成ID Token的选项
+type IDTokenOptions struct {
+	Nonce     string    // 授权请求中的nonce
+	AuthTime  time.Time // 用户完成认证的时间
+	ACR       string    // 认证上下文类
+	AMR       []string  // 认证方式引用
+	SessionID string    // 用户的登录会话ID，写入sid声明
+
+	// 与ID Token一同颁发的访问令牌与授权码，分别写入at_hash与c_hash声明
+	AccessToken string
+	Code        string
 }
 
-// BeforeCreate GORM的钩子，在创建记录前自动生成UUID和授权码
-func (ac *AuthorizationCode) BeforeCreate(tx *gorm.DB) error {
-	if ac.ID == "" {
-		ac.ID = uuid.New().String()
-	}
-	if ac.Code == "" {
-		// 生成32字节的随机授权码
-		codeBytes := make([]byte, 32)
-		if _, err := rand.Read(codeBytes); err != nil {
-			return err
-		}
-		ac.Code = base64.URLEncoding.EncodeToString(codeBytes)
-	}
-	return nil
+// AuthorizationResult 授权端点的响应
+// query与fragment模式下RedirectURL为携带响应参数的完整URL；form_post模式下RedirectURL为重定向URI，
+// 由前端将FormParams以表单POST提交到该地址
+type AuthorizationResult struct {
+	RedirectURL  string            `json:"redirect_url"`
+	ResponseMode string            `json:"response_mode,omitempty"`
+	FormParams   map[string]string `json:"form_params,omitempty"`
+}
+
+// AuthorizationCode OAuth授权码，保存在Redis中，兑换时原子地取出并删除
+type AuthorizationCode struct {
+	Code        string    `json:"-"`            // 授权码
+	ClientID    string    `json:"client_id"`    // 客户端ID
+	UserID      string    `json:"user_id"`      // 用户ID
+	RedirectURI string    `json:"redirect_uri"` // 重定向URI
+	Scope       string    `json:"scope"`        // 授权范围
+	ExpiresAt   time.Time `json:"expires_at"`   // 过期时间
+	CreatedAt   time.Time `json:"created_at"`   // 创建时间
+	// FamilyID 以授权码换取的令牌所属的令牌族，授权码被重复兑换时据此吊销
+	FamilyID string `json:"family_id"`
+
+	// PKCE参数(RFC 7636)
+	CodeChallenge       string `json:"code_challenge"`        // 授权码挑战值
+	CodeChallengeMethod string `json:"code_challenge_method"` // 挑战值计算方法
+
+	// OIDC认证信息，换取令牌时写入ID Token
+	AuthTime time.Time `json:"auth_time"` // 用户完成认证的时间
+	Nonce    string    `json:"nonce"`     // 授权请求中的nonce
+	ACR      string    `json:"acr"`       // 认证上下文类
+	AMR      []string  `json:"amr"`       // 认证方式引用
+	// SessionID 授权时用户的登录会话ID，会话结束时据此通知客户端登出
+	SessionID string `json:"sid"`
+
+	// AuthorizationDetails 用户批准的授权详情(RFC 9396)，换取令牌时写入访问令牌
+	AuthorizationDetails []AuthorizationDetail `json:"authorization_details,omitempty"`
+	// Resources 授权请求指定的目标资源(RFC 8707)，换取令牌时只能从中选择
+	Resources []string `json:"resources,omitempty"`
 }
 
 // TokenRequest OAuth令牌请求
 type TokenRequest struct {
+	ClientAuthentication
 	GrantType    string `form:"grant_type" binding:"required"`
 	Code         string `form:"code"`
 	RedirectURI  string `form:"redirect_uri"`
-	ClientID     string `form:"client_id" binding:"required"`
-	ClientSecret string `form:"client_secret" binding:"required"`
 	RefreshToken string `form:"refresh_token"`
-
-	// OIDC特定参数
-	Nonce string `form:"nonce"` // OIDC nonce参数
+	CodeVerifier string `form:"code_verifier"` // PKCE校验码(RFC 7636)
+	Scope        string `form:"scope"`         // 申请的权限范围(客户端凭证授权使用)
+	DeviceCode   string `form:"device_code"`   // 设备码(设备授权使用)
+
+	// 令牌交换参数(RFC 8693)
+	SubjectToken       string   `form:"subject_token"`        // 代表用户身份的令牌
+	SubjectTokenType   string   `form:"subject_token_type"`   // 主体令牌类型
+	ActorToken         string   `form:"actor_token"`          // 代表行事参与方的令牌，委托时提供
+	ActorTokenType     string   `form:"actor_token_type"`     // 参与方令牌类型
+	RequestedTokenType string   `form:"requested_token_type"` // 申请的令牌类型
+	Audience           []string `form:"audience"`             // 目标受众，可以有多个
+
+	// AuthorizationDetails JSON编码的授权详情数组(RFC 9396)，授权码授权时只能缩小已批准的范围
+	AuthorizationDetails string `form:"authorization_details"`
+	// Resource 访问令牌的目标资源(RFC 8707)，授权码与刷新令牌授权时只能从已授权的资源中选择
+	Resource []string `form:"resource"`
+
+	// DPoP证明(RFC 9449)，来自DPoP请求头，DPoPURL为令牌请求的实际URL
+	// 证明验证通过后DPoPJKT为证明公钥的指纹，颁发的令牌绑定到该公钥
+	DPoPProof string `form:"-"`
+	DPoPURL   string `form:"-"`
+	DPoPJKT   string `form:"-"`
 }
 
 // TokenResponse OAuth令牌响应
@@ -217,8 +383,62 @@ type TokenResponse struct {
 
 	// OIDC特定响应
 	IDToken string `json:"id_token,omitempty"` // ID令牌(仅在scope包含openid时返回)
+
+	// IssuedTokenType 颁发的令牌类型(仅令牌交换返回)
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+
+	// AuthorizationDetails 访问令牌携带的授权详情(RFC 9396)
+	AuthorizationDetails []AuthorizationDetail `json:"authorization_details,omitempty"`
+}
+
+// IntrospectionRequest 令牌内省请求(RFC 7662)
+type IntrospectionRequest struct {
+	ClientAuthentication
+	Token         string `form:"token" binding:"required"`
+	TokenTypeHint string `form:"token_type_hint"` // access_token 或 refresh_token，仅作提示
+	Resource      string `form:"resource"`        // 调用方资源标识，令牌的受众不包含该资源时视为无效
 }
 
+// IntrospectionResponse 令牌内省响应(RFC 7662)
+// 令牌无效时仅返回active=false，不透露其他信息
+type IntrospectionResponse struct {
+	Active    bool        `json:"active"`
+	Scope     string      `json:"scope,omitempty"`
+	ClientID  string      `json:"client_id,omitempty"`
+	Username  string      `json:"username,omitempty"`
+	Sub       string      `json:"sub,omitempty"`
+	Exp       int64       `json:"exp,omitempty"`
+	Iat       int64       `json:"iat,omitempty"`
+	TokenType string      `json:"token_type,omitempty"`
+	Aud       []string    `json:"aud,omitempty"`
+	Act       *TokenActor `json:"act,omitempty"`
+
+	AuthorizationDetails []AuthorizationDetail `json:"authorization_details,omitempty"` // 授权详情(RFC 9396)
+	Cnf                  *TokenConfirmation    `json:"cnf,omitempty"`                   // DPoP绑定的公钥(RFC 9449)
+}
+
+// RevocationRequest 令牌吊销请求(RFC 7009)
+type RevocationRequest struct {
+	ClientAuthentication
+	Token         string `form:"token" binding:"required"`
+	TokenTypeHint string `form:"token_type_hint"` // access_token 或 refresh_token，仅作提示
+}
+
+// 令牌类型标识(RFC 8693 3)
+const (
+	TokenTypeAccessToken  = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeRefreshToken = "urn:ietf:params:oauth:token-type:refresh_token"
+	TokenTypeIDToken      = "urn:ietf:params:oauth:token-type:id_token"
+	TokenTypeJWT          = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+const (
+	// TokenTypeHintAccessToken 访问令牌类型提示
+	TokenTypeHintAccessToken = "access_token"
+	// TokenTypeHintRefreshToken 刷新令牌类型提示
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
 // TokenError OAuth令牌错误响应
 type TokenError struct {
 	Error            string `json:"error"`
@@ -230,6 +450,12 @@ const (
 	GrantTypeAuthorizationCode = "authorization_code"
 	// GrantTypeRefreshToken 刷新令牌授权类型
 	GrantTypeRefreshToken = "refresh_token"
+	// GrantTypeClientCredentials 客户端凭证授权类型
+	GrantTypeClientCredentials = "client_credentials"
+	// GrantTypeDeviceCode 设备授权类型(RFC 8628)
+	GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+	// GrantTypeTokenExchange 令牌交换授权类型(RFC 8693)
+	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
 
 	// 错误类型
 	ErrorInvalidRequest       = "invalid_request"
@@ -238,6 +464,48 @@ const (
 	ErrorUnauthorizedClient   = "unauthorized_client"
 	ErrorUnsupportedGrantType = "unsupported_grant_type"
 	ErrorInvalidScope         = "invalid_scope"
+	ErrorInvalidTarget        = "invalid_target" // 不允许的目标受众(RFC 8693)
+
+	// 设备授权轮询错误类型(RFC 8628)
+	ErrorAuthorizationPending = "authorization_pending"
+	ErrorSlowDown             = "slow_down"
+	ErrorExpiredToken         = "expired_token"
+	ErrorAccessDenied         = "access_denied"
+
+	// 动态客户端注册错误类型(RFC 7591)
+	ErrorInvalidRedirectURI    = "invalid_redirect_uri"
+	ErrorInvalidClientMetadata = "invalid_client_metadata"
+	ErrorInvalidToken          = "invalid_token"
+
+	// OIDC授权错误类型
+	ErrorLoginRequired   = "login_required"
+	ErrorConsentRequired = "consent_required"
+
+	ErrorUnmetAuthenticationRequirements = "unmet_authentication_requirements"
+
+	// 推送授权请求与请求对象错误类型(RFC 9126/9101)
+	ErrorInvalidRequestURI    = "invalid_request_uri"
+	ErrorInvalidRequestObject = "invalid_request_object"
+
+	// 授权详情错误类型(RFC 9396)
+	ErrorInvalidAuthorizationDetails = "invalid_authorization_details"
+
+	// DPoP证明错误类型(RFC 9449)
+	ErrorInvalidDPoPProof = "invalid_dpop_proof"
+)
+
+// 令牌类型(token_type)
+const (
+	// TokenTypeBearer Bearer令牌(RFC 6750)
+	TokenTypeBearer = "Bearer"
+	// TokenTypeDPoP 绑定DPoP公钥的令牌(RFC 9449)
+	TokenTypeDPoP = "DPoP"
+)
+
+// PKCE挑战值计算方法(RFC 7636)
+const (
+	CodeChallengeMethodPlain = "plain"
+	CodeChallengeMethodS256  = "S256"
 )
 
 // OIDC相关常量
@@ -252,6 +520,12 @@ const (
 	// OIDC标准响应类型
 	ResponseTypeIDToken     = "id_token"
 	ResponseTypeIDTokenCode = "code id_token"
+
+	// OIDC prompt参数取值
+	PromptNone          = "none"
+	PromptLogin         = "login"
+	PromptConsent       = "consent"
+	PromptSelectAccount = "select_account"
 )
 
 // OIDCClaims OpenID Connect标准Claims
@@ -264,11 +538,14 @@ type OIDCClaims struct {
 	IssuedAt  int64  `json:"iat"`
 
 	// 认证相关Claims
-	AuthTime int64  `json:"auth_time,omitempty"`
-	Nonce    string `json:"nonce,omitempty"`
-	ACR      string `json:"acr,omitempty"`
-	AMR      string `json:"amr,omitempty"`
-	AZP      string `json:"azp,omitempty"`
+	AuthTime int64    `json:"auth_time,omitempty"`
+	Nonce    string   `json:"nonce,omitempty"`
+	ACR      string   `json:"acr,omitempty"`
+	AMR      []string `json:"amr,omitempty"`
+	AZP      string   `json:"azp,omitempty"`
+	SID      string   `json:"sid,omitempty"`     // 登录会话ID
+	AtHash   string   `json:"at_hash,omitempty"` // 访问令牌摘要
+	CHash    string   `json:"c_hash,omitempty"`  // 授权码摘要
 
 	// 用户信息Claims
 	Name              string `json:"name,omitempty"`
@@ -350,9 +627,28 @@ type OIDCConfiguration struct {
 	UserInfoEndpoint                 string   `json:"userinfo_endpoint"`
 	JWKSUri                          string   `json:"jwks_uri"`
 	RegistrationEndpoint             string   `json:"registration_endpoint,omitempty"`
+	IntrospectionEndpoint            string   `json:"introspection_endpoint,omitempty"`
+	RevocationEndpoint               string   `json:"revocation_endpoint,omitempty"`
+	DeviceAuthorizationEndpoint      string   `json:"device_authorization_endpoint,omitempty"`
+	EndSessionEndpoint               string   `json:"end_session_endpoint,omitempty"`
+	PushedAuthorizationEndpoint      string   `json:"pushed_authorization_request_endpoint,omitempty"`
+	RequirePushedAuthorization       bool     `json:"require_pushed_authorization_requests"`
+	RequestParameterSupported        bool     `json:"request_parameter_supported"`
+	RequestObjectSigningAlgs         []string `json:"request_object_signing_alg_values_supported,omitempty"`
+	TokenEndpointAuthMethods         []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	TokenEndpointAuthSigningAlgs     []string `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"`
+	DPoPSigningAlgs                  []string `json:"dpop_signing_alg_values_supported,omitempty"`
 	ScopesSupported                  []string `json:"scopes_supported"`
 	ResponseTypesSupported           []string `json:"response_types_supported"`
+	ResponseModesSupported           []string `json:"response_modes_supported,omitempty"`
 	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	ACRValuesSupported               []string `json:"acr_values_supported,omitempty"`
 	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
 	ClaimsSupported                  []string `json:"claims_supported"`
+	CodeChallengeMethodsSupported    []string `json:"code_challenge_methods_supported,omitempty"`
+
+	FrontchannelLogoutSupported        bool `json:"frontchannel_logout_supported"`
+	FrontchannelLogoutSessionSupported bool `json:"frontchannel_logout_session_supported"`
+	BackchannelLogoutSupported         bool `json:"backchannel_logout_supported"`
+	BackchannelLogoutSessionSupported  bool `json:"backchannel_logout_session_supported"`
 }
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// 客户端的主体标识类型(OIDC Core 8)
+const (
+	// SubjectTypePublic 所有客户端看到相同的sub
+	SubjectTypePublic = "public"
+	// SubjectTypePairwise 每个扇区看到不同的sub，客户端之间无法关联用户
+	SubjectTypePairwise = "pairwise"
+)
+
+// PairwiseSubject 成对主体标识与用户的对应关系
+// 成对sub由扇区标识与密钥盐单向派生，保存对应关系以便将客户端提交的sub解析回用户
+type PairwiseSubject struct {
+	SectorIdentifier string    `json:"sector_identifier" gorm:"primaryKey;type:varchar(255)"`
+	Subject          string    `json:"sub" gorm:"primaryKey;type:varchar(64);index"`
+	UserID           string    `json:"user_id" gorm:"type:varchar(100);index"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (PairwiseSubject) TableName() string {
+	return "oidc_pairwise_subjects"
+}
@@ -0,0 +1,18 @@
+package model
+
+// RequestURIPrefix 推送授权请求引用的URN前缀(RFC 9126 2.2)
+const RequestURIPrefix = "urn:ietf:params:oauth:request_uri:"
+
+// PushedAuthorizationRequest 推送授权请求(RFC 9126)
+// 携带与授权端点相同的参数，并由客户端认证
+type PushedAuthorizationRequest struct {
+	AuthorizationRequest
+	// Client 客户端认证信息，client_id与授权参数共用，由处理器从表单与Authorization头中提取
+	Client ClientAuthentication `json:"-" form:"-"`
+}
+
+// PushedAuthorizationResponse 推送授权响应
+type PushedAuthorizationResponse struct {
+	RequestURI string `json:"request_uri"`
+	ExpiresIn  int64  `json:"expires_in"`
+}
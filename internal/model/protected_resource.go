@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// ProtectedResource 应用登记的受保护资源(API)
+// 客户端通过resource参数(RFC 8707)指定资源标识，颁发的访问令牌受众限定为该资源
+type ProtectedResource struct {
+	ID          string         `json:"id" gorm:"primaryKey;type:uuid"`
+	AppID       string         `json:"app_id" gorm:"type:uuid;uniqueIndex:idx_protected_resource_app_identifier"`
+	Identifier  string         `json:"identifier" gorm:"type:varchar(255);uniqueIndex:idx_protected_resource_app_identifier"` // 资源标识，不含片段的绝对URI
+	Name        string         `json:"name" gorm:"type:varchar(100)"`
+	Description string         `json:"description" gorm:"type:varchar(200)"`
+	Scopes      pq.StringArray `json:"scopes" gorm:"type:text[]"` // 资源接受的权限范围
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// BeforeCreate GORM的钩子，在创建记录前自动生成UUID
+func (r *ProtectedResource) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName 指定表名
+func (ProtectedResource) TableName() string {
+	return "oauth_protected_resources"
+}
+
+// CreateProtectedResourceRequest 登记受保护资源请求
+type CreateProtectedResourceRequest struct {
+	Identifier  string   `json:"identifier" binding:"required"`
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Scopes      []string `json:"scopes"`
+}
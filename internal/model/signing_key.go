@@ -0,0 +1,63 @@
+package model
+
+import (
+	"crypto"
+	"time"
+)
+
+// SigningKeyStatus 签名密钥状态
+type SigningKeyStatus string
+
+const (
+	// SigningKeyNext 下一个签名密钥，提前在JWKS中发布但尚未用于签名
+	SigningKeyNext SigningKeyStatus = "next"
+	// SigningKeyCurrent 当前签名密钥
+	SigningKeyCurrent SigningKeyStatus = "current"
+	// SigningKeyRetired 已退役的密钥，不再用于签名，过期前仍在JWKS中发布用于验签
+	SigningKeyRetired SigningKeyStatus = "retired"
+)
+
+// SigningKey OIDC令牌签名密钥
+type SigningKey struct {
+	ID          string           `json:"kid" gorm:"primaryKey;type:varchar(64)"` // JWK指纹(RFC 7638)
+	Algorithm   string           `json:"alg" gorm:"type:varchar(10)"`
+	Status      SigningKeyStatus `json:"status" gorm:"type:varchar(20);index"`
+	PrivateKey  string           `json:"-" gorm:"type:text"` // PKCS#1(RSA)或SEC 1(EC) PEM，明文存储，数据库访问权限需按私钥文件同等保护
+	ActivatedAt *time.Time       `json:"activated_at"`       // 开始用于签名的时间
+	RetiredAt   *time.Time       `json:"retired_at"`         // 停止用于签名的时间
+	ExpiresAt   *time.Time       `json:"expires_at"`         // 退役密钥停止发布的时间
+	CreatedAt   time.Time        `json:"created_at"`
+
+	// Key 解析后的私钥(*rsa.PrivateKey或*ecdsa.PrivateKey)，不持久化
+	Key crypto.Signer `json:"-" gorm:"-"`
+}
+
+// TableName 指定表名
+func (SigningKey) TableName() string {
+	return "oidc_signing_keys"
+}
+
+// 支持的签名算法
+const (
+	// SigningAlgorithmRS256 RSA签名
+	SigningAlgorithmRS256 = "RS256"
+	// SigningAlgorithmES256 P-256椭圆曲线签名
+	SigningAlgorithmES256 = "ES256"
+)
+
+// RotateSigningKeysRequest 轮换签名密钥请求
+type RotateSigningKeysRequest struct {
+	// Algorithm 新生成的下一个密钥使用的算法，为空时沿用当前密钥的算法
+	Algorithm string `json:"algorithm" binding:"omitempty,oneof=RS256 ES256"`
+}
+
+// SigningKeyResponse 签名密钥响应(不含私钥)
+type SigningKeyResponse struct {
+	Kid         string           `json:"kid"`
+	Algorithm   string           `json:"alg"`
+	Status      SigningKeyStatus `json:"status"`
+	ActivatedAt string           `json:"activated_at,omitempty"`
+	RetiredAt   string           `json:"retired_at,omitempty"`
+	ExpiresAt   string           `json:"expires_at,omitempty"`
+	CreatedAt   string           `json:"created_at"`
+}
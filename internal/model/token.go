@@ -8,6 +8,16 @@ type TokenType string
 const (
 	AccessToken  TokenType = "access"
 	RefreshToken TokenType = "refresh"
+	// ClientAccessToken 客户端凭证授权颁发的服务令牌，不代表任何用户
+	ClientAccessToken TokenType = "client_access"
+)
+
+// 访问令牌格式
+const (
+	// AccessTokenFormatJWT 使用共享密钥HS256签名的访问令牌
+	AccessTokenFormatJWT = "jwt"
+	// AccessTokenFormatATJWT 使用OIDC签名密钥签名的访问令牌(RFC 9068)，资源服务器可通过JWKS离线验证
+	AccessTokenFormatATJWT = "at+jwt"
 )
 
 // TokenClaims JWT令牌的声明
@@ -15,9 +25,42 @@ type TokenClaims struct {
 	UserID    string    `json:"user_id"`
 	AppID     string    `json:"app_id"`
 	Username  string    `json:"username"`
+	ClientID  string    `json:"client_id,omitempty"` // 服务令牌所属的OAuth客户端
+	FamilyID  string    `json:"family_id,omitempty"` // 令牌族ID，同一次颁发及其后续刷新的令牌共享
+	SessionID string    `json:"sid,omitempty"`       // 登录会话ID，登录颁发的令牌族ID即会话ID
 	Type      TokenType `json:"type"`
+	IssuedAt  time.Time `json:"issued_at"`
+	AuthTime  time.Time `json:"auth_time"`     // 用户完成认证的时间，刷新令牌时保持不变
+	ACR       string    `json:"acr,omitempty"` // 认证上下文类
+	AMR       []string  `json:"amr,omitempty"` // 认证方式引用
 	ExpiresAt time.Time `json:"expires_at"`
 	Scope     string    `json:"scope,omitempty"`
+
+	// Subject pairwise客户端看到的成对sub(OIDC Core 8)，此时令牌中不包含用户ID与用户名，解析时由sub映射回用户
+	Subject string `json:"sub,omitempty"`
+
+	// 令牌交换(RFC 8693)颁发的令牌限定受众，委托时记录代表用户行事的参与方
+	Audience []string    `json:"aud,omitempty"`
+	Actor    *TokenActor `json:"act,omitempty"`
+
+	// AuthorizationDetails 用户批准的授权详情(RFC 9396)
+	AuthorizationDetails []AuthorizationDetail `json:"authorization_details,omitempty"`
+
+	// Confirmation 令牌绑定的DPoP公钥(RFC 9449)，使用令牌时必须提供该密钥签名的证明
+	Confirmation *TokenConfirmation `json:"cnf,omitempty"`
+}
+
+// TokenConfirmation 令牌的持有者证明信息(RFC 7800 cnf声明)
+type TokenConfirmation struct {
+	JKT string `json:"jkt"` // DPoP证明公钥的JWK指纹
+}
+
+// TokenActor 代表令牌主体行事的参与方(RFC 8693 act声明)
+// 多次委托时嵌套记录，最早的参与方位于最内层
+type TokenActor struct {
+	Subject  string      `json:"sub"`
+	ClientID string      `json:"client_id,omitempty"`
+	Actor    *TokenActor `json:"act,omitempty"`
 }
 
 // GetExpiresAt 获取过期时间
@@ -25,6 +68,62 @@ func (tc *TokenClaims) GetExpiresAt() time.Time {
 	return tc.ExpiresAt
 }
 
+// IsClientToken 是否为客户端服务令牌
+func (tc *TokenClaims) IsClientToken() bool {
+	return tc.Type == ClientAccessToken
+}
+
+// DPoPJKT 获取令牌绑定的DPoP公钥指纹，未绑定的Bearer令牌返回空
+func (tc *TokenClaims) DPoPJKT() string {
+	if tc.Confirmation == nil {
+		return ""
+	}
+	return tc.Confirmation.JKT
+}
+
+// TokenOptions 生成令牌对的选项
+type TokenOptions struct {
+	ClientID string    // 颁发令牌的OAuth客户端，直接登录时为空
+	Scope    string    // 权限范围
+	FamilyID string    // 令牌族ID，刷新时沿用原令牌族，授权码换取时使用授权码分配的令牌族，为空时生成新的令牌族
+	AuthTime time.Time // 用户完成认证的时间，为空时取当前时间
+	ACR      string    // 认证上下文类
+	AMR      []string  // 认证方式引用
+	// SessionID 登录会话ID，OAuth授权颁发的令牌记录授权时用户所在的会话；
+	// 直接登录时为空，令牌族即成为新的会话
+	SessionID string
+	// AuthorizationDetails 用户批准的授权详情(RFC 9396)
+	AuthorizationDetails []AuthorizationDetail
+	// DPoPJKT 令牌绑定的DPoP公钥指纹(RFC 9449)，为空时颁发Bearer令牌
+	DPoPJKT string
+	// Resources 授权覆盖的受保护资源(RFC 8707)，作为刷新令牌的受众，刷新时可从中选择访问令牌的受众
+	Resources []string
+	// Audience 访问令牌的受众，为空时取Resources
+	Audience []string
+	// Subject pairwise客户端看到的成对sub，非空时令牌以其代替用户ID与用户名
+	Subject string
+}
+
+// ClientTokenOptions 客户端凭证授权生成服务令牌的选项
+type ClientTokenOptions struct {
+	Scope    string   // 权限范围
+	Audience []string // 受众，客户端申请的受保护资源(RFC 8707)
+	DPoPJKT  string   // 令牌绑定的DPoP公钥指纹，为空时颁发Bearer令牌
+
+	// AuthorizationDetails 客户端申请的授权详情(RFC 9396)
+	AuthorizationDetails []AuthorizationDetail
+}
+
+// TokenExchangeOptions 令牌交换生成访问令牌的选项
+type TokenExchangeOptions struct {
+	ClientID string      // 发起交换的客户端
+	Scope    string      // 权限范围，不超过主体令牌的权限范围
+	Audience []string    // 目标受众
+	Actor    *TokenActor // 委托时的参与方，模拟时为空
+	DPoPJKT  string      // 令牌绑定的DPoP公钥指纹，为空时颁发Bearer令牌
+	Subject  string      // 发起交换的客户端为pairwise时看到的成对sub
+}
+
 // TokenPair 令牌对
 type TokenPair struct {
 	AccessToken          string        `json:"access_token"`
@@ -35,7 +134,19 @@ type TokenPair struct {
 
 // TokenUserInfo Token中包含的用户信息（快速接口使用）
 type TokenUserInfo struct {
-	UserID   string `json:"user_id"`
-	AppID    string `json:"app_id"`
-	Username string `json:"username"`
+	UserID    string    `json:"user_id"`
+	AppID     string    `json:"app_id"`
+	Username  string    `json:"username"`
+	ClientID  string    `json:"client_id,omitempty"` // 服务令牌所属的OAuth客户端
+	TokenType TokenType `json:"token_type"`          // 令牌类型(access为用户令牌，client_access为服务令牌)
+
+	// Confirmation 令牌绑定的DPoP公钥(RFC 9449)，请求已附带与之匹配的证明
+	Confirmation *TokenConfirmation `json:"cnf,omitempty"`
+}
+
+// DPoPPresentation 以DPoP方案携带令牌的请求(RFC 9449 7)
+type DPoPPresentation struct {
+	Proof  string // DPoP请求头中的证明
+	Method string // 请求方法
+	URL    string // 请求URL(不含查询参数)
 }
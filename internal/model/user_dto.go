@@ -62,6 +62,9 @@ type LoginRequest struct {
 	DeviceID   string `json:"device_id,omitempty"`   // 设备ID
 	DeviceType string `json:"device_type,omitempty"` // 设备类型
 	UserAgent  string `json:"user_agent,omitempty"`  // User-Agent
+
+	// ACRValues 授权请求要求的认证上下文类，登录时需完成满足该认证强度的验证插件
+	ACRValues string `json:"acr_values,omitempty"`
 }
 
 // LoginResponse 登录响应
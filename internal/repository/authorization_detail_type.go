@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"lauth/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// AuthorizationDetailTypeRepository 授权详情类型仓储接口
+type AuthorizationDetailTypeRepository interface {
+	// Create 登记授权详情类型
+	Create(ctx context.Context, detailType *model.AuthorizationDetailType) error
+	// Get 获取应用登记的授权详情类型
+	Get(ctx context.Context, appID, detailType string) (*model.AuthorizationDetailType, error)
+	// ListByAppID 获取应用登记的所有授权详情类型
+	ListByAppID(ctx context.Context, appID string) ([]*model.AuthorizationDetailType, error)
+	// Delete 删除应用登记的授权详情类型
+	Delete(ctx context.Context, appID, detailType string) error
+}
+
+// authorizationDetailTypeRepository 授权详情类型仓储实现
+type authorizationDetailTypeRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthorizationDetailTypeRepository 创建授权详情类型仓储实例
+func NewAuthorizationDetailTypeRepository(db *gorm.DB) AuthorizationDetailTypeRepository {
+	return &authorizationDetailTypeRepository{db: db}
+}
+
+// Create 登记授权详情类型
+func (r *authorizationDetailTypeRepository) Create(ctx context.Context, detailType *model.AuthorizationDetailType) error {
+	return r.db.WithContext(ctx).Create(detailType).Error
+}
+
+// Get 获取应用登记的授权详情类型
+func (r *authorizationDetailTypeRepository) Get(ctx context.Context, appID, detailType string) (*model.AuthorizationDetailType, error) {
+	var t model.AuthorizationDetailType
+	err := r.db.WithContext(ctx).Where("app_id = ? AND type = ?", appID, detailType).First(&t).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListByAppID 获取应用登记的所有授权详情类型
+func (r *authorizationDetailTypeRepository) ListByAppID(ctx context.Context, appID string) ([]*model.AuthorizationDetailType, error) {
+	var types []*model.AuthorizationDetailType
+	err := r.db.WithContext(ctx).Where("app_id = ?", appID).Order("type").Find(&types).Error
+	return types, err
+}
+
+// Delete 删除应用登记的授权详情类型
+func (r *authorizationDetailTypeRepository) Delete(ctx context.Context, appID, detailType string) error {
+	return r.db.WithContext(ctx).Delete(&model.AuthorizationDetailType{}, "app_id = ? AND type = ?", appID, detailType).Error
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"lauth/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// InitialAccessTokenRepository 初始访问令牌仓储接口
+type InitialAccessTokenRepository interface {
+	// Create 创建初始访问令牌
+	Create(ctx context.Context, token *model.InitialAccessToken) error
+	// Delete 删除应用下的初始访问令牌
+	Delete(ctx context.Context, appID, id string) error
+	// GetByTokenHash 通过令牌摘要获取初始访问令牌
+	GetByTokenHash(ctx context.Context, tokenHash string) (*model.InitialAccessToken, error)
+	// ListByAppID 获取应用的所有初始访问令牌
+	ListByAppID(ctx context.Context, appID string) ([]*model.InitialAccessToken, error)
+	// IncrementUsage 增加使用次数，超过最大使用次数时返回false
+	IncrementUsage(ctx context.Context, id string) (bool, error)
+}
+
+// initialAccessTokenRepository 初始访问令牌仓储实现
+type initialAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewInitialAccessTokenRepository 创建初始访问令牌仓储实例
+func NewInitialAccessTokenRepository(db *gorm.DB) InitialAccessTokenRepository {
+	return &initialAccessTokenRepository{db: db}
+}
+
+// Create 创建初始访问令牌
+func (r *initialAccessTokenRepository) Create(ctx context.Context, token *model.InitialAccessToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// Delete 删除应用下的初始访问令牌
+func (r *initialAccessTokenRepository) Delete(ctx context.Context, appID, id string) error {
+	return r.db.WithContext(ctx).Delete(&model.InitialAccessToken{}, "id = ? AND app_id = ?", id, appID).Error
+}
+
+// GetByTokenHash 通过令牌摘要获取初始访问令牌
+func (r *initialAccessTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*model.InitialAccessToken, error) {
+	var token model.InitialAccessToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListByAppID 获取应用的所有初始访问令牌
+func (r *initialAccessTokenRepository) ListByAppID(ctx context.Context, appID string) ([]*model.InitialAccessToken, error) {
+	var tokens []*model.InitialAccessToken
+	err := r.db.WithContext(ctx).
+		Where("app_id = ?", appID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// IncrementUsage 增加使用次数
+func (r *initialAccessTokenRepository) IncrementUsage(ctx context.Context, id string) (bool, error) {
+	// 在同一条UPDATE中判断次数上限，避免并发注册超出限制
+	result := r.db.WithContext(ctx).
+		Model(&model.InitialAccessToken{}).
+		Where("id = ? AND (max_uses = 0 OR used_count < max_uses)", id).
+		UpdateColumn("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
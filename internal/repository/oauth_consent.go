@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"lauth/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// OAuthConsentRepository 授权同意记录仓储接口
+type OAuthConsentRepository interface {
+	// Save 创建或更新授权同意记录
+	Save(ctx context.Context, consent *model.OAuthConsent) error
+	// Get 获取用户对客户端的授权同意记录
+	Get(ctx context.Context, userID, clientID string) (*model.OAuthConsent, error)
+	// ListByUserID 获取用户的所有授权同意记录
+	ListByUserID(ctx context.Context, userID string) ([]*model.OAuthConsent, error)
+	// Delete 删除用户对客户端的授权同意记录
+	Delete(ctx context.Context, userID, clientID string) error
+}
+
+// oauthConsentRepository 授权同意记录仓储实现
+type oauthConsentRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthConsentRepository 创建授权同意记录仓储实例
+func NewOAuthConsentRepository(db *gorm.DB) OAuthConsentRepository {
+	return &oauthConsentRepository{db: db}
+}
+
+// Save 创建或更新授权同意记录
+func (r *oauthConsentRepository) Save(ctx context.Context, consent *model.OAuthConsent) error {
+	return r.db.WithContext(ctx).Save(consent).Error
+}
+
+// Get 获取用户对客户端的授权同意记录
+func (r *oauthConsentRepository) Get(ctx context.Context, userID, clientID string) (*model.OAuthConsent, error) {
+	var consent model.OAuthConsent
+	err := r.db.WithContext(ctx).Where("user_id = ? AND client_id = ?", userID, clientID).First(&consent).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// ListByUserID 获取用户的所有授权同意记录
+func (r *oauthConsentRepository) ListByUserID(ctx context.Context, userID string) ([]*model.OAuthConsent, error) {
+	var consents []*model.OAuthConsent
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Find(&consents).Error
+	return consents, err
+}
+
+// Delete 删除用户对客户端的授权同意记录
+func (r *oauthConsentRepository) Delete(ctx context.Context, userID, clientID string) error {
+	return r.db.WithContext(ctx).Delete(&model.OAuthConsent{}, "user_id = ? AND client_id = ?", userID, clientID).Error
+}
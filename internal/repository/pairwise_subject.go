@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"lauth/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PairwiseSubjectRepository 成对主体标识仓储接口
+type PairwiseSubjectRepository interface {
+	// Save 保存成对主体标识，已存在时忽略
+	Save(ctx context.Context, subject *model.PairwiseSubject) error
+	// GetUserID 根据扇区标识与成对sub获取用户ID，不存在时返回空字符串
+	GetUserID(ctx context.Context, sectorIdentifier, subject string) (string, error)
+	// GetUserIDBySubject 根据成对sub获取用户ID，不存在时返回空字符串
+	GetUserIDBySubject(ctx context.Context, subject string) (string, error)
+}
+
+// pairwiseSubjectRepository 成对主体标识仓储实现
+type pairwiseSubjectRepository struct {
+	db *gorm.DB
+}
+
+// NewPairwiseSubjectRepository 创建成对主体标识仓储实例
+func NewPairwiseSubjectRepository(db *gorm.DB) PairwiseSubjectRepository {
+	return &pairwiseSubjectRepository{db: db}
+}
+
+// Save 保存成对主体标识
+func (r *pairwiseSubjectRepository) Save(ctx context.Context, subject *model.PairwiseSubject) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(subject).Error
+}
+
+// GetUserID 根据扇区标识与成对sub获取用户ID
+func (r *pairwiseSubjectRepository) GetUserID(ctx context.Context, sectorIdentifier, subject string) (string, error) {
+	var record model.PairwiseSubject
+	err := r.db.WithContext(ctx).
+		Where("sector_identifier = ? AND subject = ?", sectorIdentifier, subject).
+		First(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return record.UserID, nil
+}
+
+// GetUserIDBySubject 根据成对sub获取用户ID
+// 成对sub是扇区标识与用户ID的HMAC，不同扇区不会重复，令牌中只有sub时可直接按sub查询
+func (r *pairwiseSubjectRepository) GetUserIDBySubject(ctx context.Context, subject string) (string, error) {
+	var record model.PairwiseSubject
+	err := r.db.WithContext(ctx).
+		Where("subject = ?", subject).
+		First(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return record.UserID, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"lauth/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ProtectedResourceRepository 受保护资源仓储接口
+type ProtectedResourceRepository interface {
+	// Create 登记受保护资源
+	Create(ctx context.Context, resource *model.ProtectedResource) error
+	// GetByIdentifier 根据资源标识获取应用登记的受保护资源
+	GetByIdentifier(ctx context.Context, appID, identifier string) (*model.ProtectedResource, error)
+	// GetByID 获取应用登记的受保护资源
+	GetByID(ctx context.Context, appID, id string) (*model.ProtectedResource, error)
+	// ListByAppID 获取应用登记的所有受保护资源
+	ListByAppID(ctx context.Context, appID string) ([]*model.ProtectedResource, error)
+	// Delete 删除应用登记的受保护资源
+	Delete(ctx context.Context, appID, id string) error
+}
+
+// protectedResourceRepository 受保护资源仓储实现
+type protectedResourceRepository struct {
+	db *gorm.DB
+}
+
+// NewProtectedResourceRepository 创建受保护资源仓储实例
+func NewProtectedResourceRepository(db *gorm.DB) ProtectedResourceRepository {
+	return &protectedResourceRepository{db: db}
+}
+
+// Create 登记受保护资源
+func (r *protectedResourceRepository) Create(ctx context.Context, resource *model.ProtectedResource) error {
+	return r.db.WithContext(ctx).Create(resource).Error
+}
+
+// GetByIdentifier 根据资源标识获取应用登记的受保护资源
+func (r *protectedResourceRepository) GetByIdentifier(ctx context.Context, appID, identifier string) (*model.ProtectedResource, error) {
+	return r.first(ctx, "app_id = ? AND identifier = ?", appID, identifier)
+}
+
+// GetByID 获取应用登记的受保护资源
+func (r *protectedResourceRepository) GetByID(ctx context.Context, appID, id string) (*model.ProtectedResource, error) {
+	return r.first(ctx, "app_id = ? AND id = ?", appID, id)
+}
+
+// ListByAppID 获取应用登记的所有受保护资源
+func (r *protectedResourceRepository) ListByAppID(ctx context.Context, appID string) ([]*model.ProtectedResource, error) {
+	var resources []*model.ProtectedResource
+	err := r.db.WithContext(ctx).Where("app_id = ?", appID).Order("identifier").Find(&resources).Error
+	return resources, err
+}
+
+// Delete 删除应用登记的受保护资源
+func (r *protectedResourceRepository) Delete(ctx context.Context, appID, id string) error {
+	return r.db.WithContext(ctx).Delete(&model.ProtectedResource{}, "app_id = ? AND id = ?", appID, id).Error
+}
+
+// first 按条件获取一条记录，不存在时返回nil
+func (r *protectedResourceRepository) first(ctx context.Context, query string, args ...interface{}) (*model.ProtectedResource, error) {
+	var resource model.ProtectedResource
+	err := r.db.WithContext(ctx).Where(query, args...).First(&resource).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &resource, nil
+}
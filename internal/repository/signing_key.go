@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"lauth/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// SigningKeyRepository 签名密钥仓储接口
+type SigningKeyRepository interface {
+	// ListActive 获取所有未过期的签名密钥
+	ListActive(ctx context.Context) ([]*model.SigningKey, error)
+	// SaveAll 在同一事务中保存多个签名密钥，保证轮换时状态一致
+	SaveAll(ctx context.Context, keys []*model.SigningKey) error
+	// DeleteExpired 删除已过期的退役密钥
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// signingKeyRepository 签名密钥仓储实现
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewSigningKeyRepository 创建签名密钥仓储实例
+func NewSigningKeyRepository(db *gorm.DB) SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+// ListActive 获取所有未过期的签名密钥
+func (r *signingKeyRepository) ListActive(ctx context.Context) ([]*model.SigningKey, error) {
+	var keys []*model.SigningKey
+	err := r.db.WithContext(ctx).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// SaveAll 在同一事务中保存多个签名密钥
+func (r *signingKeyRepository) SaveAll(ctx context.Context, keys []*model.SigningKey) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, key := range keys {
+			if err := tx.Save(key).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteExpired 删除已过期的退役密钥
+func (r *signingKeyRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	return r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", model.SigningKeyRetired, now).
+		Delete(&model.SigningKey{}).Error
+}
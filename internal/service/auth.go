@@ -25,6 +25,9 @@ type AuthService interface {
 	// Login 用户登录
 	Login(ctx context.Context, appID string, req *model.LoginRequest) (*model.ExtendedLoginResponse, error)
 
+	// ResumeLogin 插件验证完成后继续登录，sessionID为Login返回的验证会话，必须由调用方保存在服务端
+	ResumeLogin(ctx context.Context, appID, sessionID string, req *model.LoginRequest) (*model.ExtendedLoginResponse, error)
+
 	// RefreshToken 刷新访问令牌
 	RefreshToken(ctx context.Context, refreshToken string) (*model.ExtendedLoginResponse, error)
 
@@ -32,10 +35,12 @@ type AuthService interface {
 	Logout(ctx context.Context, accessToken string) error
 
 	// ValidateTokenAndGetUser 验证Token并获取用户信息（快速接口）
-	ValidateTokenAndGetUser(ctx context.Context, token string) (*model.TokenUserInfo, error)
+	// resource为调用方的资源标识(RFC 8707)，受众不包含该资源的令牌无效，为空时表示本服务；
+	// dpop为令牌以DPoP方案携带时的证明，绑定DPoP公钥的令牌必须提供
+	ValidateTokenAndGetUser(ctx context.Context, token, resource string, dpop *model.DPoPPresentation) (*model.TokenUserInfo, error)
 
 	// ValidateTokenAndRuleWithUser 组合验证令牌和规则并返回用户信息
-	ValidateTokenAndRuleWithUser(ctx context.Context, token string, data map[string]interface{}) (*ValidateTokenAndRuleResponse, error)
+	ValidateTokenAndRuleWithUser(ctx context.Context, token, resource string, dpop *model.DPoPPresentation, data map[string]interface{}) (*ValidateTokenAndRuleResponse, error)
 }
 
 // authService 认证服务实现
@@ -55,12 +60,14 @@ func NewAuthService(
 	profileSvc ProfileService,
 	locationSvc LoginLocationService,
 	superAdminSvc SuperAdminService,
+	logoutSvc LogoutService,
+	dpopService DPoPService,
 	db *gorm.DB,
 ) AuthService {
 	// 创建子服务实例
 	accountService := newAuthAccountService(userRepo, appRepo, tokenService, verificationSvc, profileSvc, locationSvc, superAdminSvc, db)
-	tokenSvc := newAuthTokenService(userRepo, tokenService)
-	validationService := newAuthValidationService(userRepo, tokenService, ruleService)
+	tokenSvc := newAuthTokenService(userRepo, tokenService, logoutSvc)
+	validationService := newAuthValidationService(userRepo, tokenService, ruleService, dpopService)
 
 	return &authService{
 		accountService:    accountService,
@@ -79,6 +86,11 @@ func (s *authService) Login(ctx context.Context, appID string, req *model.LoginR
 	return s.accountService.Login(ctx, appID, req)
 }
 
+// ResumeLogin 插件验证完成后继续登录
+func (s *authService) ResumeLogin(ctx context.Context, appID, sessionID string, req *model.LoginRequest) (*model.ExtendedLoginResponse, error) {
+	return s.accountService.ResumeLogin(ctx, appID, sessionID, req)
+}
+
 // RefreshToken 刷新访问令牌
 func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*model.ExtendedLoginResponse, error) {
 	return s.tokenService.RefreshToken(ctx, refreshToken)
@@ -90,11 +102,11 @@ func (s *authService) Logout(ctx context.Context, accessToken string) error {
 }
 
 // ValidateTokenAndGetUser 验证Token并获取用户信息（快速接口）
-func (s *authService) ValidateTokenAndGetUser(ctx context.Context, token string) (*model.TokenUserInfo, error) {
-	return s.validationService.ValidateTokenAndGetUser(ctx, token)
+func (s *authService) ValidateTokenAndGetUser(ctx context.Context, token, resource string, dpop *model.DPoPPresentation) (*model.TokenUserInfo, error) {
+	return s.validationService.ValidateTokenAndGetUser(ctx, token, resource, dpop)
 }
 
 // ValidateTokenAndRuleWithUser 组合验证令牌和规则并返回用户信息
-func (s *authService) ValidateTokenAndRuleWithUser(ctx context.Context, token string, data map[string]interface{}) (*ValidateTokenAndRuleResponse, error) {
-	return s.validationService.ValidateTokenAndRuleWithUser(ctx, token, data)
+func (s *authService) ValidateTokenAndRuleWithUser(ctx context.Context, token, resource string, dpop *model.DPoPPresentation, data map[string]interface{}) (*ValidateTokenAndRuleResponse, error) {
+	return s.validationService.ValidateTokenAndRuleWithUser(ctx, token, resource, dpop, data)
 }
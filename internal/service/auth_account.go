@@ -57,6 +57,7 @@ type verificationContext struct {
 	deviceID   string
 	deviceType string
 	userAgent  string
+	acrValues  string
 }
 
 // buildVerificationContext 构建验证上下文
@@ -74,6 +75,7 @@ func (s *authAccountService) buildVerificationContext(appID, userID, action stri
 		ctx.deviceID = r.DeviceID
 		ctx.deviceType = r.DeviceType
 		ctx.userAgent = r.UserAgent
+		ctx.acrValues = r.ACRValues
 	case *model.CreateUserRequest:
 		ctx.clientIP = r.ClientIP
 		ctx.deviceID = r.DeviceID
@@ -93,6 +95,9 @@ func (s *authAccountService) handleVerification(ctx context.Context, vCtx *verif
 		"device_type": vCtx.deviceType,
 		"user_agent":  vCtx.userAgent,
 	}
+	if vCtx.acrValues != "" {
+		contextMap["acr_values"] = vCtx.acrValues
+	}
 
 	// 创建验证会话
 	session, err := s.verificationSvc.CreateSession(ctx, vCtx.appID, vCtx.userID, vCtx.action, contextMap)
@@ -317,6 +322,14 @@ func (s *authAccountService) Login(ctx context.Context, appID string, req *model
 	log.Printf("[DEBUG] 是否跳过验证: %v (IsSuperAdmin=%v, IsFirstLogin=%v)",
 		skipVerification, user.IsSuperAdmin, user.IsFirstLogin)
 
+	// 跳过验证的登录只能达到单因素认证强度，授权请求要求多因素认证时无法满足
+	if skipVerification && requiredACRLevel(req.ACRValues) >= acrLevels[model.ACRMultiFactor] {
+		log.Printf("[ERROR] 首次登录的超级管理员无法满足acr_values=%q", req.ACRValues)
+		return nil, ErrUnmetAuthenticationRequirements
+	}
+
+	// 记录本次登录完成的认证方式，写入令牌的amr与acr
+	amr := []string{model.AMRPassword}
 	if !skipVerification {
 		vCtx := s.buildVerificationContext(appID, user.ID, "login", req)
 		session, plugins, verifyStatus, err := s.handleVerification(ctx, vCtx)
@@ -330,18 +343,62 @@ func (s *authAccountService) Login(ctx context.Context, appID string, req *model
 			log.Printf("[DEBUG] 需要额外验证，插件数量: %d", len(plugins))
 			return s.buildUserResponse(user, nil, plugins, verifyStatus, session.ID), ErrPluginRequired
 		}
+		amr = authenticationMethods(verifyStatus.Plugins)
+	}
+
+	return s.completeLogin(ctx, appID, user, req, amr, !skipVerification)
+}
+
+// ResumeLogin 插件验证完成后继续登录
+// sessionID必须是本次登录密码验证通过后返回的验证会话，由调用方保存在服务端，不能取自客户端请求
+func (s *authAccountService) ResumeLogin(ctx context.Context, appID, sessionID string, req *model.LoginRequest) (*model.ExtendedLoginResponse, error) {
+	session, err := s.verificationSvc.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.AppID != appID || session.Action != "login" || session.UserID == nil {
+		log.Printf("[ERROR] 验证会话无效: %s", sessionID)
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := s.userRepo.GetByID(ctx, *session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.Status == model.UserStatusDisabled {
+		return nil, ErrUserDisabled
 	}
 
+	verifyStatus, err := s.verificationSvc.ValidatePluginStatusBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !verifyStatus.Completed {
+		return s.buildUserResponse(user, nil, verifyStatus.Plugins, verifyStatus, sessionID), ErrPluginRequired
+	}
+
+	return s.completeLogin(ctx, appID, user, req, authenticationMethods(verifyStatus.Plugins), true)
+}
+
+// completeLogin 验证完成后生成登录令牌，清理验证状态并记录登录位置
+func (s *authAccountService) completeLogin(ctx context.Context, appID string, user *model.User, req *model.LoginRequest, amr []string, clearVerification bool) (*model.ExtendedLoginResponse, error) {
 	// 验证完成，生成token
-	log.Printf("[DEBUG] 验证完成，正在生成token")
-	tokenPair, err := s.tokenService.GenerateTokenPair(ctx, user, "read")
+	log.Printf("[DEBUG] 验证完成，正在生成token, amr=%v", amr)
+	tokenPair, err := s.tokenService.GenerateTokenPairWithOptions(ctx, user, &model.TokenOptions{
+		Scope: "read",
+		ACR:   acrForAMR(amr),
+		AMR:   amr,
+	})
 	if err != nil {
 		log.Printf("[ERROR] 生成token失败: %v", err)
 		return nil, err
 	}
 
 	// 清理验证状态
-	if !skipVerification {
+	if clearVerification {
 		if err := s.verificationSvc.ClearVerification(ctx, appID, user.ID, "login"); err != nil {
 			log.Printf("Failed to clear verification: %v", err)
 		}
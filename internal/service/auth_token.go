@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"fmt"
 
 	"lauth/internal/model"
 	"lauth/internal/repository"
@@ -10,25 +9,28 @@ import (
 
 // authTokenService 令牌管理服务
 type authTokenService struct {
-	userRepo     repository.UserRepository
-	tokenService TokenService
+	userRepo      repository.UserRepository
+	tokenService  TokenService
+	logoutService LogoutService
 }
 
 // newAuthTokenService 创建令牌管理服务实例
 func newAuthTokenService(
 	userRepo repository.UserRepository,
 	tokenService TokenService,
+	logoutService LogoutService,
 ) *authTokenService {
 	return &authTokenService{
-		userRepo:     userRepo,
-		tokenService: tokenService,
+		userRepo:      userRepo,
+		tokenService:  tokenService,
+		logoutService: logoutService,
 	}
 }
 
 // RefreshToken 刷新访问令牌
 func (s *authTokenService) RefreshToken(ctx context.Context, refreshToken string) (*model.ExtendedLoginResponse, error) {
 	// 使用TokenService刷新令牌
-	tokenPair, err := s.tokenService.RefreshToken(ctx, refreshToken)
+	tokenPair, err := s.tokenService.RefreshToken(ctx, refreshToken, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -85,11 +87,18 @@ func (s *authTokenService) Logout(ctx context.Context, accessToken string) error
 		return err
 	}
 
-	// 获取并吊销关联的刷新令牌
-	refreshKey := fmt.Sprintf("refresh_token:%s", claims.UserID)
-	refreshToken, err := s.tokenService.(*tokenService).redis.Get(ctx, refreshKey)
-	if err == nil && refreshToken != "" {
-		_ = s.tokenService.RevokeToken(ctx, refreshToken, model.RefreshToken)
+	// 吊销同一令牌族的刷新令牌，其他设备的登录不受影响
+	if err := s.tokenService.RevokeTokenFamily(ctx, claims.FamilyID); err != nil {
+		return err
+	}
+
+	// 结束登录会话并通知在该会话中授权过的客户端
+	sessionID := claims.SessionID
+	if sessionID == "" {
+		sessionID = claims.FamilyID
+	}
+	if _, err := s.logoutService.EndSession(ctx, claims.UserID, sessionID); err != nil {
+		return err
 	}
 
 	return nil
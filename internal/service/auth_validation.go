@@ -23,6 +23,7 @@ type authValidationService struct {
 	userRepo     repository.UserRepository
 	tokenService TokenService
 	ruleService  RuleService
+	dpopService  DPoPService
 }
 
 // newAuthValidationService 创建验证服务实例
@@ -30,38 +31,58 @@ func newAuthValidationService(
 	userRepo repository.UserRepository,
 	tokenService TokenService,
 	ruleService RuleService,
+	dpopService DPoPService,
 ) *authValidationService {
 	return &authValidationService{
 		userRepo:     userRepo,
 		tokenService: tokenService,
 		ruleService:  ruleService,
+		dpopService:  dpopService,
 	}
 }
 
 // ValidateTokenAndGetUser 验证Token并获取用户信息（快速接口）
-func (s *authValidationService) ValidateTokenAndGetUser(ctx context.Context, token string) (*model.TokenUserInfo, error) {
+// 令牌限定了受众时必须包含调用方的资源标识，绑定DPoP公钥的令牌必须附带匹配的证明
+func (s *authValidationService) ValidateTokenAndGetUser(ctx context.Context, token, resource string, dpop *model.DPoPPresentation) (*model.TokenUserInfo, error) {
 	// 验证Token
-	claims, err := s.tokenService.ValidateToken(ctx, token, model.AccessToken)
+	claims, err := s.tokenService.ValidateTokenForAudience(ctx, token, model.AccessToken, resource)
+	if err == ErrInvalidToken {
+		// 非用户令牌时再尝试按客户端服务令牌验证
+		if clientClaims, clientErr := s.tokenService.ValidateTokenForAudience(ctx, token, model.ClientAccessToken, resource); clientErr != ErrInvalidToken {
+			claims, err = clientClaims, clientErr
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
+	if err := s.dpopService.VerifyTokenBinding(ctx, token, claims, dpop); err != nil {
+		return nil, err
+	}
 
 	// 构造快速响应
 	return &model.TokenUserInfo{
-		UserID:   claims.UserID,
-		AppID:    claims.AppID,
-		Username: claims.Username,
+		UserID:       claims.UserID,
+		AppID:        claims.AppID,
+		Username:     claims.Username,
+		ClientID:     claims.ClientID,
+		TokenType:    claims.Type,
+		Confirmation: claims.Confirmation,
 	}, nil
 }
 
 // ValidateTokenAndRuleWithUser 组合验证令牌和规则并返回用户信息
-func (s *authValidationService) ValidateTokenAndRuleWithUser(ctx context.Context, token string, data map[string]interface{}) (*ValidateTokenAndRuleResponse, error) {
+func (s *authValidationService) ValidateTokenAndRuleWithUser(ctx context.Context, token, resource string, dpop *model.DPoPPresentation, data map[string]interface{}) (*ValidateTokenAndRuleResponse, error) {
 	// 先验证令牌并获取用户信息
-	userInfo, err := s.ValidateTokenAndGetUser(ctx, token)
+	userInfo, err := s.ValidateTokenAndGetUser(ctx, token, resource, dpop)
 	if err != nil {
 		return nil, err
 	}
 
+	// 规则验证需要用户主体，服务令牌不适用
+	if userInfo.TokenType == model.ClientAccessToken {
+		return nil, ErrInvalidToken
+	}
+
 	// 将 token 中的用户信息添加到验证数据中
 	data["token_user_id"] = userInfo.UserID
 	data["token_app_id"] = userInfo.AppID
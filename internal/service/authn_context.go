@@ -0,0 +1,65 @@
+package service
+
+import (
+	"strings"
+
+	"lauth/internal/model"
+)
+
+// pluginAMR 验证插件完成后对应的认证方式引用值，未列出的插件不计入amr
+var pluginAMR = map[string]string{
+	"totp":         model.AMROTP,
+	"email_verify": model.AMREmail,
+}
+
+// acrLevels 支持的认证上下文类及其认证强度
+var acrLevels = map[string]int{
+	model.ACRSingleFactor: 1,
+	model.ACRMultiFactor:  2,
+}
+
+// authenticationMethods 根据验证会话中已完成的插件计算amr
+// 登录总是经过密码认证，密码之外再完成任一插件即为多因素认证
+func authenticationMethods(plugins []model.PluginRequirement) []string {
+	amr := []string{model.AMRPassword}
+	for _, plugin := range plugins {
+		method, ok := pluginAMR[plugin.Name]
+		if !ok || plugin.Status != model.PluginStatusCompleted || containsString(amr, method) {
+			continue
+		}
+		amr = append(amr, method)
+	}
+	if len(amr) > 1 {
+		amr = append(amr, model.AMRMFA)
+	}
+	return amr
+}
+
+// acrForAMR 根据amr确定认证上下文类
+func acrForAMR(amr []string) string {
+	if containsString(amr, model.AMRMFA) {
+		return model.ACRMultiFactor
+	}
+	return model.ACRSingleFactor
+}
+
+// requiredACRLevel 计算acr_values要求的认证强度
+// acr_values按偏好排列，满足其中任一值即可，因此取最低的强度；不支持的值忽略
+func requiredACRLevel(acrValues string) int {
+	required := 0
+	for _, value := range strings.Fields(acrValues) {
+		level, ok := acrLevels[value]
+		if !ok {
+			continue
+		}
+		if required == 0 || level < required {
+			required = level
+		}
+	}
+	return required
+}
+
+// acrSatisfies 判断登录会话的认证上下文类是否满足acr_values
+func acrSatisfies(acr, acrValues string) bool {
+	return acrLevels[acr] >= requiredACRLevel(acrValues)
+}
@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,138 +16,292 @@ import (
 
 // AuthorizationService 授权服务接口
 type AuthorizationService interface {
-	// Authorize 处理授权请求
-	Authorize(ctx context.Context, userID string, req *model.AuthorizationRequest) (string, error)
+	// Authorize 处理授权请求，authCtx为nil表示用户尚未登录
+	Authorize(ctx context.Context, authCtx *model.AuthContext, req *model.AuthorizationRequest) (*model.AuthorizationResult, error)
 	// IssueToken 颁发令牌
 	IssueToken(ctx context.Context, req *model.TokenRequest) (*model.TokenResponse, error)
+	// IntrospectToken 内省令牌(RFC 7662)
+	IntrospectToken(ctx context.Context, req *model.IntrospectionRequest) (*model.IntrospectionResponse, error)
+	// RevokeToken 吊销令牌(RFC 7009)
+	RevokeToken(ctx context.Context, req *model.RevocationRequest) error
+	// PushAuthorizationRequest 处理推送授权请求(RFC 9126)
+	PushAuthorizationRequest(ctx context.Context, req *model.PushedAuthorizationRequest) (*model.PushedAuthorizationResponse, error)
+	// DeviceAuthorization 处理设备授权请求(RFC 8628)
+	DeviceAuthorization(ctx context.Context, req *model.DeviceAuthorizationRequest) (*model.DeviceAuthorizationResponse, error)
+	// GetConsentPrompt 获取需要用户确认的授权同意信息
+	GetConsentPrompt(ctx context.Context, authCtx *model.AuthContext, req *model.AuthorizationRequest) (*model.ConsentPrompt, error)
+	// Consent 处理用户的授权同意决定，同意时保存授权记录并按响应类型颁发授权码或令牌
+	Consent(ctx context.Context, authCtx *model.AuthContext, req *model.ConsentRequest) (*model.AuthorizationResult, error)
+	// Reject 用户交互无法完成时终止授权请求，将错误码返回给客户端
+	Reject(ctx context.Context, req *model.AuthorizationRequest, errorCode string) (*model.AuthorizationResult, error)
+	// ListConsents 获取用户已授权的客户端列表
+	ListConsents(ctx context.Context, userID string) ([]*model.ConsentResponse, error)
+	// RevokeConsent 撤销用户对客户端的授权，并吊销该客户端为用户持有的令牌
+	RevokeConsent(ctx context.Context, userID, clientID string) error
 }
 
 // authorizationService 授权服务实现
 type authorizationService struct {
-	clientRepo   repository.OAuthClientRepository
-	secretRepo   repository.OAuthClientSecretRepository
-	codeRepo     repository.AuthorizationCodeRepository
-	userRepo     repository.UserRepository
-	tokenService TokenService
-	oidcService  OIDCService
+	clientRepo    repository.OAuthClientRepository
+	userRepo      repository.UserRepository
+	consentRepo   repository.OAuthConsentRepository
+	tokenService  TokenService
+	codeService   AuthorizationCodeService
+	oidcService   OIDCService
+	deviceService DeviceAuthorizationService
+	parService    PushedAuthorizationService
+
+	clientKeyService    ClientKeyService
+	clientAuthenticator ClientAuthenticator
+	detailService       AuthorizationDetailService
+	dpopService         DPoPService
+	resourceService     ProtectedResourceService
 }
 
 // NewAuthorizationService 创建授权服务实例
 func NewAuthorizationService(
 	clientRepo repository.OAuthClientRepository,
-	secretRepo repository.OAuthClientSecretRepository,
-	codeRepo repository.AuthorizationCodeRepository,
 	userRepo repository.UserRepository,
+	consentRepo repository.OAuthConsentRepository,
 	tokenService TokenService,
+	codeService AuthorizationCodeService,
 	oidcService OIDCService,
+	deviceService DeviceAuthorizationService,
+	parService PushedAuthorizationService,
+	clientKeyService ClientKeyService,
+	clientAuthenticator ClientAuthenticator,
+	detailService AuthorizationDetailService,
+	dpopService DPoPService,
+	resourceService ProtectedResourceService,
 ) AuthorizationService {
 	return &authorizationService{
-		clientRepo:   clientRepo,
-		secretRepo:   secretRepo,
-		codeRepo:     codeRepo,
-		userRepo:     userRepo,
-		tokenService: tokenService,
-		oidcService:  oidcService,
+		clientRepo:    clientRepo,
+		userRepo:      userRepo,
+		consentRepo:   consentRepo,
+		tokenService:  tokenService,
+		codeService:   codeService,
+		oidcService:   oidcService,
+		deviceService: deviceService,
+		parService:    parService,
+
+		clientKeyService:    clientKeyService,
+		clientAuthenticator: clientAuthenticator,
+		detailService:       detailService,
+		dpopService:         dpopService,
+		resourceService:     resourceService,
 	}
 }
 
 // Authorize 处理授权请求
-func (s *authorizationService) Authorize(ctx context.Context, userID string, req *model.AuthorizationRequest) (string, error) {
+func (s *authorizationService) Authorize(ctx context.Context, authCtx *model.AuthContext, req *model.AuthorizationRequest) (*model.AuthorizationResult, error) {
 	log.Printf("Processing authorization request for client_id: %s", req.ClientID)
 
+	if err := s.resolveAuthorizationRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	client, err := s.validateAuthorizationRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPushedAuthorization(client, req); err != nil {
+		return nil, err
+	}
+
+	// prompt=none时不能与用户交互，需要登录或确认时直接将错误返回给客户端
+	silent := hasPrompt(req.Prompt, model.PromptNone)
+
+	// 6. 检查用户的登录会话是否满足prompt、max_age与登录提示
+	if err := s.checkAuthentication(ctx, authCtx, client, req); err != nil {
+		if err == ErrLoginRequired && silent {
+			return buildErrorResult(req, model.ErrorLoginRequired)
+		}
+		return nil, err
+	}
+
+	// 7. 检查用户是否已同意授予所请求的权限范围
+	consentRequired, err := s.isConsentRequired(ctx, authCtx.UserID, req)
+	if err != nil {
+		return nil, err
+	}
+	if consentRequired {
+		log.Printf("User %s has not consented to scope %q for client %s", authCtx.UserID, req.Scope, req.ClientID)
+		if silent {
+			return buildErrorResult(req, model.ErrorConsentRequired)
+		}
+		return nil, ErrConsentRequired
+	}
+
+	return s.issueAuthorizationResponse(ctx, authCtx, client, req)
+}
+
+// validateAuthorizationRequest 验证授权请求的客户端、重定向URI、权限范围与PKCE参数
+func (s *authorizationService) validateAuthorizationRequest(ctx context.Context, req *model.AuthorizationRequest) (*model.OAuthClient, error) {
 	// 1. 获取并验证客户端
 	client, err := s.clientRepo.GetByClientID(ctx, req.ClientID)
 	if err != nil {
 		log.Printf("Error getting client: %v", err)
-		return "", err
+		return nil, err
 	}
 	if client == nil || !client.Status {
 		log.Printf("Invalid or inactive client: %s", req.ClientID)
-		return "", ErrInvalidClient
+		return nil, ErrInvalidClient
 	}
 
-	// 2. 验证授权类型
-	if !s.containsGrantType(client.GrantTypes, string(model.AuthorizationCodeGrant)) {
-		log.Printf("Unsupported grant type for client %s", req.ClientID)
-		return "", ErrUnsupportedGrantType
+	if req.ResponseType == "" || req.RedirectURI == "" || req.Scope == "" || req.State == "" {
+		log.Printf("Missing required authorization parameters for client %s", req.ClientID)
+		return nil, ErrInvalidRequest
+	}
+
+	// 2. 验证响应类型与客户端的授权类型
+	if err := validateResponseType(client, req); err != nil {
+		return nil, err
 	}
 
 	// 3. 验证重定向URI
-	if !s.validateRedirectURI(client.RedirectURIs, req.RedirectURI) {
+	if !matchRedirectURI(client, req.RedirectURI) {
 		log.Printf("Invalid redirect URI: %s", req.RedirectURI)
-		return "", ErrInvalidRedirectURI
+		return nil, ErrInvalidRedirectURI
 	}
 
 	// 4. 验证权限范围
 	if !s.validateScope(client.Scopes, req.Scope) {
 		log.Printf("Invalid scope: %s", req.Scope)
-		return "", ErrInvalidScope
+		return nil, ErrInvalidScope
 	}
-
-	// 5. 生成授权码
-	authCode := &model.AuthorizationCode{
-		ClientID:    req.ClientID,
-		UserID:      userID,
-		RedirectURI: req.RedirectURI, // 存储原始URI，不进行编码
-		Scope:       req.Scope,
-		ExpiresAt:   time.Now().Add(10 * time.Minute), // 授权码10分钟有效
-		CreatedAt:   time.Now(),
+	if _, err := s.detailService.Parse(ctx, client.AppID, req.AuthorizationDetails); err != nil {
+		return nil, err
+	}
+	if err := s.resourceService.ValidateResources(ctx, client.AppID, req.Resource, req.Scope); err != nil {
+		return nil, err
 	}
 
-	if err := s.codeRepo.Create(ctx, authCode); err != nil {
-		log.Printf("Failed to create authorization code: %v", err)
-		return "", err
+	// 5. 验证PKCE参数，不颁发授权码的响应类型无需PKCE
+	if responseTypeHas(req.ResponseType, "code") {
+		if err := validateCodeChallenge(client, req); err != nil {
+			return nil, err
+		}
+	}
+	if err := validatePrompt(req.Prompt); err != nil {
+		return nil, err
 	}
+	if req.MaxAge != nil && *req.MaxAge < 0 {
+		log.Printf("Invalid max_age: %d", *req.MaxAge)
+		return nil, ErrInvalidRequest
+	}
+
+	return client, nil
+}
 
-	log.Printf("Created authorization code for client %s: %s", req.ClientID, authCode.Code)
+// issueAuthorizationResponse 按响应类型颁发授权码、访问令牌与ID Token，并构建授权响应
+func (s *authorizationService) issueAuthorizationResponse(ctx context.Context, authCtx *model.AuthContext, client *model.OAuthClient, req *model.AuthorizationRequest) (*model.AuthorizationResult, error) {
+	// 授权端点与同意端点都经由此处颁发凭据，颁发前再次确认认证强度，单因素会话不能取得要求多因素认证的授权码或令牌
+	if !acrSatisfies(authCtx.ACR, req.ACRValues) {
+		log.Printf("Refusing to issue authorization response for user %s: acr=%q does not satisfy acr_values=%q", authCtx.UserID, authCtx.ACR, req.ACRValues)
+		return nil, ErrLoginRequired
+	}
 
-	// 6. 构建重定向URL
-	redirectURL, err := url.Parse(req.RedirectURI)
+	// 授权详情已在验证授权请求时校验
+	details, err := parseAuthorizationDetails(req.AuthorizationDetails)
 	if err != nil {
-		log.Printf("Failed to parse redirect URI: %v", err)
-		return "", err
+		return nil, err
+	}
+
+	// 推送的授权请求只能完成一次授权，颁发任何凭据前先使request_uri失效，并发使用时只有一个请求成功
+	if req.RequestURI != "" {
+		if err := s.parService.Consume(ctx, req.RequestURI); err != nil {
+			return nil, err
+		}
+	}
+
+	params := url.Values{}
+	idOpts := &model.IDTokenOptions{
+		Nonce:     req.Nonce,
+		AuthTime:  authCtx.AuthTime,
+		ACR:       authCtx.ACR,
+		AMR:       authCtx.AMR,
+		SessionID: authCtx.SessionID,
+	}
+
+	if responseTypeHas(req.ResponseType, "code") {
+		// 8. 生成授权码，同时记录认证时间与nonce供ID Token使用
+		authCode := &model.AuthorizationCode{
+			ClientID:            req.ClientID,
+			UserID:              authCtx.UserID,
+			RedirectURI:         req.RedirectURI, // 存储原始URI，不进行编码
+			Scope:               req.Scope,
+			CodeChallenge:       req.CodeChallenge,
+			CodeChallengeMethod: req.CodeChallengeMethod,
+			AuthTime:            authCtx.AuthTime,
+			Nonce:               req.Nonce,
+			ACR:                 authCtx.ACR,
+			AMR:                 authCtx.AMR,
+			SessionID:           authCtx.SessionID,
+
+			AuthorizationDetails: details,
+			Resources:            req.Resource,
+		}
+
+		if err := s.codeService.Issue(ctx, authCode); err != nil {
+			log.Printf("Failed to create authorization code: %v", err)
+			return nil, err
+		}
+
+		log.Printf("Created authorization code for client %s", req.ClientID)
+		params.Set("code", authCode.Code)
+		idOpts.Code = authCode.Code
 	}
 
-	query := redirectURL.Query()
-	query.Set("code", authCode.Code) // 授权码已经是base64编码的，不需要额外编码
-	if req.State != "" {
-		query.Set("state", req.State)
+	// 授权端点直接颁发的访问令牌不附带刷新令牌
+	if responseTypeHas(req.ResponseType, "token") {
+		subject, err := s.pairwiseSubject(ctx, client, authCtx.UserID)
+		if err != nil {
+			return nil, err
+		}
+		accessToken, expiresIn, err := s.tokenService.GenerateAccessToken(ctx, &model.User{ID: authCtx.UserID, AppID: client.AppID}, &model.TokenOptions{
+			ClientID:  client.ClientID,
+			Scope:     req.Scope,
+			AuthTime:  authCtx.AuthTime,
+			ACR:       authCtx.ACR,
+			AMR:       authCtx.AMR,
+			SessionID: authCtx.SessionID,
+			Resources: req.Resource,
+			Subject:   subject,
+
+			AuthorizationDetails: details,
+		})
+		if err != nil {
+			log.Printf("Failed to generate access token: %v", err)
+			return nil, fmt.Errorf("failed to generate access token: %w", err)
+		}
+		params.Set("access_token", accessToken)
+		params.Set("token_type", "Bearer")
+		params.Set("expires_in", strconv.FormatInt(int64(expiresIn.Seconds()), 10))
+		params.Set("scope", req.Scope)
+		if details != nil {
+			params.Set("authorization_details", req.AuthorizationDetails)
+		}
+		idOpts.AccessToken = accessToken
 	}
 
 	// 如果响应类型包含 id_token，生成并返回 ID Token
-	if req.ResponseType == model.CodeIDTokenResponse {
+	if responseTypeHas(req.ResponseType, "id_token") {
 		// 获取用户信息
-		user, err := s.userRepo.GetByID(ctx, userID)
+		user, err := s.userRepo.GetByID(ctx, authCtx.UserID)
 		if err != nil {
 			log.Printf("Failed to get user info: %v", err)
-			return "", fmt.Errorf("failed to get user info: %w", err)
+			return nil, fmt.Errorf("failed to get user info: %w", err)
 		}
 
-		// 生成 ID Token
-		idToken, err := s.oidcService.GenerateIDToken(ctx, user, client, req.Nonce)
+		idToken, err := s.oidcService.GenerateIDToken(ctx, user, client, idOpts)
 		if err != nil {
 			log.Printf("Failed to generate ID token: %v", err)
-			return "", fmt.Errorf("failed to generate ID token: %w", err)
+			return nil, fmt.Errorf("failed to generate ID token: %w", err)
 		}
-		query.Set("id_token", idToken)
+		params.Set("id_token", idToken)
 	}
 
-	redirectURL.RawQuery = query.Encode()
-
-	finalURL := redirectURL.String()
-	log.Printf("Generated redirect URL: %s", finalURL)
-
-	return finalURL, nil
-}
-
-// validateRedirectURI 验证重定向URI
-func (s *authorizationService) validateRedirectURI(allowedURIs []string, redirectURI string) bool {
-	for _, uri := range allowedURIs {
-		if uri == redirectURI {
-			return true
-		}
-	}
-	return false
+	// 9. 按响应方式构建授权响应
+	return buildAuthorizationResult(req, params)
 }
 
 // validateScope 验证权限范围
@@ -183,33 +339,16 @@ func (s *authorizationService) IssueToken(ctx context.Context, req *model.TokenR
 		req.GrantType, req.ClientID, req.Code, req.RedirectURI)
 
 	// 验证客户端
-	client, err := s.clientRepo.GetByClientID(ctx, req.ClientID)
-	if err != nil {
-		log.Printf("Error getting client: %v", err)
-		return nil, fmt.Errorf("failed to get client: %w", err)
-	}
-	if client == nil {
-		log.Printf("Client not found with client_id: %s", req.ClientID)
-		return nil, ErrInvalidClient
-	}
-
-	// 打印客户端信息（注意不要打印密钥）
-	log.Printf("Found client: id=%s, name=%s, type=%s", client.ID, client.Name, client.Type)
-
-	// 验证客户端密钥
-	secret, err := s.secretRepo.ValidateSecret(ctx, req.ClientID, req.ClientSecret)
+	client, err := s.clientAuthenticator.Authenticate(ctx, &req.ClientAuthentication)
 	if err != nil {
-		log.Printf("Error validating client secret: %v", err)
-		return nil, fmt.Errorf("failed to validate client secret: %w", err)
-	}
-	if secret == nil {
-		log.Printf("Invalid client secret for client_id: %s", req.ClientID)
-		return nil, ErrInvalidClient
+		return nil, err
 	}
 
-	// 更新密钥最后使用时间
-	if err := s.secretRepo.UpdateLastUsedAt(ctx, secret.ID); err != nil {
-		log.Printf("Failed to update secret last used time: %v", err)
+	// 携带DPoP证明时颁发的令牌绑定到证明公钥(RFC 9449 5)
+	if req.DPoPProof != "" {
+		if req.DPoPJKT, err = s.dpopService.VerifyProof(ctx, req.DPoPProof, "POST", req.DPoPURL, ""); err != nil {
+			return nil, err
+		}
 	}
 
 	switch req.GrantType {
@@ -217,42 +356,18 @@ func (s *authorizationService) IssueToken(ctx context.Context, req *model.TokenR
 		return s.handleAuthorizationCodeGrant(ctx, req, client)
 	case model.GrantTypeRefreshToken:
 		return s.handleRefreshTokenGrant(ctx, req, client)
+	case model.GrantTypeClientCredentials:
+		return s.handleClientCredentialsGrant(ctx, req, client)
+	case model.GrantTypeDeviceCode:
+		return s.handleDeviceCodeGrant(ctx, req, client)
+	case model.GrantTypeTokenExchange:
+		return s.handleTokenExchangeGrant(ctx, req, client)
 	default:
 		log.Printf("Unsupported grant type: %s", req.GrantType)
 		return nil, ErrUnsupportedGrantType
 	}
 }
 
-// processAuthorizationCode 处理和验证授权码
-func (s *authorizationService) processAuthorizationCode(ctx context.Context, code string) (*model.AuthorizationCode, error) {
-	// URL解码授权码
-	decodedCode, err := url.QueryUnescape(code)
-	if err != nil {
-		log.Printf("Failed to URL decode authorization code: %v", err)
-		return nil, fmt.Errorf("failed to decode authorization code: %w", err)
-	}
-	log.Printf("URL decoded authorization code: %s", decodedCode)
-
-	// 如果授权码末尾没有=，尝试添加
-	if !strings.HasSuffix(decodedCode, "=") {
-		decodedCode = decodedCode + "="
-		log.Printf("Added padding to authorization code: %s", decodedCode)
-	}
-
-	// 验证授权码
-	authCode, err := s.codeRepo.GetByCode(ctx, decodedCode)
-	if err != nil {
-		log.Printf("Error getting authorization code: %v", err)
-		return nil, fmt.Errorf("failed to get authorization code: %w", err)
-	}
-	if authCode == nil {
-		log.Printf("Authorization code not found: %s", decodedCode)
-		return nil, ErrInvalidGrant
-	}
-
-	return authCode, nil
-}
-
 // validateAuthCodeRequest 验证授权码请求
 func (s *authorizationService) validateAuthCodeRequest(authCode *model.AuthorizationCode, req *model.TokenRequest, client *model.OAuthClient) error {
 	// 验证客户端ID
@@ -289,17 +404,88 @@ func (s *authorizationService) validateAuthCodeRequest(authCode *model.Authoriza
 		return ErrInvalidGrant
 	}
 
+	// 验证PKCE
+	if err := verifyCodeVerifier(authCode, client, req.CodeVerifier); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // generateTokenResponse 生成令牌响应
+// 令牌请求携带authorization_details时，只能申请授权码已批准的授权详情的子集(RFC 9396 6.1)
 func (s *authorizationService) generateTokenResponse(ctx context.Context, authCode *model.AuthorizationCode, client *model.OAuthClient, req *model.TokenRequest) (*model.TokenResponse, error) {
+	details := authCode.AuthorizationDetails
+	if req.AuthorizationDetails != "" {
+		requested, err := parseAuthorizationDetails(req.AuthorizationDetails)
+		if err != nil {
+			return nil, err
+		}
+		if !authorizationDetailsContain(authCode.AuthorizationDetails, requested) {
+			log.Printf("Requested authorization details exceed those approved for the code")
+			return nil, ErrInvalidAuthorizationDetails
+		}
+		details = requested
+	}
+
+	// 令牌请求的resource只能从授权请求指定的资源中选择(RFC 8707 2.2)
+	if !resourcesSubset(authCode.Resources, req.Resource) {
+		log.Printf("Requested resources %v exceed those authorized for the code %v", req.Resource, authCode.Resources)
+		return nil, ErrInvalidTarget
+	}
+
+	return s.issueUserTokens(ctx, client, authCode.UserID, &model.TokenOptions{
+		Scope:     authCode.Scope,
+		FamilyID:  authCode.FamilyID,
+		Resources: authCode.Resources,
+		Audience:  req.Resource,
+		DPoPJKT:   req.DPoPJKT,
+
+		AuthorizationDetails: details,
+	}, &model.IDTokenOptions{
+		Nonce:     authCode.Nonce,
+		AuthTime:  authCode.AuthTime,
+		ACR:       authCode.ACR,
+		AMR:       authCode.AMR,
+		SessionID: authCode.SessionID,
+	})
+}
+
+// pairwiseSubject 获取pairwise客户端看到的成对sub，颁发给该客户端的令牌以其代替用户ID；其他客户端返回空
+func (s *authorizationService) pairwiseSubject(ctx context.Context, client *model.OAuthClient, userID string) (string, error) {
+	if client.SubjectType != model.SubjectTypePairwise {
+		return "", nil
+	}
+	subject, err := s.oidcService.SubjectFor(ctx, client, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pairwise subject: %w", err)
+	}
+	return subject, nil
+}
+
+// issueUserTokens 为用户颁发访问令牌、刷新令牌，scope包含openid时同时颁发ID令牌
+// opts携带权限范围、授权详情、目标资源与DPoP绑定；idOpts携带用户认证时的auth_time、acr、nonce与会话ID，
+// 同时写入访问令牌以便刷新时保留
+func (s *authorizationService) issueUserTokens(ctx context.Context, client *model.OAuthClient, userID string, opts *model.TokenOptions, idOpts *model.IDTokenOptions) (*model.TokenResponse, error) {
+	scope := opts.Scope
+	opts.ClientID = client.ClientID
+	opts.AuthTime = idOpts.AuthTime
+	opts.ACR = idOpts.ACR
+	opts.AMR = idOpts.AMR
+	opts.SessionID = idOpts.SessionID
+
+	subject, err := s.pairwiseSubject(ctx, client, userID)
+	if err != nil {
+		return nil, err
+	}
+	opts.Subject = subject
+
 	// 生成访问令牌和刷新令牌
 	user := &model.User{
-		ID:    authCode.UserID,
+		ID:    userID,
 		AppID: client.AppID,
 	}
-	tokenPair, err := s.tokenService.GenerateTokenPair(ctx, user, authCode.Scope)
+	tokenPair, err := s.tokenService.GenerateTokenPairWithOptions(ctx, user, opts)
 	if err != nil {
 		log.Printf("Failed to generate token pair: %v", err)
 		return nil, fmt.Errorf("failed to generate token pair: %w", err)
@@ -307,24 +493,25 @@ func (s *authorizationService) generateTokenResponse(ctx context.Context, authCo
 
 	response := &model.TokenResponse{
 		AccessToken:  tokenPair.AccessToken,
-		TokenType:    "Bearer",
+		TokenType:    tokenTypeFor(opts.DPoPJKT),
 		ExpiresIn:    int64(tokenPair.AccessTokenExpireIn.Seconds()),
 		RefreshToken: tokenPair.RefreshToken,
-		Scope:        authCode.Scope,
+		Scope:        scope,
+
+		AuthorizationDetails: opts.AuthorizationDetails,
 	}
 
 	// 如果scope包含openid，生成ID Token
-	scopes := strings.Split(authCode.Scope, " ")
-	for _, scope := range scopes {
-		if scope == model.ScopeOpenID {
+	for _, requested := range strings.Split(scope, " ") {
+		if requested == model.ScopeOpenID {
 			// 获取完整的用户信息
-			user, err = s.userRepo.GetByID(ctx, authCode.UserID)
+			user, err = s.userRepo.GetByID(ctx, userID)
 			if err != nil {
 				log.Printf("Failed to get user info: %v", err)
 				return nil, fmt.Errorf("failed to get user info: %w", err)
 			}
 
-			idToken, err := s.oidcService.GenerateIDToken(ctx, user, client, req.Nonce)
+			idToken, err := s.oidcService.GenerateIDToken(ctx, user, client, idOpts)
 			if err != nil {
 				log.Printf("Failed to generate ID token: %v", err)
 				return nil, fmt.Errorf("failed to generate ID token: %w", err)
@@ -339,11 +526,8 @@ func (s *authorizationService) generateTokenResponse(ctx context.Context, authCo
 
 // handleAuthorizationCodeGrant 处理授权码授权类型
 func (s *authorizationService) handleAuthorizationCodeGrant(ctx context.Context, req *model.TokenRequest, client *model.OAuthClient) (*model.TokenResponse, error) {
-	// 打印原始授权码
-	log.Printf("Received authorization code: %s", req.Code)
-
-	// 处理和验证授权码
-	authCode, err := s.processAuthorizationCode(ctx, req.Code)
+	// 兑换授权码，授权码无论后续验证是否通过都已失效
+	authCode, err := s.codeService.Redeem(ctx, req.Code)
 	if err != nil {
 		return nil, err
 	}
@@ -354,17 +538,7 @@ func (s *authorizationService) handleAuthorizationCodeGrant(ctx context.Context,
 	}
 
 	// 生成令牌响应
-	response, err := s.generateTokenResponse(ctx, authCode, client, req)
-	if err != nil {
-		return nil, err
-	}
-
-	// 删除已使用的授权码
-	if err := s.codeRepo.Delete(ctx, authCode.ID); err != nil {
-		log.Printf("Failed to delete authorization code: %v", err)
-	}
-
-	return response, nil
+	return s.generateTokenResponse(ctx, authCode, client, req)
 }
 
 // handleRefreshTokenGrant 处理刷新令牌授权类型
@@ -384,10 +558,28 @@ func (s *authorizationService) handleRefreshTokenGrant(ctx context.Context, req
 		return nil, ErrInvalidGrant
 	}
 
+	// 通过OAuth授权颁发的刷新令牌只能由原客户端使用
+	if claims.ClientID != "" && claims.ClientID != client.ClientID {
+		log.Printf("Refresh token was issued to another client: token client_id=%s, client_id=%s", claims.ClientID, client.ClientID)
+		return nil, ErrInvalidGrant
+	}
+
+	// 绑定DPoP公钥的刷新令牌必须提供同一公钥签名的证明(RFC 9449 5)
+	if jkt := claims.DPoPJKT(); jkt != "" && jkt != req.DPoPJKT {
+		log.Printf("Refresh token is bound to a DPoP key but no matching proof was presented")
+		return nil, ErrInvalidDPoPProof
+	}
+
 	// 使用刷新令牌获取新的令牌对
-	tokenPair, err := s.tokenService.RefreshToken(ctx, req.RefreshToken)
+	tokenPair, err := s.tokenService.RefreshToken(ctx, req.RefreshToken, req.Resource)
 	if err != nil {
 		log.Printf("Failed to refresh token: %v", err)
+		if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrTokenExpired) || errors.Is(err, ErrTokenRevoked) {
+			return nil, ErrInvalidGrant
+		}
+		if err == ErrInvalidTarget {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
@@ -398,9 +590,80 @@ func (s *authorizationService) handleRefreshTokenGrant(ctx context.Context, req
 
 	return &model.TokenResponse{
 		AccessToken:  tokenPair.AccessToken,
-		TokenType:    "Bearer",
+		TokenType:    tokenTypeFor(claims.DPoPJKT()),
 		ExpiresIn:    int64(tokenPair.AccessTokenExpireIn.Seconds()),
 		RefreshToken: tokenPair.RefreshToken,
 		Scope:        scope,
+
+		AuthorizationDetails: claims.AuthorizationDetails,
+	}, nil
+}
+
+// handleClientCredentialsGrant 处理客户端凭证授权类型
+func (s *authorizationService) handleClientCredentialsGrant(ctx context.Context, req *model.TokenRequest, client *model.OAuthClient) (*model.TokenResponse, error) {
+	log.Printf("Processing client credentials grant for client_id: %s", req.ClientID)
+
+	// 只有完成认证的机密客户端才能获取服务令牌
+	if client.Type != model.Confidential || req.AuthMethod == model.TokenEndpointAuthNone {
+		log.Printf("Client credentials grant requires an authenticated confidential client: %s", req.ClientID)
+		return nil, ErrUnauthorizedClient
+	}
+	if !s.containsGrantType(client.GrantTypes, string(model.ClientCredentials)) {
+		log.Printf("Client %s is not allowed to use client credentials grant", req.ClientID)
+		return nil, ErrUnauthorizedClient
+	}
+
+	// 服务令牌没有用户主体，不能申请OIDC的openid权限
+	var scope string
+	if req.Scope == "" {
+		scopes := make([]string, 0, len(client.Scopes))
+		for _, allowed := range client.Scopes {
+			if allowed != model.ScopeOpenID {
+				scopes = append(scopes, allowed)
+			}
+		}
+		scope = strings.Join(scopes, " ")
+	} else {
+		if !s.validateScope(client.Scopes, req.Scope) || strings.Contains(" "+req.Scope+" ", " "+model.ScopeOpenID+" ") {
+			log.Printf("Invalid scope for client credentials grant: %s", req.Scope)
+			return nil, ErrInvalidScope
+		}
+		scope = req.Scope
+	}
+
+	// 客户端凭证授权没有用户批准，授权详情只能使用管理员为客户端开放的类型，目标资源只需符合应用的登记
+	details, err := s.detailService.Parse(ctx, client.AppID, req.AuthorizationDetails)
+	if err != nil {
+		return nil, err
+	}
+	for _, detail := range details {
+		if detailType := detail.Type(); !containsString(client.AuthorizationDetailsTypes, detailType) {
+			log.Printf("Client %s is not allowed to request authorization details of type %s", client.ClientID, detailType)
+			return nil, ErrInvalidAuthorizationDetails
+		}
+	}
+	if err := s.resourceService.ValidateResources(ctx, client.AppID, req.Resource, scope); err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresIn, err := s.tokenService.GenerateClientToken(ctx, client, &model.ClientTokenOptions{
+		Scope:    scope,
+		Audience: req.Resource,
+		DPoPJKT:  req.DPoPJKT,
+
+		AuthorizationDetails: details,
+	})
+	if err != nil {
+		log.Printf("Failed to generate client token: %v", err)
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   tokenTypeFor(req.DPoPJKT),
+		ExpiresIn:   int64(expiresIn.Seconds()),
+		Scope:       scope,
+
+		AuthorizationDetails: details,
 	}, nil
 }
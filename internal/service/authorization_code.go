@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"lauth/internal/model"
+	"lauth/pkg/redis"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// authorizationCodeExpiry 授权码有效期
+const authorizationCodeExpiry = 10 * time.Minute
+
+// AuthorizationCodeService 授权码服务接口
+// 授权码只能兑换一次，重复兑换说明授权码可能已泄露，需吊销此前由其颁发的令牌(RFC 9700 4.5)
+type AuthorizationCodeService interface {
+	// Issue 生成授权码并保存授权信息，同时为将要颁发的令牌分配令牌族
+	Issue(ctx context.Context, code *model.AuthorizationCode) error
+	// Redeem 兑换授权码，授权码取出后立即失效
+	// 授权码不存在或已被兑换时返回ErrInvalidGrant，已被兑换时同时吊销其令牌族
+	Redeem(ctx context.Context, code string) (*model.AuthorizationCode, error)
+}
+
+// authorizationCodeService 授权码服务实现
+type authorizationCodeService struct {
+	redis        *redis.Client
+	tokenService TokenService
+	// familyRetention 授权码对应令牌族的记录保留时间，需覆盖其颁发的刷新令牌的有效期
+	familyRetention time.Duration
+}
+
+// NewAuthorizationCodeService 创建授权码服务实例
+func NewAuthorizationCodeService(redisClient *redis.Client, tokenService TokenService, familyRetention time.Duration) AuthorizationCodeService {
+	return &authorizationCodeService{
+		redis:           redisClient,
+		tokenService:    tokenService,
+		familyRetention: familyRetention,
+	}
+}
+
+// Issue 生成授权码并保存授权信息
+func (s *authorizationCodeService) Issue(ctx context.Context, code *model.AuthorizationCode) error {
+	value, err := generateSecureToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate authorization code: %w", err)
+	}
+
+	now := time.Now()
+	code.Code = value
+	code.FamilyID = uuid.NewString()
+	code.CreatedAt = now
+	code.ExpiresAt = now.Add(authorizationCodeExpiry)
+
+	data, err := json.Marshal(code)
+	if err != nil {
+		return fmt.Errorf("failed to marshal authorization code: %w", err)
+	}
+
+	// 令牌族记录与授权码同时写入并保留更久，授权码兑换后再次出现时据此判断为重放
+	if err := s.redis.Set(ctx, s.familyKey(value), code.FamilyID, s.familyRetention); err != nil {
+		return fmt.Errorf("failed to store authorization code: %w", err)
+	}
+	if err := s.redis.Set(ctx, s.codeKey(value), string(data), authorizationCodeExpiry); err != nil {
+		return fmt.Errorf("failed to store authorization code: %w", err)
+	}
+	return nil
+}
+
+// Redeem 兑换授权码
+func (s *authorizationCodeService) Redeem(ctx context.Context, code string) (*model.AuthorizationCode, error) {
+	// GETDEL保证并发兑换时只有一个请求能取到授权码
+	data, err := s.redis.GetDel(ctx, s.codeKey(code)).Result()
+	if err == goredis.Nil {
+		return nil, s.handleReplay(ctx, code)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authorization code: %w", err)
+	}
+
+	var authCode model.AuthorizationCode
+	if err := json.Unmarshal([]byte(data), &authCode); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal authorization code: %w", err)
+	}
+	authCode.Code = code
+	return &authCode, nil
+}
+
+// handleReplay 处理无法取到的授权码
+// 令牌族记录仍在说明授权码曾被签发且已被兑换或过期，吊销该令牌族中已颁发的令牌
+func (s *authorizationCodeService) handleReplay(ctx context.Context, code string) error {
+	familyID, err := s.redis.Get(ctx, s.familyKey(code))
+	if err == goredis.Nil {
+		log.Printf("Authorization code not found")
+		return ErrInvalidGrant
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get authorization code: %w", err)
+	}
+
+	log.Printf("Authorization code reuse detected, revoking token family %s", familyID)
+	if err := s.tokenService.RevokeTokenFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return ErrInvalidGrant
+}
+
+// codeKey 构建授权码键，只保存授权码的摘要
+func (s *authorizationCodeService) codeKey(code string) string {
+	return fmt.Sprintf("auth_code:%s", hashToken(code))
+}
+
+// familyKey 构建授权码令牌族键
+func (s *authorizationCodeService) familyKey(code string) string {
+	return fmt.Sprintf("auth_code_family:%s", hashToken(code))
+}
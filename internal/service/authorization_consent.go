@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"lauth/internal/model"
+)
+
+// isConsentRequired 判断授权请求是否需要用户确认
+// prompt=consent时总是需要确认；否则仅当请求的权限范围或授权详情未被完全同意过时需要确认
+func (s *authorizationService) isConsentRequired(ctx context.Context, userID string, req *model.AuthorizationRequest) (bool, error) {
+	if hasPrompt(req.Prompt, model.PromptConsent) {
+		return true, nil
+	}
+
+	consent, err := s.consentRepo.Get(ctx, userID, req.ClientID)
+	if err != nil {
+		log.Printf("Failed to get consent: %v", err)
+		return false, err
+	}
+	if consent == nil {
+		return true, nil
+	}
+
+	details, err := parseAuthorizationDetails(req.AuthorizationDetails)
+	if err != nil {
+		return false, err
+	}
+	return !s.validateScope(consent.Scopes, req.Scope) || !authorizationDetailsContain(consent.AuthorizationDetails, details), nil
+}
+
+// GetConsentPrompt 获取需要用户确认的授权同意信息
+// 同意页面可能在登录很久之后才打开，展示前重新检查登录会话是否满足授权请求的认证要求
+func (s *authorizationService) GetConsentPrompt(ctx context.Context, authCtx *model.AuthContext, req *model.AuthorizationRequest) (*model.ConsentPrompt, error) {
+	client, err := s.clientRepo.GetByClientID(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil || !client.Status {
+		return nil, ErrInvalidClient
+	}
+	if err := s.checkAuthentication(ctx, authCtx, client, req); err != nil {
+		return nil, err
+	}
+	userID := authCtx.UserID
+
+	details, err := parseAuthorizationDetails(req.AuthorizationDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt := &model.ConsentPrompt{
+		ConsentRequired: true,
+		ClientID:        client.ClientID,
+		ClientName:      client.Name,
+		RequestedScopes: strings.Fields(req.Scope),
+		GrantedScopes:   []string{},
+
+		RequestedAuthorizationDetails: details,
+	}
+
+	consent, err := s.consentRepo.Get(ctx, userID, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if consent != nil {
+		prompt.GrantedScopes = consent.Scopes
+		prompt.GrantedAuthorizationDetails = consent.AuthorizationDetails
+	}
+
+	return prompt, nil
+}
+
+// Consent 处理用户的授权同意决定
+func (s *authorizationService) Consent(ctx context.Context, authCtx *model.AuthContext, req *model.ConsentRequest) (*model.AuthorizationResult, error) {
+	log.Printf("Processing consent decision for client_id: %s", req.ClientID)
+
+	if err := s.resolveAuthorizationRequest(ctx, &req.AuthorizationRequest); err != nil {
+		return nil, err
+	}
+	client, err := s.validateAuthorizationRequest(ctx, &req.AuthorizationRequest)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPushedAuthorization(client, &req.AuthorizationRequest); err != nil {
+		return nil, err
+	}
+	// 同意端点可以不经过授权端点直接调用，必须与授权端点一样检查prompt、max_age与登录提示
+	if err := s.checkAuthentication(ctx, authCtx, client, &req.AuthorizationRequest); err != nil {
+		return nil, err
+	}
+	userID := authCtx.UserID
+
+	// 用户拒绝时按RFC 6749 4.1.2.1将access_denied返回给客户端
+	if !req.Approve {
+		log.Printf("User %s denied consent for client %s", userID, req.ClientID)
+		return buildErrorResult(&req.AuthorizationRequest, model.ErrorAccessDenied)
+	}
+
+	// 合并之前已同意的权限范围与授权详情
+	details, err := parseAuthorizationDetails(req.AuthorizationDetails)
+	if err != nil {
+		return nil, err
+	}
+	consent, err := s.consentRepo.Get(ctx, userID, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if consent == nil {
+		consent = &model.OAuthConsent{
+			UserID:    userID,
+			ClientID:  client.ClientID,
+			AppID:     client.AppID,
+			CreatedAt: now,
+		}
+	}
+	for _, scope := range strings.Fields(req.Scope) {
+		if !s.validateScope(consent.Scopes, scope) {
+			consent.Scopes = append(consent.Scopes, scope)
+		}
+	}
+	consent.AuthorizationDetails = mergeAuthorizationDetails(consent.AuthorizationDetails, details)
+	consent.UpdatedAt = now
+
+	if err := s.consentRepo.Save(ctx, consent); err != nil {
+		log.Printf("Failed to save consent: %v", err)
+		return nil, err
+	}
+
+	return s.issueAuthorizationResponse(ctx, authCtx, client, &req.AuthorizationRequest)
+}
+
+// ListConsents 获取用户已授权的客户端列表
+func (s *authorizationService) ListConsents(ctx context.Context, userID string) ([]*model.ConsentResponse, error) {
+	consents, err := s.consentRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*model.ConsentResponse, 0, len(consents))
+	for _, consent := range consents {
+		resp := &model.ConsentResponse{
+			ClientID:  consent.ClientID,
+			Scopes:    consent.Scopes,
+			CreatedAt: consent.CreatedAt.Format(time.RFC3339),
+			UpdatedAt: consent.UpdatedAt.Format(time.RFC3339),
+
+			AuthorizationDetails: consent.AuthorizationDetails,
+		}
+		client, err := s.clientRepo.GetByClientID(ctx, consent.ClientID)
+		if err != nil {
+			return nil, err
+		}
+		if client != nil {
+			resp.ClientName = client.Name
+		}
+		responses = append(responses, resp)
+	}
+
+	return responses, nil
+}
+
+// RevokeConsent 撤销用户对客户端的授权
+func (s *authorizationService) RevokeConsent(ctx context.Context, userID, clientID string) error {
+	consent, err := s.consentRepo.Get(ctx, userID, clientID)
+	if err != nil {
+		return err
+	}
+	if consent == nil {
+		return ErrConsentNotFound
+	}
+
+	if err := s.consentRepo.Delete(ctx, userID, clientID); err != nil {
+		return err
+	}
+
+	// 撤销授权后客户端不能继续使用之前获得的令牌
+	if err := s.tokenService.RevokeClientTokens(ctx, userID, clientID); err != nil {
+		log.Printf("Failed to revoke tokens of client %s for user %s: %v", clientID, userID, err)
+		return err
+	}
+
+	log.Printf("User %s revoked consent for client %s", userID, clientID)
+	return nil
+}
+
+// hasPrompt 判断prompt参数(空格分隔)是否包含指定值
+func hasPrompt(prompt, value string) bool {
+	for _, p := range strings.Fields(prompt) {
+		if p == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Reject 用户交互无法完成时终止授权请求
+// 请求已在开始交互前解析，这里只需确认客户端与重定向URI仍然有效
+func (s *authorizationService) Reject(ctx context.Context, req *model.AuthorizationRequest, errorCode string) (*model.AuthorizationResult, error) {
+	if _, err := s.validateAuthorizationRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	log.Printf("Rejecting authorization request of client %s: %s", req.ClientID, errorCode)
+	return buildErrorResult(req, errorCode)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+
+	"lauth/internal/model"
+	"lauth/internal/repository"
+)
+
+var (
+	// ErrAuthorizationDetailTypeExists 授权详情类型已登记
+	ErrAuthorizationDetailTypeExists = errors.New("authorization detail type already exists")
+	// ErrAuthorizationDetailTypeNotFound 授权详情类型未登记
+	ErrAuthorizationDetailTypeNotFound = errors.New("authorization detail type not found")
+)
+
+// authorizationDetailStringFields RFC 9396 2.2定义的公共字段中取值为字符串的字段
+var authorizationDetailStringFields = []string{"identifier"}
+
+// authorizationDetailArrayFields RFC 9396 2.2定义的公共字段中取值为字符串数组的字段
+var authorizationDetailArrayFields = []string{"locations", "actions", "datatypes", "privileges"}
+
+// AuthorizationDetailService 授权详情服务接口(RFC 9396)
+type AuthorizationDetailService interface {
+	// CreateType 为应用登记授权详情类型
+	CreateType(ctx context.Context, appID string, req *model.CreateAuthorizationDetailTypeRequest) (*model.AuthorizationDetailType, error)
+	// ListTypes 获取应用登记的授权详情类型
+	ListTypes(ctx context.Context, appID string) ([]*model.AuthorizationDetailType, error)
+	// DeleteType 删除应用登记的授权详情类型
+	DeleteType(ctx context.Context, appID, detailType string) error
+
+	// Parse 解析authorization_details参数，并按应用登记的类型校验每一项
+	// 参数为空时返回nil
+	Parse(ctx context.Context, appID, raw string) ([]model.AuthorizationDetail, error)
+}
+
+// authorizationDetailService 授权详情服务实现
+type authorizationDetailService struct {
+	typeRepo repository.AuthorizationDetailTypeRepository
+}
+
+// NewAuthorizationDetailService 创建授权详情服务实例
+func NewAuthorizationDetailService(typeRepo repository.AuthorizationDetailTypeRepository) AuthorizationDetailService {
+	return &authorizationDetailService{
+		typeRepo: typeRepo,
+	}
+}
+
+// CreateType 为应用登记授权详情类型
+func (s *authorizationDetailService) CreateType(ctx context.Context, appID string, req *model.CreateAuthorizationDetailTypeRequest) (*model.AuthorizationDetailType, error) {
+	existing, err := s.typeRepo.Get(ctx, appID, req.Type)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAuthorizationDetailTypeExists
+	}
+
+	detailType := &model.AuthorizationDetailType{
+		AppID:          appID,
+		Type:           req.Type,
+		Description:    req.Description,
+		RequiredFields: req.RequiredFields,
+		AllowedFields:  req.AllowedFields,
+	}
+	if err := s.typeRepo.Create(ctx, detailType); err != nil {
+		return nil, fmt.Errorf("failed to create authorization detail type: %w", err)
+	}
+	return detailType, nil
+}
+
+// ListTypes 获取应用登记的授权详情类型
+func (s *authorizationDetailService) ListTypes(ctx context.Context, appID string) ([]*model.AuthorizationDetailType, error) {
+	return s.typeRepo.ListByAppID(ctx, appID)
+}
+
+// DeleteType 删除应用登记的授权详情类型
+func (s *authorizationDetailService) DeleteType(ctx context.Context, appID, detailType string) error {
+	existing, err := s.typeRepo.Get(ctx, appID, detailType)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrAuthorizationDetailTypeNotFound
+	}
+	return s.typeRepo.Delete(ctx, appID, detailType)
+}
+
+// Parse 解析authorization_details参数，并按应用登记的类型校验每一项
+func (s *authorizationDetailService) Parse(ctx context.Context, appID, raw string) ([]model.AuthorizationDetail, error) {
+	details, err := parseAuthorizationDetails(raw)
+	if err != nil || details == nil {
+		return nil, err
+	}
+
+	types := make(map[string]*model.AuthorizationDetailType)
+	for _, detail := range details {
+		detailType, ok := types[detail.Type()]
+		if !ok {
+			if detailType, err = s.typeRepo.Get(ctx, appID, detail.Type()); err != nil {
+				return nil, err
+			}
+			types[detail.Type()] = detailType
+		}
+		if detailType == nil {
+			log.Printf("Authorization detail type %q is not registered for app %s", detail.Type(), appID)
+			return nil, ErrInvalidAuthorizationDetails
+		}
+		if err := validateAuthorizationDetail(detail, detailType); err != nil {
+			return nil, err
+		}
+	}
+	return details, nil
+}
+
+// parseAuthorizationDetails 解析authorization_details参数的JSON结构
+// 参数必须是非空的对象数组，每个对象都有字符串类型的type字段
+func parseAuthorizationDetails(raw string) ([]model.AuthorizationDetail, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var details []model.AuthorizationDetail
+	if err := json.Unmarshal([]byte(raw), &details); err != nil {
+		log.Printf("Malformed authorization_details: %v", err)
+		return nil, ErrInvalidAuthorizationDetails
+	}
+	if len(details) == 0 {
+		log.Printf("authorization_details must not be empty")
+		return nil, ErrInvalidAuthorizationDetails
+	}
+	for _, detail := range details {
+		if detail == nil || detail.Type() == "" {
+			log.Printf("Authorization detail is missing the type field")
+			return nil, ErrInvalidAuthorizationDetails
+		}
+	}
+	return details, nil
+}
+
+// validateAuthorizationDetail 校验一项授权详情的公共字段与类型登记的字段约束
+func validateAuthorizationDetail(detail model.AuthorizationDetail, detailType *model.AuthorizationDetailType) error {
+	for _, field := range authorizationDetailStringFields {
+		if value, ok := detail[field]; ok {
+			if _, ok := value.(string); !ok {
+				log.Printf("Authorization detail field %s must be a string", field)
+				return ErrInvalidAuthorizationDetails
+			}
+		}
+	}
+	for _, field := range authorizationDetailArrayFields {
+		if value, ok := detail[field]; ok && !isStringArray(value) {
+			log.Printf("Authorization detail field %s must be an array of strings", field)
+			return ErrInvalidAuthorizationDetails
+		}
+	}
+
+	for _, field := range detailType.RequiredFields {
+		if _, ok := detail[field]; !ok {
+			log.Printf("Authorization detail of type %s is missing required field %s", detailType.Type, field)
+			return ErrInvalidAuthorizationDetails
+		}
+	}
+	if len(detailType.AllowedFields) > 0 {
+		for field := range detail {
+			if field == "type" || containsString(detailType.AllowedFields, field) || containsString(detailType.RequiredFields, field) {
+				continue
+			}
+			log.Printf("Authorization detail of type %s contains unknown field %s", detailType.Type, field)
+			return ErrInvalidAuthorizationDetails
+		}
+	}
+	return nil
+}
+
+// isStringArray 判断JSON值是否为字符串数组
+func isStringArray(value interface{}) bool {
+	values, ok := value.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, v := range values {
+		if _, ok := v.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// authorizationDetailsContain 判断granted是否包含requested中的每一项
+func authorizationDetailsContain(granted, requested []model.AuthorizationDetail) bool {
+	for _, detail := range requested {
+		if !containsAuthorizationDetail(granted, detail) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeAuthorizationDetails 将requested中尚未包含的授权详情追加到granted
+func mergeAuthorizationDetails(granted, requested []model.AuthorizationDetail) []model.AuthorizationDetail {
+	for _, detail := range requested {
+		if !containsAuthorizationDetail(granted, detail) {
+			granted = append(granted, detail)
+		}
+	}
+	return granted
+}
+
+// containsAuthorizationDetail 判断列表中是否有与detail完全相同的授权详情
+func containsAuthorizationDetail(details []model.AuthorizationDetail, detail model.AuthorizationDetail) bool {
+	for _, d := range details {
+		if reflect.DeepEqual(d, detail) {
+			return true
+		}
+	}
+	return false
+}
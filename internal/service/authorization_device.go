@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"lauth/internal/model"
+)
+
+// DeviceAuthorization 处理设备授权请求(RFC 8628)
+func (s *authorizationService) DeviceAuthorization(ctx context.Context, req *model.DeviceAuthorizationRequest) (*model.DeviceAuthorizationResponse, error) {
+	log.Printf("Processing device authorization request for client_id: %s", req.ClientID)
+
+	client, err := s.clientAuthenticator.Authenticate(ctx, &req.ClientAuthentication)
+	if err != nil {
+		return nil, err
+	}
+	if !s.containsGrantType(client.GrantTypes, string(model.DeviceCodeGrant)) {
+		log.Printf("Client %s is not allowed to use device authorization grant", req.ClientID)
+		return nil, ErrUnauthorizedClient
+	}
+
+	// 未指定scope时使用客户端的全部权限范围
+	scope := req.Scope
+	if scope == "" {
+		scope = strings.Join(client.Scopes, " ")
+	} else if !s.validateScope(client.Scopes, scope) {
+		log.Printf("Invalid scope for device authorization: %s", scope)
+		return nil, ErrInvalidScope
+	}
+
+	return s.deviceService.CreateDeviceCode(ctx, client, scope)
+}
+
+// handleDeviceCodeGrant 处理设备授权类型的轮询请求
+func (s *authorizationService) handleDeviceCodeGrant(ctx context.Context, req *model.TokenRequest, client *model.OAuthClient) (*model.TokenResponse, error) {
+	if !s.containsGrantType(client.GrantTypes, string(model.DeviceCodeGrant)) {
+		log.Printf("Client %s is not allowed to use device authorization grant", req.ClientID)
+		return nil, ErrUnauthorizedClient
+	}
+
+	dc, err := s.deviceService.PollDeviceCode(ctx, client.ClientID, req.DeviceCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先使设备码失效再颁发令牌，保证设备码只能换取一次令牌
+	if err := s.deviceService.ConsumeDeviceCode(ctx, dc); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Issuing tokens for device authorization of client_id: %s", client.ClientID)
+	return s.issueUserTokens(ctx, client, dc.UserID, &model.TokenOptions{
+		Scope:   dc.Scope,
+		DPoPJKT: req.DPoPJKT,
+	}, &model.IDTokenOptions{
+		AuthTime:  dc.AuthTime,
+		ACR:       dc.ACR,
+		AMR:       dc.AMR,
+		SessionID: dc.SessionID,
+	})
+}
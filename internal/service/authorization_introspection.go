@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"lauth/internal/model"
+)
+
+// IntrospectToken 内省令牌(RFC 7662)
+// 调用方必须是完成认证的机密客户端，且只能内省本应用颁发的令牌
+func (s *authorizationService) IntrospectToken(ctx context.Context, req *model.IntrospectionRequest) (*model.IntrospectionResponse, error) {
+	client, err := s.clientAuthenticator.Authenticate(ctx, &req.ClientAuthentication)
+	if err != nil {
+		return nil, err
+	}
+	// 公开客户端无法证明自身身份，不允许内省
+	if req.AuthMethod == model.TokenEndpointAuthNone {
+		log.Printf("Introspection requires client credentials")
+		return nil, ErrInvalidClient
+	}
+
+	inactive := &model.IntrospectionResponse{Active: false}
+
+	// 解析令牌，签名错误、已吊销或已过期的令牌均视为非活跃
+	claims, err := s.tokenService.ParseToken(ctx, req.Token)
+	if err != nil {
+		log.Printf("Introspected token is not active: %v", err)
+		return inactive, nil
+	}
+
+	// 不向其他应用的客户端暴露令牌信息
+	if claims.AppID != client.AppID {
+		log.Printf("Client %s attempted to introspect token of another app", client.ClientID)
+		return inactive, nil
+	}
+
+	// 资源服务器提供自身的资源标识时，受众不包含该资源的访问令牌对其无效(RFC 8707)
+	if req.Resource != "" && claims.Type != model.RefreshToken && !audienceAllows(claims.Audience, req.Resource) {
+		log.Printf("Introspected token audience %v does not include resource %s", claims.Audience, req.Resource)
+		return inactive, nil
+	}
+
+	// pairwise客户端的令牌以成对sub作为主体，不暴露用户ID与用户名
+	subject := claims.UserID
+	if claims.Subject != "" {
+		subject = claims.Subject
+	}
+
+	resp := &model.IntrospectionResponse{
+		Active:   true,
+		Scope:    claims.Scope,
+		ClientID: claims.ClientID,
+		Username: claims.Username,
+		Sub:      subject,
+		Exp:      claims.ExpiresAt.Unix(),
+		Aud:      claims.Audience,
+		Act:      claims.Actor,
+
+		AuthorizationDetails: claims.AuthorizationDetails,
+		Cnf:                  claims.Confirmation,
+	}
+	if !claims.IssuedAt.IsZero() {
+		resp.Iat = claims.IssuedAt.Unix()
+	}
+
+	switch claims.Type {
+	case model.RefreshToken:
+		resp.TokenType = model.TokenTypeHintRefreshToken
+	default:
+		resp.TokenType = tokenTypeFor(claims.DPoPJKT())
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"lauth/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// resolveAuthorizationRequest 展开request_uri或request参数引用的授权参数
+func (s *authorizationService) resolveAuthorizationRequest(ctx context.Context, req *model.AuthorizationRequest) error {
+	if req.Request != "" && req.RequestURI != "" {
+		log.Printf("request and request_uri cannot be used together")
+		return ErrInvalidRequest
+	}
+	if err := s.resolveRequestURI(ctx, req); err != nil {
+		return err
+	}
+	return s.resolveRequestObject(ctx, req)
+}
+
+// resolveRequestObject 验证request参数中的请求对象(RFC 9101)并合并其中的授权参数
+func (s *authorizationService) resolveRequestObject(ctx context.Context, req *model.AuthorizationRequest) error {
+	if req.Request == "" {
+		return nil
+	}
+
+	client, err := s.clientRepo.GetByClientID(ctx, req.ClientID)
+	if err != nil {
+		log.Printf("Error getting client: %v", err)
+		return err
+	}
+	if client == nil || !client.Status {
+		log.Printf("Invalid or inactive client: %s", req.ClientID)
+		return ErrInvalidClient
+	}
+
+	claims, err := s.clientKeyService.VerifyClientJWT(ctx, client, req.Request, client.RequestObjectSigningAlg)
+	if err != nil {
+		log.Printf("Failed to verify request object for client %s: %v", req.ClientID, err)
+		return ErrInvalidRequestObject
+	}
+	if err := requireRequestObjectClaims(claims, client.ClientID); err != nil {
+		log.Printf("Invalid request object for client %s: %v", req.ClientID, err)
+		return ErrInvalidRequestObject
+	}
+	if err := mergeRequestObject(req, claims); err != nil {
+		log.Printf("Invalid request object for client %s: %v", req.ClientID, err)
+		return err
+	}
+
+	req.Request = ""
+	return nil
+}
+
+// requireRequestObjectClaims 检查请求对象必须包含的声明(RFC 9101 6.3)
+// VerifyClientJWT只在声明存在时校验iss与aud，请求对象必须由客户端签发、以本服务为受众并限定有效期，
+// 避免为其他授权服务器签发的或不过期的请求对象被重放
+func requireRequestObjectClaims(claims jwt.MapClaims, clientID string) error {
+	if iss, _ := claims["iss"].(string); iss != clientID {
+		return fmt.Errorf("request object issuer must be the client id")
+	}
+	if _, ok := claims["aud"]; !ok {
+		return fmt.Errorf("request object must have an audience")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return fmt.Errorf("request object must have an expiration time")
+	}
+	return nil
+}
+
+// mergeRequestObject 以请求对象中的授权参数替换查询参数
+// 按RFC 9101 5，只使用请求对象中的参数，查询参数中未签名的值(redirect_uri、scope、state等)一律忽略，
+// 唯一的例外是用于查找客户端的client_id
+func mergeRequestObject(req *model.AuthorizationRequest, claims jwt.MapClaims) error {
+	// 请求对象中的client_id必须与查询参数一致，且不能嵌套引用其他请求
+	if clientID, ok := claims["client_id"]; ok && clientID != req.ClientID {
+		return ErrInvalidRequestObject
+	}
+	if _, ok := claims["request"]; ok {
+		return ErrInvalidRequestObject
+	}
+	if _, ok := claims["request_uri"]; ok {
+		return ErrInvalidRequestObject
+	}
+
+	*req = model.AuthorizationRequest{ClientID: req.ClientID}
+	var responseType string
+	fields := map[string]*string{
+		"response_type":         &responseType,
+		"response_mode":         &req.ResponseMode,
+		"redirect_uri":          &req.RedirectURI,
+		"scope":                 &req.Scope,
+		"state":                 &req.State,
+		"nonce":                 &req.Nonce,
+		"display":               &req.Display,
+		"prompt":                &req.Prompt,
+		"ui_locales":            &req.UILocales,
+		"id_token_hint":         &req.IDTokenHint,
+		"login_hint":            &req.LoginHint,
+		"acr_values":            &req.ACRValues,
+		"code_challenge":        &req.CodeChallenge,
+		"code_challenge_method": &req.CodeChallengeMethod,
+	}
+	for name, field := range fields {
+		value, ok := claims[name]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return ErrInvalidRequestObject
+		}
+		*field = str
+	}
+
+	switch model.ResponseType(responseType) {
+	case "", model.CodeResponse, model.IDTokenResponse, model.IDTokenTokenResponse, model.CodeIDTokenResponse, model.CodeIDTokenTokenResponse:
+		req.ResponseType = model.ResponseType(responseType)
+	default:
+		return ErrInvalidRequestObject
+	}
+	switch req.ResponseMode {
+	case "", model.ResponseModeQuery, model.ResponseModeFragment, model.ResponseModeFormPost:
+	default:
+		return ErrInvalidRequestObject
+	}
+
+	// 请求对象中的authorization_details是JSON数组，转换为与查询参数相同的编码形式
+	if value, ok := claims["authorization_details"]; ok {
+		if _, ok := value.([]interface{}); !ok {
+			return ErrInvalidRequestObject
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return ErrInvalidRequestObject
+		}
+		req.AuthorizationDetails = string(data)
+	}
+
+	// resource可以是单个资源标识或资源标识数组
+	if value, ok := claims["resource"]; ok {
+		switch v := value.(type) {
+		case string:
+			req.Resource = []string{v}
+		case []interface{}:
+			resources := make([]string, 0, len(v))
+			for _, item := range v {
+				resource, ok := item.(string)
+				if !ok {
+					return ErrInvalidRequestObject
+				}
+				resources = append(resources, resource)
+			}
+			req.Resource = resources
+		default:
+			return ErrInvalidRequestObject
+		}
+	}
+
+	if value, ok := claims["max_age"]; ok {
+		maxAge, ok := value.(float64)
+		if !ok || maxAge != float64(int(maxAge)) {
+			return ErrInvalidRequestObject
+		}
+		age := int(maxAge)
+		req.MaxAge = &age
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"lauth/internal/model"
+)
+
+// PushAuthorizationRequest 处理推送授权请求(RFC 9126)
+func (s *authorizationService) PushAuthorizationRequest(ctx context.Context, req *model.PushedAuthorizationRequest) (*model.PushedAuthorizationResponse, error) {
+	log.Printf("Processing pushed authorization request for client_id: %s", req.ClientID)
+
+	if _, err := s.clientAuthenticator.Authenticate(ctx, &req.Client); err != nil {
+		return nil, err
+	}
+	// 授权参数中的client_id必须是完成认证的客户端
+	if req.Client.ClientID != req.ClientID {
+		log.Printf("client_id %s does not match the authenticated client %s", req.ClientID, req.Client.ClientID)
+		return nil, ErrInvalidRequest
+	}
+
+	// 推送的请求本身不能再引用其他推送请求
+	if req.RequestURI != "" {
+		log.Printf("request_uri is not allowed in pushed authorization request")
+		return nil, ErrInvalidRequest
+	}
+	// 推送时即验证请求对象，保存的是合并后的授权参数
+	if err := s.resolveRequestObject(ctx, &req.AuthorizationRequest); err != nil {
+		return nil, err
+	}
+
+	// 与授权端点使用相同的校验规则，错误在推送时即返回给客户端
+	if _, err := s.validateAuthorizationRequest(ctx, &req.AuthorizationRequest); err != nil {
+		return nil, err
+	}
+
+	return s.parService.Push(ctx, &req.AuthorizationRequest)
+}
+
+// resolveRequestURI 使用request_uri引用的推送授权请求替换授权参数
+func (s *authorizationService) resolveRequestURI(ctx context.Context, req *model.AuthorizationRequest) error {
+	if req.RequestURI == "" {
+		return nil
+	}
+
+	pushed, err := s.parService.Resolve(ctx, req.ClientID, req.RequestURI)
+	if err != nil {
+		log.Printf("Failed to resolve request_uri for client %s: %v", req.ClientID, err)
+		return err
+	}
+
+	*req = *pushed
+	return nil
+}
+
+// checkPushedAuthorization 检查要求推送授权请求的客户端是否使用了request_uri
+func checkPushedAuthorization(client *model.OAuthClient, req *model.AuthorizationRequest) error {
+	if client.RequirePushedAuthorizationRequests && req.RequestURI == "" {
+		log.Printf("Client %s requires pushed authorization requests", client.ClientID)
+		return ErrInvalidRequest
+	}
+	return nil
+}
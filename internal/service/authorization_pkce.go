@@ -0,0 +1,83 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"log"
+	"regexp"
+
+	"lauth/internal/model"
+)
+
+// pkceValuePattern RFC 7636 规定的code_verifier/code_challenge字符集与长度(43-128个非保留字符)
+var pkceValuePattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// requiresPKCE 判断客户端是否必须使用PKCE
+// 公开客户端无法保存密钥，始终强制PKCE；机密客户端按客户端配置决定
+func requiresPKCE(client *model.OAuthClient) bool {
+	return client.Type == model.Public || client.RequirePKCE
+}
+
+// validateCodeChallenge 验证授权请求中的PKCE参数，并补全默认的挑战值计算方法
+func validateCodeChallenge(client *model.OAuthClient, req *model.AuthorizationRequest) error {
+	if req.CodeChallenge == "" {
+		if req.CodeChallengeMethod != "" {
+			log.Printf("code_challenge_method provided without code_challenge")
+			return ErrInvalidRequest
+		}
+		if requiresPKCE(client) {
+			log.Printf("PKCE is required for client %s", client.ClientID)
+			return ErrInvalidRequest
+		}
+		return nil
+	}
+
+	// 未指定方法时按RFC 7636默认为plain
+	if req.CodeChallengeMethod == "" {
+		req.CodeChallengeMethod = model.CodeChallengeMethodPlain
+	}
+	if req.CodeChallengeMethod != model.CodeChallengeMethodPlain && req.CodeChallengeMethod != model.CodeChallengeMethodS256 {
+		log.Printf("Unsupported code_challenge_method: %s", req.CodeChallengeMethod)
+		return ErrInvalidRequest
+	}
+	if !pkceValuePattern.MatchString(req.CodeChallenge) {
+		log.Printf("Malformed code_challenge for client %s", client.ClientID)
+		return ErrInvalidRequest
+	}
+
+	return nil
+}
+
+// verifyCodeVerifier 使用授权码中保存的挑战值校验code_verifier
+func verifyCodeVerifier(authCode *model.AuthorizationCode, client *model.OAuthClient, verifier string) error {
+	if authCode.CodeChallenge == "" {
+		// 授权时未使用PKCE，则令牌请求也不能携带code_verifier
+		if verifier != "" || requiresPKCE(client) {
+			log.Printf("Unexpected or missing PKCE for authorization code of client %s", client.ClientID)
+			return ErrInvalidGrant
+		}
+		return nil
+	}
+
+	if !pkceValuePattern.MatchString(verifier) {
+		log.Printf("Missing or malformed code_verifier for client %s", client.ClientID)
+		return ErrInvalidGrant
+	}
+
+	var computed string
+	switch authCode.CodeChallengeMethod {
+	case model.CodeChallengeMethodS256:
+		sum := sha256.Sum256([]byte(verifier))
+		computed = base64.RawURLEncoding.EncodeToString(sum[:])
+	default:
+		computed = verifier
+	}
+
+	if subtle.ConstantTimeCompare([]byte(computed), []byte(authCode.CodeChallenge)) != 1 {
+		log.Printf("code_verifier does not match code_challenge for client %s", client.ClientID)
+		return ErrInvalidGrant
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"lauth/internal/model"
+)
+
+// promptLoginMaxAge prompt=login时认为用户"刚刚"完成认证的时间窗口
+const promptLoginMaxAge = 2 * time.Minute
+
+// validatePrompt 验证prompt参数，none不能与其他值同时出现(OIDC Core 3.1.2.1)
+func validatePrompt(prompt string) error {
+	values := strings.Fields(prompt)
+	for _, p := range values {
+		switch p {
+		case model.PromptNone:
+			if len(values) > 1 {
+				log.Printf("prompt=none must not be combined with other values: %q", prompt)
+				return ErrInvalidRequest
+			}
+		case model.PromptLogin, model.PromptConsent, model.PromptSelectAccount:
+		default:
+			log.Printf("Unsupported prompt value: %s", p)
+			return ErrInvalidRequest
+		}
+	}
+	return nil
+}
+
+// checkAuthentication 检查当前登录会话是否满足授权请求的认证要求
+// 未登录、认证时间不满足prompt=login/max_age、认证强度不满足acr_values、
+// 或与login_hint/id_token_hint指定的用户不一致时返回ErrLoginRequired
+func (s *authorizationService) checkAuthentication(ctx context.Context, authCtx *model.AuthContext, client *model.OAuthClient, req *model.AuthorizationRequest) error {
+	if authCtx == nil {
+		return ErrLoginRequired
+	}
+	// 会话必须属于客户端所在的应用
+	if authCtx.AppID != client.AppID {
+		log.Printf("Session of app %s cannot authorize client %s", authCtx.AppID, client.ClientID)
+		return ErrLoginRequired
+	}
+
+	authAge := time.Since(authCtx.AuthTime)
+	if hasPrompt(req.Prompt, model.PromptLogin) && authAge > promptLoginMaxAge {
+		log.Printf("prompt=login requires re-authentication of user %s", authCtx.UserID)
+		return ErrLoginRequired
+	}
+	if req.MaxAge != nil && authAge > time.Duration(*req.MaxAge)*time.Second {
+		log.Printf("Authentication of user %s is older than max_age=%d", authCtx.UserID, *req.MaxAge)
+		return ErrLoginRequired
+	}
+	// 认证强度不足时要求用户携带acr_values重新登录，以完成相应的验证插件
+	if !acrSatisfies(authCtx.ACR, req.ACRValues) {
+		log.Printf("Authentication of user %s (acr=%q) does not satisfy acr_values=%q", authCtx.UserID, authCtx.ACR, req.ACRValues)
+		return ErrLoginRequired
+	}
+
+	if req.LoginHint != "" {
+		user, err := s.userRepo.GetByID(ctx, authCtx.UserID)
+		if err != nil {
+			return err
+		}
+		if user == nil || !matchesLoginHint(user, req.LoginHint) {
+			log.Printf("login_hint does not match user %s", authCtx.UserID)
+			return ErrLoginRequired
+		}
+	}
+
+	if req.IDTokenHint != "" {
+		hint, err := s.oidcService.ParseIDTokenHint(ctx, req.IDTokenHint)
+		if err != nil {
+			log.Printf("Invalid id_token_hint: %v", err)
+			return ErrInvalidRequest
+		}
+		if hint.Subject != authCtx.UserID {
+			log.Printf("id_token_hint subject does not match user %s", authCtx.UserID)
+			return ErrLoginRequired
+		}
+	}
+
+	return nil
+}
+
+// matchesLoginHint 判断login_hint是否指向当前用户(用户名、邮箱或手机号)
+func matchesLoginHint(user *model.User, hint string) bool {
+	return hint == user.Username ||
+		(user.Email != "" && strings.EqualFold(hint, user.Email)) ||
+		(user.Phone != "" && hint == user.Phone)
+}
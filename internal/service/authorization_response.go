@@ -0,0 +1,124 @@
+package service
+
+import (
+	"log"
+	"net/url"
+	"strings"
+
+	"lauth/internal/model"
+)
+
+// responseTypeHas 判断响应类型(空格分隔)是否包含指定值
+func responseTypeHas(responseType model.ResponseType, value string) bool {
+	for _, rt := range strings.Fields(string(responseType)) {
+		if rt == value {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsResponseType 判断授权类型是否允许使用该响应类型
+// 包含code的响应类型需要authorization_code授权，授权端点直接返回访问令牌或只返回ID Token时需要implicit授权
+func allowsResponseType(grantTypes []string, responseType model.ResponseType) bool {
+	switch responseType {
+	case model.CodeResponse, model.IDTokenResponse, model.IDTokenTokenResponse,
+		model.CodeIDTokenResponse, model.CodeIDTokenTokenResponse:
+	default:
+		return false
+	}
+
+	hasCode := responseTypeHas(responseType, "code")
+	if hasCode && !containsString(grantTypes, string(model.AuthorizationCodeGrant)) {
+		return false
+	}
+	if (responseTypeHas(responseType, "token") || !hasCode) && !containsString(grantTypes, string(model.Implicit)) {
+		return false
+	}
+	return true
+}
+
+// validateResponseType 验证响应类型与客户端授权类型一致，并检查直接返回ID Token时的必需参数
+func validateResponseType(client *model.OAuthClient, req *model.AuthorizationRequest) error {
+	if !allowsResponseType(client.GrantTypes, req.ResponseType) {
+		log.Printf("Client %s is not allowed to use response_type %q", req.ClientID, req.ResponseType)
+		return ErrUnsupportedGrantType
+	}
+
+	hasToken := responseTypeHas(req.ResponseType, "token")
+	hasIDToken := responseTypeHas(req.ResponseType, "id_token")
+
+	// 授权端点返回的ID Token必须携带nonce以防重放(OIDC Core 3.2.2.1)
+	if hasIDToken {
+		if !strings.Contains(" "+req.Scope+" ", " "+model.ScopeOpenID+" ") {
+			log.Printf("response_type %q requires the openid scope", req.ResponseType)
+			return ErrInvalidScope
+		}
+		if req.Nonce == "" {
+			log.Printf("nonce is required for response_type %q", req.ResponseType)
+			return ErrInvalidRequest
+		}
+	}
+
+	// 令牌不能出现在查询参数中，以免被记录到日志或通过Referer泄露
+	if req.ResponseMode == model.ResponseModeQuery && (hasToken || hasIDToken) {
+		log.Printf("response_mode=query is not allowed for response_type %q", req.ResponseType)
+		return ErrInvalidRequest
+	}
+
+	return nil
+}
+
+// responseMode 获取授权响应的返回方式，未指定时code使用query，其余响应类型使用fragment
+func responseMode(req *model.AuthorizationRequest) string {
+	if req.ResponseMode != "" {
+		return req.ResponseMode
+	}
+	if req.ResponseType == "" || req.ResponseType == model.CodeResponse {
+		return model.ResponseModeQuery
+	}
+	return model.ResponseModeFragment
+}
+
+// buildAuthorizationResult 按响应方式将响应参数返回给客户端
+func buildAuthorizationResult(req *model.AuthorizationRequest, params url.Values) (*model.AuthorizationResult, error) {
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+
+	mode := responseMode(req)
+	if mode == model.ResponseModeFormPost {
+		formParams := make(map[string]string, len(params))
+		for name := range params {
+			formParams[name] = params.Get(name)
+		}
+		return &model.AuthorizationResult{
+			RedirectURL:  req.RedirectURI,
+			ResponseMode: mode,
+			FormParams:   formParams,
+		}, nil
+	}
+
+	redirectURL, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		log.Printf("Failed to parse redirect URI: %v", err)
+		return nil, err
+	}
+	if mode == model.ResponseModeFragment {
+		// 注册的重定向URI不含fragment，直接拼接编码后的参数
+		redirectURL.Fragment = ""
+		return &model.AuthorizationResult{RedirectURL: redirectURL.String() + "#" + params.Encode()}, nil
+	}
+
+	query := redirectURL.Query()
+	for name := range params {
+		query.Set(name, params.Get(name))
+	}
+	redirectURL.RawQuery = query.Encode()
+	return &model.AuthorizationResult{RedirectURL: redirectURL.String()}, nil
+}
+
+// buildErrorResult 构建携带错误码的授权响应
+func buildErrorResult(req *model.AuthorizationRequest, errorCode string) (*model.AuthorizationResult, error) {
+	return buildAuthorizationResult(req, url.Values{"error": {errorCode}})
+}
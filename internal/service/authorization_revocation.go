@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"lauth/internal/model"
+)
+
+// RevokeToken 吊销令牌(RFC 7009)
+// 无效、过期或已吊销的令牌直接视为吊销成功；吊销刷新令牌时同族的访问令牌一并失效
+func (s *authorizationService) RevokeToken(ctx context.Context, req *model.RevocationRequest) error {
+	client, err := s.clientAuthenticator.Authenticate(ctx, &req.ClientAuthentication)
+	if err != nil {
+		return err
+	}
+
+	claims, err := s.tokenService.ParseToken(ctx, req.Token)
+	if err != nil {
+		log.Printf("Ignoring revocation of inactive token: %v", err)
+		return nil
+	}
+
+	// 只能吊销颁发给本客户端的令牌；直接登录颁发的令牌不含client_id，按应用归属判断
+	if claims.AppID != client.AppID || (claims.ClientID != "" && claims.ClientID != client.ClientID) {
+		log.Printf("Client %s attempted to revoke a token issued to another client", client.ClientID)
+		return ErrUnauthorizedClient
+	}
+
+	if err := s.tokenService.RevokeToken(ctx, req.Token, claims.Type); err != nil {
+		log.Printf("Failed to revoke token: %v", err)
+		return err
+	}
+
+	log.Printf("Revoked %s token for client_id: %s", claims.Type, client.ClientID)
+	return nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"lauth/internal/model"
+)
+
+// handleTokenExchangeGrant 处理令牌交换授权类型(RFC 8693)
+// 提供actor_token时为委托，颁发的令牌通过act声明记录参与方；否则为模拟，令牌直接代表主体
+func (s *authorizationService) handleTokenExchangeGrant(ctx context.Context, req *model.TokenRequest, client *model.OAuthClient) (*model.TokenResponse, error) {
+	log.Printf("Processing token exchange for client_id: %s", client.ClientID)
+
+	// 只有完成认证的机密客户端才能交换令牌
+	if !s.containsGrantType(client.GrantTypes, string(model.TokenExchangeGrant)) || req.AuthMethod == model.TokenEndpointAuthNone {
+		log.Printf("Client %s is not allowed to use token exchange", client.ClientID)
+		return nil, ErrUnauthorizedClient
+	}
+
+	if req.SubjectToken == "" || req.SubjectTokenType != model.TokenTypeAccessToken {
+		log.Printf("Unsupported subject_token_type: %s", req.SubjectTokenType)
+		return nil, ErrInvalidRequest
+	}
+	if req.RequestedTokenType != "" && req.RequestedTokenType != model.TokenTypeAccessToken {
+		log.Printf("Unsupported requested_token_type: %s", req.RequestedTokenType)
+		return nil, ErrInvalidRequest
+	}
+
+	// 主体令牌必须是本应用颁发的有效用户访问令牌
+	subject, err := s.tokenService.ValidateToken(ctx, req.SubjectToken, model.AccessToken)
+	if err != nil {
+		log.Printf("Invalid subject token: %v", err)
+		return nil, ErrInvalidRequest
+	}
+	if subject.AppID != client.AppID {
+		log.Printf("Subject token belongs to another app: %s", subject.AppID)
+		return nil, ErrInvalidRequest
+	}
+	// 绑定DPoP公钥的主体令牌只能由持有该密钥的客户端交换，令牌请求必须附带同一公钥签名的证明
+	if jkt := subject.DPoPJKT(); jkt != "" && jkt != req.DPoPJKT {
+		log.Printf("Subject token is bound to a DPoP key but no matching proof was presented")
+		return nil, ErrInvalidDPoPProof
+	}
+
+	actor, err := s.resolveTokenActor(ctx, req, client, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	// 目标受众必须在客户端的令牌交换策略内，resource(RFC 8707)与audience一样作为目标受众
+	audiences := append(append([]string{}, req.Audience...), req.Resource...)
+	if len(audiences) == 0 {
+		log.Printf("Token exchange requires an audience")
+		return nil, ErrInvalidRequest
+	}
+	for _, audience := range audiences {
+		if !containsString(client.TokenExchangeAudiences, audience) {
+			log.Printf("Client %s is not allowed to exchange tokens for audience %s", client.ClientID, audience)
+			return nil, ErrInvalidTarget
+		}
+	}
+
+	// 交换得到的令牌只能缩小权限范围
+	scope := subject.Scope
+	if req.Scope != "" {
+		if !s.validateScope(strings.Fields(subject.Scope), req.Scope) {
+			log.Printf("Requested scope %q exceeds subject token scope %q", req.Scope, subject.Scope)
+			return nil, ErrInvalidScope
+		}
+		scope = req.Scope
+	}
+
+	pairwiseSubject, err := s.pairwiseSubject(ctx, client, subject.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresIn, err := s.tokenService.GenerateExchangedToken(ctx, subject, &model.TokenExchangeOptions{
+		ClientID: client.ClientID,
+		Scope:    scope,
+		Audience: audiences,
+		Actor:    actor,
+		DPoPJKT:  req.DPoPJKT,
+		Subject:  pairwiseSubject,
+	})
+	if err != nil {
+		log.Printf("Failed to generate exchanged token: %v", err)
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		AccessToken:     accessToken,
+		TokenType:       tokenTypeFor(req.DPoPJKT),
+		ExpiresIn:       int64(expiresIn.Seconds()),
+		Scope:           scope,
+		IssuedTokenType: model.TokenTypeAccessToken,
+	}, nil
+}
+
+// resolveTokenActor 根据actor_token确定委托的参与方，主体令牌已有的委托链嵌套在内层
+func (s *authorizationService) resolveTokenActor(ctx context.Context, req *model.TokenRequest, client *model.OAuthClient, subject *model.TokenClaims) (*model.TokenActor, error) {
+	if req.ActorToken == "" {
+		if req.ActorTokenType != "" {
+			log.Printf("actor_token_type provided without actor_token")
+			return nil, ErrInvalidRequest
+		}
+		return subject.Actor, nil
+	}
+	if req.ActorTokenType != model.TokenTypeAccessToken {
+		log.Printf("Unsupported actor_token_type: %s", req.ActorTokenType)
+		return nil, ErrInvalidRequest
+	}
+
+	// 参与方令牌必须是颁发给发起交换的客户端、面向本服务的有效访问令牌，客户端不能冒用其他客户端的身份
+	claims, err := s.tokenService.ValidateTokenForAudience(ctx, req.ActorToken, model.AccessToken, "")
+	if err == ErrInvalidToken {
+		claims, err = s.tokenService.ValidateTokenForAudience(ctx, req.ActorToken, model.ClientAccessToken, "")
+	}
+	if err != nil {
+		log.Printf("Invalid actor token: %v", err)
+		return nil, ErrInvalidRequest
+	}
+	if claims.AppID != client.AppID || claims.ClientID != client.ClientID {
+		log.Printf("Actor token was not issued to client %s", client.ClientID)
+		return nil, ErrInvalidRequest
+	}
+	if jkt := claims.DPoPJKT(); jkt != "" && jkt != req.DPoPJKT {
+		log.Printf("Actor token is bound to a DPoP key but no matching proof was presented")
+		return nil, ErrInvalidDPoPProof
+	}
+
+	// 服务令牌的主体是客户端本身，pairwise客户端的令牌沿用成对sub
+	actorSubject := claims.UserID
+	if claims.IsClientToken() {
+		actorSubject = claims.ClientID
+	} else if claims.Subject != "" {
+		actorSubject = claims.Subject
+	}
+	return &model.TokenActor{
+		Subject:  actorSubject,
+		ClientID: claims.ClientID,
+		Actor:    subject.Actor,
+	}, nil
+}
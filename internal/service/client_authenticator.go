@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"lauth/internal/model"
+	"lauth/internal/repository"
+	"lauth/pkg/redis"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// clientAssertionMaxLifetime 客户端断言的最长有效期，同时限制jti防重放记录的保存时间
+const clientAssertionMaxLifetime = time.Hour
+
+// supportedClientSecretJWTAlgs client_secret_jwt接受的HMAC签名算法
+var supportedClientSecretJWTAlgs = []string{"HS256", "HS384", "HS512"}
+
+// supportedTokenEndpointAuthMethods 支持的客户端认证方式
+var supportedTokenEndpointAuthMethods = []string{
+	model.TokenEndpointAuthNone,
+	model.TokenEndpointAuthClientSecretBasic,
+	model.TokenEndpointAuthClientSecretPost,
+	model.TokenEndpointAuthClientSecretJWT,
+	model.TokenEndpointAuthPrivateKeyJWT,
+}
+
+// ClientAuthenticator 客户端认证器
+// 令牌、内省、吊销、推送授权与设备授权端点共用同一套客户端认证规则
+type ClientAuthenticator interface {
+	// Authenticate 按客户端登记的认证方式验证凭证
+	// 认证成功后creds.ClientID与creds.AuthMethod为实际认证的客户端与方式
+	Authenticate(ctx context.Context, creds *model.ClientAuthentication) (*model.OAuthClient, error)
+}
+
+// clientAuthenticator 客户端认证器实现
+type clientAuthenticator struct {
+	clientRepo    repository.OAuthClientRepository
+	secretRepo    repository.OAuthClientSecretRepository
+	keyService    ClientKeyService
+	redis         *redis.Client
+	issuer        string
+	tokenEndpoint string
+}
+
+// NewClientAuthenticator 创建客户端认证器实例
+func NewClientAuthenticator(
+	clientRepo repository.OAuthClientRepository,
+	secretRepo repository.OAuthClientSecretRepository,
+	keyService ClientKeyService,
+	redisClient *redis.Client,
+	issuer string,
+) ClientAuthenticator {
+	return &clientAuthenticator{
+		clientRepo:    clientRepo,
+		secretRepo:    secretRepo,
+		keyService:    keyService,
+		redis:         redisClient,
+		issuer:        issuer,
+		tokenEndpoint: issuer + "/oauth/token",
+	}
+}
+
+// Authenticate 按客户端登记的认证方式验证凭证
+func (a *clientAuthenticator) Authenticate(ctx context.Context, creds *model.ClientAuthentication) (*model.OAuthClient, error) {
+	method, err := a.detectAuthMethod(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := a.clientRepo.GetByClientID(ctx, creds.ClientID)
+	if err != nil {
+		log.Printf("Error getting client: %v", err)
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	if client == nil || !client.Status {
+		log.Printf("Invalid or inactive client: %s", creds.ClientID)
+		return nil, ErrInvalidClient
+	}
+
+	// 打印客户端信息（注意不要打印密钥）
+	log.Printf("Found client: id=%s, name=%s, type=%s, auth_method=%s", client.ID, client.Name, client.Type, method)
+
+	if !allowedAuthMethod(client, method) {
+		log.Printf("Client %s is not allowed to authenticate with %s", client.ClientID, method)
+		return nil, ErrInvalidClient
+	}
+
+	switch method {
+	case model.TokenEndpointAuthClientSecretBasic, model.TokenEndpointAuthClientSecretPost:
+		err = a.verifySecret(ctx, client, creds.ClientSecret)
+	case model.TokenEndpointAuthClientSecretJWT:
+		err = a.verifySecretAssertion(ctx, client, creds.ClientAssertion)
+	case model.TokenEndpointAuthPrivateKeyJWT:
+		err = a.verifyPrivateKeyAssertion(ctx, client, creds.ClientAssertion)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	creds.AuthMethod = method
+	return client, nil
+}
+
+// detectAuthMethod 根据请求中携带的凭证确定认证方式
+func (a *clientAuthenticator) detectAuthMethod(creds *model.ClientAuthentication) (string, error) {
+	if creds.ClientAssertion == "" {
+		if creds.ClientAssertionType != "" {
+			log.Printf("client_assertion_type provided without client_assertion")
+			return "", ErrInvalidClient
+		}
+		if creds.ClientID == "" {
+			return "", ErrInvalidClient
+		}
+		if creds.AuthMethod != "" {
+			return creds.AuthMethod, nil
+		}
+		if creds.ClientSecret != "" {
+			return model.TokenEndpointAuthClientSecretPost, nil
+		}
+		return model.TokenEndpointAuthNone, nil
+	}
+
+	// 客户端只能使用一种认证方式
+	if creds.ClientAssertionType != model.ClientAssertionTypeJWTBearer || creds.ClientSecret != "" || creds.AuthMethod != "" {
+		log.Printf("Invalid client assertion type or multiple client authentication methods")
+		return "", ErrInvalidClient
+	}
+
+	// 先读取未验证的sub确定客户端，签名在获取到客户端的密钥后验证
+	token, _, err := jwt.NewParser().ParseUnverified(creds.ClientAssertion, jwt.MapClaims{})
+	if err != nil {
+		log.Printf("Malformed client assertion: %v", err)
+		return "", ErrInvalidClient
+	}
+	sub, _ := token.Claims.GetSubject()
+	if sub == "" || (creds.ClientID != "" && creds.ClientID != sub) {
+		log.Printf("Client assertion subject %q does not match client_id %q", sub, creds.ClientID)
+		return "", ErrInvalidClient
+	}
+	creds.ClientID = sub
+
+	if strings.HasPrefix(token.Method.Alg(), "HS") {
+		return model.TokenEndpointAuthClientSecretJWT, nil
+	}
+	return model.TokenEndpointAuthPrivateKeyJWT, nil
+}
+
+// allowedAuthMethod 判断客户端是否可以使用该认证方式
+// 未登记认证方式的客户端保持原有规则：机密客户端必须提供密钥，公开客户端可以不认证
+func allowedAuthMethod(client *model.OAuthClient, method string) bool {
+	if client.TokenEndpointAuthMethod != "" {
+		return client.TokenEndpointAuthMethod == method
+	}
+	switch method {
+	case model.TokenEndpointAuthClientSecretBasic, model.TokenEndpointAuthClientSecretPost:
+		return true
+	case model.TokenEndpointAuthNone:
+		return client.Type == model.Public
+	default:
+		return false
+	}
+}
+
+// verifySecret 验证客户端密钥并更新最后使用时间
+func (a *clientAuthenticator) verifySecret(ctx context.Context, client *model.OAuthClient, clientSecret string) error {
+	secret, err := a.secretRepo.ValidateSecret(ctx, client.ClientID, clientSecret)
+	if err != nil {
+		log.Printf("Error validating client secret: %v", err)
+		return fmt.Errorf("failed to validate client secret: %w", err)
+	}
+	if secret == nil {
+		log.Printf("Invalid client secret for client_id: %s", client.ClientID)
+		return ErrInvalidClient
+	}
+
+	if err := a.secretRepo.UpdateLastUsedAt(ctx, secret.ID); err != nil {
+		log.Printf("Failed to update secret last used time: %v", err)
+	}
+	return nil
+}
+
+// verifySecretAssertion 验证以客户端密钥HMAC签名的断言(client_secret_jwt)
+// 客户端可能有多个有效密钥，依次尝试直到签名验证通过
+func (a *clientAuthenticator) verifySecretAssertion(ctx context.Context, client *model.OAuthClient, assertion string) error {
+	secrets, err := a.secretRepo.GetByClientID(ctx, client.ClientID)
+	if err != nil {
+		log.Printf("Error getting client secrets: %v", err)
+		return fmt.Errorf("failed to get client secrets: %w", err)
+	}
+
+	now := time.Now()
+	parser := jwt.NewParser(jwt.WithValidMethods(supportedClientSecretJWTAlgs))
+	for _, secret := range secrets {
+		if !secret.ExpiresAt.After(now) {
+			continue
+		}
+		claims := jwt.MapClaims{}
+		key := []byte(secret.Secret)
+		if _, err := parser.ParseWithClaims(assertion, claims, func(*jwt.Token) (interface{}, error) {
+			return key, nil
+		}); err != nil {
+			continue
+		}
+
+		if err := a.secretRepo.UpdateLastUsedAt(ctx, secret.ID); err != nil {
+			log.Printf("Failed to update secret last used time: %v", err)
+		}
+		return a.checkAssertionClaims(ctx, client, claims)
+	}
+
+	log.Printf("Client assertion signature could not be verified for client_id: %s", client.ClientID)
+	return ErrInvalidClient
+}
+
+// verifyPrivateKeyAssertion 验证以客户端私钥签名的断言(private_key_jwt)
+func (a *clientAuthenticator) verifyPrivateKeyAssertion(ctx context.Context, client *model.OAuthClient, assertion string) error {
+	claims, err := a.keyService.VerifyClientJWT(ctx, client, assertion, "", a.tokenEndpoint)
+	if err != nil {
+		log.Printf("Failed to verify client assertion for client_id %s: %v", client.ClientID, err)
+		return ErrInvalidClient
+	}
+	return a.checkAssertionClaims(ctx, client, claims)
+}
+
+// checkAssertionClaims 校验断言的必需声明(RFC 7523 3)，并通过jti防止断言被重放
+func (a *clientAuthenticator) checkAssertionClaims(ctx context.Context, client *model.OAuthClient, claims jwt.MapClaims) error {
+	iss, _ := claims.GetIssuer()
+	sub, _ := claims.GetSubject()
+	if iss != client.ClientID || sub != client.ClientID {
+		log.Printf("Client assertion iss/sub must be the client_id: iss=%s, sub=%s", iss, sub)
+		return ErrInvalidClient
+	}
+
+	aud, err := claims.GetAudience()
+	if err != nil || !audienceMatches(aud, []string{a.issuer, a.tokenEndpoint}) {
+		log.Printf("Client assertion audience is invalid: %v", aud)
+		return ErrInvalidClient
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		log.Printf("Client assertion must have an expiration time")
+		return ErrInvalidClient
+	}
+	ttl := time.Until(exp.Time)
+	if ttl > clientAssertionMaxLifetime {
+		log.Printf("Client assertion lifetime exceeds %v", clientAssertionMaxLifetime)
+		return ErrInvalidClient
+	}
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		log.Printf("Client assertion must have a jti")
+		return ErrInvalidClient
+	}
+	// 断言过期前记录jti，同一断言只能使用一次
+	ok, err := a.redis.SetNX(ctx, a.assertionJTIKey(client.ClientID, jti), "1", ttl).Result()
+	if err != nil {
+		return fmt.Errorf("failed to record client assertion: %w", err)
+	}
+	if !ok {
+		log.Printf("Client assertion jti %s has already been used by client %s", jti, client.ClientID)
+		return ErrInvalidClient
+	}
+
+	return nil
+}
+
+// assertionJTIKey 构建已使用的客户端断言键
+func (a *clientAuthenticator) assertionJTIKey(clientID, jti string) string {
+	return fmt.Sprintf("client_assertion_jti:%s:%s", clientID, jti)
+}
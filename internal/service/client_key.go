@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"lauth/internal/model"
+	"lauth/pkg/crypto"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// clientJWKSCacheTTL 通过jwks_uri获取的客户端公钥缓存时间
+	clientJWKSCacheTTL = 5 * time.Minute
+	// clientJWKSRefetchInterval 两次获取同一jwks_uri的最小间隔，未知kid与获取失败都不会更频繁地访问客户端
+	clientJWKSRefetchInterval = 30 * time.Second
+	// clientJWKSMaxSize 客户端JWK Set响应的最大长度
+	clientJWKSMaxSize = 64 << 10
+)
+
+// supportedClientSigningAlgs 接受的客户端JWT签名算法，不接受none与对称算法
+var supportedClientSigningAlgs = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+}
+
+// ErrClientKeyNotFound 客户端未注册可用于验签的公钥
+var ErrClientKeyNotFound = errors.New("client key not found")
+
+// ClientKeyService 客户端公钥服务接口
+type ClientKeyService interface {
+	// PublicKeys 获取客户端注册的公钥，jwks_uri的结果会被缓存
+	PublicKeys(ctx context.Context, client *model.OAuthClient) ([]*crypto.PublicJWK, error)
+
+	// VerifyClientJWT 使用客户端注册的公钥验证客户端签名的JWT
+	// alg为空时接受所有支持的算法；iss存在时必须为客户端ID，aud存在时必须包含issuer或audiences之一
+	VerifyClientJWT(ctx context.Context, client *model.OAuthClient, tokenString, alg string, audiences ...string) (jwt.MapClaims, error)
+}
+
+// clientJWKSEntry 缓存的客户端JWK Set，获取失败时记录错误作为否定缓存
+type clientJWKSEntry struct {
+	keys      []*crypto.PublicJWK
+	err       error
+	fetchedAt time.Time
+}
+
+// clientKeyService 客户端公钥服务实现
+type clientKeyService struct {
+	issuer     string
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	cache map[string]*clientJWKSEntry
+}
+
+// NewClientKeyService 创建客户端公钥服务实例
+func NewClientKeyService(issuer string) ClientKeyService {
+	return &clientKeyService{
+		issuer:     issuer,
+		httpClient: newExternalHTTPClient(5 * time.Second),
+		cache:      make(map[string]*clientJWKSEntry),
+	}
+}
+
+// PublicKeys 获取客户端注册的公钥
+func (s *clientKeyService) PublicKeys(ctx context.Context, client *model.OAuthClient) ([]*crypto.PublicJWK, error) {
+	if client.JWKS != "" {
+		return crypto.ParseJWKSet([]byte(client.JWKS))
+	}
+	if client.JWKSURI == "" {
+		return nil, ErrClientKeyNotFound
+	}
+
+	s.mu.RLock()
+	entry := s.cache[client.JWKSURI]
+	s.mu.RUnlock()
+	if entry != nil && entry.err == nil && time.Since(entry.fetchedAt) < clientJWKSCacheTTL {
+		return entry.keys, nil
+	}
+	return s.fetch(ctx, client.JWKSURI)
+}
+
+// VerifyClientJWT 使用客户端注册的公钥验证客户端签名的JWT
+func (s *clientKeyService) VerifyClientJWT(ctx context.Context, client *model.OAuthClient, tokenString, alg string, audiences ...string) (jwt.MapClaims, error) {
+	algs := supportedClientSigningAlgs
+	if alg != "" {
+		algs = []string{alg}
+	}
+
+	keys, err := s.PublicKeys(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key := selectClientKey(keys, kid, token.Method.Alg())
+		// 客户端可能已轮换密钥，kid未命中时重新获取一次jwks_uri，距上次获取不足最小间隔时沿用缓存
+		if key == nil && client.JWKSURI != "" && client.JWKS == "" {
+			refreshed, err := s.fetch(ctx, client.JWKSURI)
+			if err != nil {
+				return nil, err
+			}
+			key = selectClientKey(refreshed, kid, token.Method.Alg())
+		}
+		if key == nil {
+			return nil, ErrClientKeyNotFound
+		}
+		return key.Key, nil
+	}
+	if _, err := jwt.ParseWithClaims(tokenString, claims, keyFunc, jwt.WithValidMethods(algs)); err != nil {
+		return nil, fmt.Errorf("invalid client jwt: %w", err)
+	}
+
+	if iss, ok := claims["iss"]; ok && iss != client.ClientID {
+		return nil, fmt.Errorf("invalid client jwt: unexpected issuer %v", iss)
+	}
+	if _, ok := claims["aud"]; ok {
+		aud, err := claims.GetAudience()
+		if err != nil {
+			return nil, fmt.Errorf("invalid client jwt: %w", err)
+		}
+		if !audienceMatches(aud, append([]string{s.issuer}, audiences...)) {
+			return nil, fmt.Errorf("invalid client jwt: unexpected audience %v", aud)
+		}
+	}
+
+	return claims, nil
+}
+
+// fetch 获取jwks_uri发布的公钥并更新缓存，距上次获取不足最小间隔时返回缓存的结果
+func (s *clientKeyService) fetch(ctx context.Context, jwksURI string) ([]*crypto.PublicJWK, error) {
+	s.mu.RLock()
+	entry := s.cache[jwksURI]
+	s.mu.RUnlock()
+	if entry != nil && time.Since(entry.fetchedAt) < clientJWKSRefetchInterval {
+		return entry.keys, entry.err
+	}
+
+	keys, err := s.download(ctx, jwksURI)
+	s.mu.Lock()
+	s.cache[jwksURI] = &clientJWKSEntry{keys: keys, err: err, fetchedAt: time.Now()}
+	s.mu.Unlock()
+	return keys, err
+}
+
+// download 通过https获取jwks_uri发布的JWK Set
+func (s *clientKeyService) download(ctx context.Context, jwksURI string) ([]*crypto.PublicJWK, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jwks request: %w", err)
+	}
+	if req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("jwks_uri must use https: %s", jwksURI)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		log.Printf("Failed to fetch client jwks from %s: %v", jwksURI, err)
+		return nil, fmt.Errorf("failed to fetch client jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Unexpected status fetching client jwks from %s: %d", jwksURI, resp.StatusCode)
+		return nil, fmt.Errorf("failed to fetch client jwks: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, clientJWKSMaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client jwks: %w", err)
+	}
+	return crypto.ParseJWKSet(data)
+}
+
+// selectClientKey 根据kid与算法选择验签公钥，未指定kid时客户端只能有一个可用密钥
+func selectClientKey(keys []*crypto.PublicJWK, kid, alg string) *crypto.PublicJWK {
+	kty := "RSA"
+	if alg[:2] == "ES" {
+		kty = "EC"
+	}
+
+	var candidates []*crypto.PublicJWK
+	for _, key := range keys {
+		if key.KeyType != kty || key.Use == "enc" || (key.Algorithm != "" && key.Algorithm != alg) {
+			continue
+		}
+		if kid != "" && key.KeyID == kid {
+			return key
+		}
+		candidates = append(candidates, key)
+	}
+	if kid == "" && len(candidates) == 1 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// audienceMatches 判断aud是否包含任一期望的受众
+func audienceMatches(aud jwt.ClaimStrings, expected []string) bool {
+	for _, a := range aud {
+		for _, e := range expected {
+			if a == e {
+				return true
+			}
+		}
+	}
+	return false
+}
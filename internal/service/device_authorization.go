@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"lauth/internal/model"
+	"lauth/pkg/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// deviceCodeExpiry 设备码有效期
+	deviceCodeExpiry = 10 * time.Minute
+	// deviceCodeRetention 设备码过期后继续保留的时间，用于向轮询方返回expired_token
+	deviceCodeRetention = 10 * time.Minute
+	// devicePollingInterval 默认最小轮询间隔(秒)
+	devicePollingInterval = 5
+	// deviceSlowDownIncrement 收到slow_down后轮询间隔的增量(秒)
+	deviceSlowDownIncrement = 5
+	// userCodeCharset 用户码字符集，去除元音与易混淆字符(RFC 8628 6.1)
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+	// userCodeLength 用户码长度(不含分隔符)
+	userCodeLength = 8
+	// deviceVerificationPath 设备确认接口的路径，未配置验证地址时作为verification_uri
+	deviceVerificationPath = "/api/v1/oauth/device"
+)
+
+// DeviceAuthorizationService 设备授权服务接口(RFC 8628)
+type DeviceAuthorizationService interface {
+	// CreateDeviceCode 为已认证的客户端创建设备码与用户码
+	CreateDeviceCode(ctx context.Context, client *model.OAuthClient, scope string) (*model.DeviceAuthorizationResponse, error)
+	// GetDeviceCodeInfo 根据用户码获取待确认的设备授权信息
+	GetDeviceCodeInfo(ctx context.Context, appID, userCode string) (*model.DeviceCodeInfo, error)
+	// VerifyUserCode 用户批准或拒绝设备授权
+	VerifyUserCode(ctx context.Context, authCtx *model.AuthContext, req *model.DeviceVerificationRequest) error
+	// PollDeviceCode 设备轮询授权结果，仅在用户已批准时返回设备授权会话
+	PollDeviceCode(ctx context.Context, clientID, deviceCode string) (*model.DeviceCode, error)
+	// ConsumeDeviceCode 使用已批准的设备码，设备码只能使用一次
+	ConsumeDeviceCode(ctx context.Context, dc *model.DeviceCode) error
+}
+
+// deviceAuthorizationService 设备授权服务实现
+type deviceAuthorizationService struct {
+	redis           *redis.Client
+	verificationURI string
+}
+
+// NewDeviceAuthorizationService 创建设备授权服务实例
+// verificationURI为用户输入用户码的页面，为空时使用颁发者下的设备确认接口
+func NewDeviceAuthorizationService(redisClient *redis.Client, issuer, verificationURI string) DeviceAuthorizationService {
+	if verificationURI == "" {
+		verificationURI = strings.TrimSuffix(issuer, "/") + deviceVerificationPath
+	}
+	return &deviceAuthorizationService{
+		redis:           redisClient,
+		verificationURI: verificationURI,
+	}
+}
+
+// CreateDeviceCode 为已认证的客户端创建设备码与用户码
+func (s *deviceAuthorizationService) CreateDeviceCode(ctx context.Context, client *model.OAuthClient, scope string) (*model.DeviceAuthorizationResponse, error) {
+	deviceCode, err := generateSecureToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate device code: %w", err)
+	}
+
+	// 用户码空间较小，通过SETNX预留避免与未过期的用户码冲突
+	var userCode string
+	for i := 0; i < 3 && userCode == ""; i++ {
+		candidate, err := generateUserCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate user code: %w", err)
+		}
+		ok, err := s.redis.SetNX(ctx, s.userCodeKey(candidate), deviceCode, deviceCodeExpiry).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to store user code: %w", err)
+		}
+		if ok {
+			userCode = candidate
+		}
+	}
+	if userCode == "" {
+		return nil, errors.New("failed to allocate unique user code")
+	}
+
+	dc := &model.DeviceCode{
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		ClientID:   client.ClientID,
+		ClientName: client.Name,
+		AppID:      client.AppID,
+		Scope:      scope,
+		Status:     model.DeviceCodePending,
+		Interval:   devicePollingInterval,
+		ExpiresAt:  time.Now().Add(deviceCodeExpiry),
+	}
+	if err := s.save(ctx, dc); err != nil {
+		return nil, err
+	}
+
+	return &model.DeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                formatUserCode(userCode),
+		VerificationURI:         s.verificationURI,
+		VerificationURIComplete: s.verificationURIComplete(userCode),
+		ExpiresIn:               int64(deviceCodeExpiry.Seconds()),
+		Interval:                devicePollingInterval,
+	}, nil
+}
+
+// GetDeviceCodeInfo 根据用户码获取待确认的设备授权信息
+func (s *deviceAuthorizationService) GetDeviceCodeInfo(ctx context.Context, appID, userCode string) (*model.DeviceCodeInfo, error) {
+	dc, err := s.getPendingByUserCode(ctx, appID, userCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DeviceCodeInfo{
+		UserCode:   formatUserCode(dc.UserCode),
+		ClientID:   dc.ClientID,
+		ClientName: dc.ClientName,
+		Scope:      dc.Scope,
+		ExpiresAt:  dc.ExpiresAt,
+	}, nil
+}
+
+// VerifyUserCode 用户批准或拒绝设备授权
+func (s *deviceAuthorizationService) VerifyUserCode(ctx context.Context, authCtx *model.AuthContext, req *model.DeviceVerificationRequest) error {
+	dc, err := s.getPendingByUserCode(ctx, authCtx.AppID, req.UserCode)
+	if err != nil {
+		return err
+	}
+
+	dc.UserID = authCtx.UserID
+	dc.AuthTime = authCtx.AuthTime
+	dc.ACR = authCtx.ACR
+	dc.AMR = authCtx.AMR
+	dc.SessionID = authCtx.SessionID
+	if req.Approve {
+		dc.Status = model.DeviceCodeApproved
+	} else {
+		dc.Status = model.DeviceCodeDenied
+	}
+	if err := s.save(ctx, dc); err != nil {
+		return err
+	}
+
+	// 用户码只能确认一次
+	if err := s.redis.Del(ctx, s.userCodeKey(dc.UserCode)); err != nil {
+		log.Printf("Failed to delete user code: %v", err)
+	}
+
+	log.Printf("Device authorization %s by user %s for client %s", dc.Status, dc.UserID, dc.ClientID)
+	return nil
+}
+
+// PollDeviceCode 设备轮询授权结果
+func (s *deviceAuthorizationService) PollDeviceCode(ctx context.Context, clientID, deviceCode string) (*model.DeviceCode, error) {
+	dc, err := s.load(ctx, deviceCode)
+	if err != nil {
+		return nil, err
+	}
+	if dc == nil || dc.ClientID != clientID {
+		log.Printf("Unknown device code for client %s", clientID)
+		return nil, ErrInvalidGrant
+	}
+
+	now := time.Now()
+	if now.After(dc.ExpiresAt) {
+		return nil, ErrExpiredToken
+	}
+
+	// 轮询状态保存在独立的键中，轮询不改写设备授权会话，避免覆盖用户同时做出的批准
+	interval, err := s.pollInterval(ctx, dc)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.redis.SetNX(ctx, s.pollKey(dc.DeviceCode), "1", interval).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to record device poll: %w", err)
+	}
+	if !ok {
+		// 轮询间隔过短时要求设备降低频率，并延长之后的最小间隔
+		if err := s.slowDown(ctx, dc); err != nil {
+			return nil, err
+		}
+		return nil, ErrSlowDown
+	}
+
+	switch dc.Status {
+	case model.DeviceCodeApproved:
+		return dc, nil
+	case model.DeviceCodeDenied:
+		if err := s.redis.Del(ctx, s.deviceCodeKey(dc.DeviceCode)); err != nil {
+			log.Printf("Failed to delete denied device code: %v", err)
+		}
+		return nil, ErrAccessDenied
+	default:
+		return nil, ErrAuthorizationPending
+	}
+}
+
+// verificationURIComplete 构建包含用户码的验证地址，保留验证地址原有的查询参数
+func (s *deviceAuthorizationService) verificationURIComplete(userCode string) string {
+	u, err := url.Parse(s.verificationURI)
+	if err != nil {
+		return s.verificationURI
+	}
+	query := u.Query()
+	query.Set("user_code", formatUserCode(userCode))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// ConsumeDeviceCode 使用已批准的设备码
+func (s *deviceAuthorizationService) ConsumeDeviceCode(ctx context.Context, dc *model.DeviceCode) error {
+	// 以删除结果判断是否为首次使用，避免并发轮询重复颁发令牌
+	deleted, err := s.redis.Client.Del(ctx, s.deviceCodeKey(dc.DeviceCode)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to consume device code: %w", err)
+	}
+	if deleted == 0 {
+		return ErrInvalidGrant
+	}
+	return nil
+}
+
+// pollInterval 获取设备当前的最小轮询间隔
+func (s *deviceAuthorizationService) pollInterval(ctx context.Context, dc *model.DeviceCode) (time.Duration, error) {
+	value, err := s.redis.Get(ctx, s.pollIntervalKey(dc.DeviceCode))
+	if err == goredis.Nil {
+		return time.Duration(dc.Interval) * time.Second, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get device poll interval: %w", err)
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid device poll interval: %w", err)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// slowDown 延长设备的最小轮询间隔，并从本次轮询开始重新计算
+func (s *deviceAuthorizationService) slowDown(ctx context.Context, dc *model.DeviceCode) error {
+	key := s.pollIntervalKey(dc.DeviceCode)
+	ttl := time.Until(dc.ExpiresAt) + deviceCodeRetention
+	if err := s.redis.SetNX(ctx, key, dc.Interval, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store device poll interval: %w", err)
+	}
+	seconds, err := s.redis.IncrBy(ctx, key, deviceSlowDownIncrement).Result()
+	if err != nil {
+		return fmt.Errorf("failed to update device poll interval: %w", err)
+	}
+	if err := s.redis.Set(ctx, s.pollKey(dc.DeviceCode), "1", time.Duration(seconds)*time.Second); err != nil {
+		return fmt.Errorf("failed to record device poll: %w", err)
+	}
+	return nil
+}
+
+// getPendingByUserCode 根据用户码获取待确认的设备授权会话
+func (s *deviceAuthorizationService) getPendingByUserCode(ctx context.Context, appID, userCode string) (*model.DeviceCode, error) {
+	deviceCode, err := s.redis.Get(ctx, s.userCodeKey(normalizeUserCode(userCode)))
+	if err == goredis.Nil {
+		return nil, ErrInvalidUserCode
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user code: %w", err)
+	}
+
+	dc, err := s.load(ctx, deviceCode)
+	if err != nil {
+		return nil, err
+	}
+	// 用户只能确认本应用客户端发起的设备授权
+	if dc == nil || dc.AppID != appID || dc.Status != model.DeviceCodePending || time.Now().After(dc.ExpiresAt) {
+		return nil, ErrInvalidUserCode
+	}
+
+	return dc, nil
+}
+
+// load 从Redis读取设备授权会话，不存在时返回nil
+func (s *deviceAuthorizationService) load(ctx context.Context, deviceCode string) (*model.DeviceCode, error) {
+	data, err := s.redis.Get(ctx, s.deviceCodeKey(deviceCode))
+	if err == goredis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device code: %w", err)
+	}
+
+	var dc model.DeviceCode
+	if err := json.Unmarshal([]byte(data), &dc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal device code: %w", err)
+	}
+	return &dc, nil
+}
+
+// save 保存设备授权会话，过期后保留一段时间以便返回expired_token
+func (s *deviceAuthorizationService) save(ctx context.Context, dc *model.DeviceCode) error {
+	data, err := json.Marshal(dc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal device code: %w", err)
+	}
+
+	ttl := time.Until(dc.ExpiresAt) + deviceCodeRetention
+	if err := s.redis.Set(ctx, s.deviceCodeKey(dc.DeviceCode), string(data), ttl); err != nil {
+		return fmt.Errorf("failed to store device code: %w", err)
+	}
+	return nil
+}
+
+// deviceCodeKey 构建设备码键
+func (s *deviceAuthorizationService) deviceCodeKey(deviceCode string) string {
+	return fmt.Sprintf("device_code:%s", deviceCode)
+}
+
+// pollKey 构建设备最近一次轮询的键，在最小轮询间隔内存在
+func (s *deviceAuthorizationService) pollKey(deviceCode string) string {
+	return fmt.Sprintf("device_poll:%s", deviceCode)
+}
+
+// pollIntervalKey 构建设备最小轮询间隔的键，收到slow_down后才会写入
+func (s *deviceAuthorizationService) pollIntervalKey(deviceCode string) string {
+	return fmt.Sprintf("device_poll_interval:%s", deviceCode)
+}
+
+// userCodeKey 构建用户码键
+func (s *deviceAuthorizationService) userCodeKey(userCode string) string {
+	return fmt.Sprintf("device_user_code:%s", userCode)
+}
+
+// generateUserCode 生成便于用户输入的用户码
+func generateUserCode() (string, error) {
+	max := big.NewInt(int64(len(userCodeCharset)))
+	code := make([]byte, userCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeCharset[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// formatUserCode 将用户码格式化为XXXX-XXXX
+func formatUserCode(userCode string) string {
+	if len(userCode) != userCodeLength {
+		return userCode
+	}
+	return userCode[:userCodeLength/2] + "-" + userCode[userCodeLength/2:]
+}
+
+// normalizeUserCode 规范化用户输入的用户码(忽略大小写、分隔符与空白)
+func normalizeUserCode(userCode string) string {
+	userCode = strings.ToUpper(userCode)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, userCode)
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"lauth/internal/model"
+	"lauth/pkg/crypto"
+	"lauth/pkg/redis"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// dpopProofTyp DPoP证明的typ头部
+	dpopProofTyp = "dpop+jwt"
+	// dpopProofMaxAge DPoP证明的最长有效时间，同时限制jti防重放记录的保存时间
+	dpopProofMaxAge = 5 * time.Minute
+	// dpopClockSkew 允许客户端时钟超前的时间
+	dpopClockSkew = 30 * time.Second
+)
+
+// DPoPService DPoP证明验证服务(RFC 9449)
+type DPoPService interface {
+	// VerifyProof 验证DPoP证明并返回证明公钥的JWK指纹
+	// method与requestURL为实际请求的方法与URL；accessToken非空时证明必须通过ath绑定该令牌
+	VerifyProof(ctx context.Context, proof, method, requestURL, accessToken string) (string, error)
+
+	// VerifyTokenBinding 校验访问令牌的DPoP绑定，presentation为nil表示令牌以Bearer方案或Cookie携带
+	VerifyTokenBinding(ctx context.Context, token string, claims *model.TokenClaims, presentation *model.DPoPPresentation) error
+}
+
+// dpopService DPoP证明验证服务实现
+type dpopService struct {
+	redis *redis.Client
+}
+
+// NewDPoPService 创建DPoP证明验证服务实例
+func NewDPoPService(redisClient *redis.Client) DPoPService {
+	return &dpopService{
+		redis: redisClient,
+	}
+}
+
+// VerifyProof 验证DPoP证明(RFC 9449 4.3)
+func (s *dpopService) VerifyProof(ctx context.Context, proof, method, requestURL, accessToken string) (string, error) {
+	if proof == "" || strings.Contains(proof, ",") {
+		return "", ErrInvalidDPoPProof
+	}
+
+	// 证明使用头部jwk中的公钥签名，验签后该公钥的指纹即令牌绑定的密钥
+	var jkt string
+	claims := jwt.MapClaims{}
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != dpopProofTyp {
+			return nil, fmt.Errorf("unexpected typ %q", typ)
+		}
+		data, err := json.Marshal(token.Header["jwk"])
+		if err != nil {
+			return nil, err
+		}
+		jwk, err := crypto.ParsePublicJWK(data)
+		if err != nil {
+			return nil, err
+		}
+		if jkt, err = crypto.PublicKeyThumbprint(jwk.Key); err != nil {
+			return nil, err
+		}
+		return jwk.Key, nil
+	}
+	if _, err := jwt.ParseWithClaims(proof, claims, keyFunc, jwt.WithValidMethods(supportedClientSigningAlgs)); err != nil {
+		log.Printf("Failed to verify DPoP proof: %v", err)
+		return "", ErrInvalidDPoPProof
+	}
+
+	if htm, _ := claims["htm"].(string); htm != method {
+		log.Printf("DPoP proof htm %q does not match request method %s", htm, method)
+		return "", ErrInvalidDPoPProof
+	}
+	if htu, _ := claims["htu"].(string); !dpopURLMatches(htu, requestURL) {
+		log.Printf("DPoP proof htu %q does not match request url %s", htu, requestURL)
+		return "", ErrInvalidDPoPProof
+	}
+
+	iat, err := claims.GetIssuedAt()
+	if err != nil || iat == nil {
+		log.Printf("DPoP proof must have an issued at time")
+		return "", ErrInvalidDPoPProof
+	}
+	if age := time.Since(iat.Time); age > dpopProofMaxAge || age < -dpopClockSkew {
+		log.Printf("DPoP proof issued at %v is outside the accepted window", iat.Time)
+		return "", ErrInvalidDPoPProof
+	}
+
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		if ath, _ := claims["ath"].(string); ath != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			log.Printf("DPoP proof ath does not match the access token")
+			return "", ErrInvalidDPoPProof
+		}
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		log.Printf("DPoP proof must have a jti")
+		return "", ErrInvalidDPoPProof
+	}
+	// 证明有效期内记录jti，同一证明只能使用一次
+	ok, err := s.redis.SetNX(ctx, s.proofJTIKey(jkt, jti), "1", dpopProofMaxAge+dpopClockSkew).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to record dpop proof: %w", err)
+	}
+	if !ok {
+		log.Printf("DPoP proof jti %s has already been used", jti)
+		return "", ErrInvalidDPoPProof
+	}
+
+	return jkt, nil
+}
+
+// VerifyTokenBinding 校验访问令牌的DPoP绑定(RFC 9449 7)
+// 绑定的令牌必须以DPoP方案携带，并附带同一公钥签名、与本次请求及令牌对应的证明；
+// 以Bearer方案或Cookie携带的绑定令牌视为被盗用，未绑定的令牌也不能以DPoP方案携带
+func (s *dpopService) VerifyTokenBinding(ctx context.Context, token string, claims *model.TokenClaims, presentation *model.DPoPPresentation) error {
+	jkt := claims.DPoPJKT()
+	if jkt == "" {
+		if presentation != nil {
+			return ErrInvalidDPoPProof
+		}
+		return nil
+	}
+	if presentation == nil {
+		log.Printf("DPoP-bound token presented without a proof")
+		return ErrInvalidDPoPProof
+	}
+
+	proofJKT, err := s.VerifyProof(ctx, presentation.Proof, presentation.Method, presentation.URL, token)
+	if err != nil {
+		return err
+	}
+	if proofJKT != jkt {
+		log.Printf("DPoP proof key %s does not match the token binding %s", proofJKT, jkt)
+		return ErrInvalidDPoPProof
+	}
+	return nil
+}
+
+// proofJTIKey 构建已使用的DPoP证明键
+func (s *dpopService) proofJTIKey(jkt, jti string) string {
+	return fmt.Sprintf("dpop_jti:%s:%s", jkt, jti)
+}
+
+// dpopURLMatches 比较htu与请求URL，忽略查询参数与片段(RFC 9449 4.3)
+func dpopURLMatches(htu, requestURL string) bool {
+	if htu == "" {
+		return false
+	}
+	proofURL, err := url.Parse(htu)
+	if err != nil {
+		return false
+	}
+	actualURL, err := url.Parse(requestURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(proofURL.Scheme, actualURL.Scheme) &&
+		strings.EqualFold(proofURL.Host, actualURL.Host) &&
+		proofURL.EscapedPath() == actualURL.EscapedPath()
+}
+
+// tokenTypeFor 根据令牌是否绑定DPoP公钥确定响应中的token_type
+func tokenTypeFor(dpopJKT string) string {
+	if dpopJKT != "" {
+		return model.TokenTypeDPoP
+	}
+	return model.TokenTypeBearer
+}
@@ -13,4 +13,38 @@ var (
 	ErrUnsupportedGrantType = errors.New("unsupported grant type")
 	// ErrInvalidGrant 无效的授权码
 	ErrInvalidGrant = errors.New("invalid grant")
+	// ErrInvalidRequest 无效的请求参数
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrUnauthorizedClient 客户端无权使用该授权类型
+	ErrUnauthorizedClient = errors.New("unauthorized client")
+	// ErrAuthorizationPending 用户尚未完成设备授权
+	ErrAuthorizationPending = errors.New("authorization pending")
+	// ErrSlowDown 设备轮询过于频繁
+	ErrSlowDown = errors.New("slow down")
+	// ErrExpiredToken 设备码已过期
+	ErrExpiredToken = errors.New("expired token")
+	// ErrAccessDenied 用户拒绝了授权
+	ErrAccessDenied = errors.New("access denied")
+	// ErrInvalidUserCode 无效的用户码
+	ErrInvalidUserCode = errors.New("invalid user code")
+	// ErrConsentRequired 需要用户同意授权
+	ErrConsentRequired = errors.New("consent required")
+	// ErrConsentNotFound 授权同意记录不存在
+	ErrConsentNotFound = errors.New("consent not found")
+	// ErrLoginRequired 需要用户重新认证
+	ErrLoginRequired = errors.New("login required")
+	// ErrUnmetAuthenticationRequirements 没有可用的验证方式能达到授权请求要求的认证强度
+	ErrUnmetAuthenticationRequirements = errors.New("unmet authentication requirements")
+	// ErrInvalidRequestURI 无效或已过期的request_uri
+	ErrInvalidRequestURI = errors.New("invalid request uri")
+	// ErrInvalidRequestObject 请求对象无效或签名验证失败
+	ErrInvalidRequestObject = errors.New("invalid request object")
+	// ErrInvalidTarget 客户端无权申请该目标受众
+	ErrInvalidTarget = errors.New("invalid target")
+	// ErrInvalidAuthorizationDetails 授权详情格式错误、类型未登记或超出已授予的范围
+	ErrInvalidAuthorizationDetails = errors.New("invalid authorization details")
+	// ErrInvalidDPoPProof DPoP证明无效、已被使用或与令牌绑定的公钥不一致
+	ErrInvalidDPoPProof = errors.New("invalid dpop proof")
+	// ErrLogoutConfirmationRequired 登出请求无法证明来自用户正在使用的客户端，需要用户确认
+	ErrLogoutConfirmationRequired = errors.New("logout confirmation required")
 )
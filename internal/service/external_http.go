@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// externalHTTPMaxRedirects 获取客户端提供的URL时最多跟随的重定向次数
+const externalHTTPMaxRedirects = 3
+
+// errInternalAddress 客户端提供的URL解析到了不能从外部访问的地址
+var errInternalAddress = errors.New("address is not publicly routable")
+
+// sharedAddressSpace 运营商级NAT地址段(RFC 6598)，net.IP.IsPrivate不包含该地址段
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// newExternalHTTPClient 创建获取客户端提供的URL(jwks_uri、sector_identifier_uri)的HTTP客户端
+// 这些URL可通过动态注册由任何人设置，连接前检查实际解析到的地址，拒绝回环、链路本地与内网地址，
+// 避免服务端被用来访问内部服务(SSRF)；检查在建立连接时进行，DNS重绑定与重定向同样无法绕过。
+// 不使用代理，否则检查的将是代理地址
+func newExternalHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: rejectInternalAddress,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to non-https url %s", req.URL.Redacted())
+			}
+			if len(via) >= externalHTTPMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", externalHTTPMaxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// rejectInternalAddress 拒绝连接不能从外部访问的地址
+func rejectInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: %s", errInternalAddress, host)
+	}
+	return nil
+}
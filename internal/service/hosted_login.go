@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"lauth/internal/model"
+	"lauth/internal/plugin/types"
+	"lauth/internal/repository"
+	"lauth/pkg/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// interactionExpiry 托管页面交互的有效期，需覆盖用户登录、插件验证与确认授权的时间
+const interactionExpiry = 30 * time.Minute
+
+var (
+	// ErrInteractionNotFound 交互不存在或已过期
+	ErrInteractionNotFound = errors.New("interaction not found or expired")
+	// ErrVerificationFailed 插件验证未通过
+	ErrVerificationFailed = errors.New("plugin verification failed")
+)
+
+// HostedLoginService 托管登录服务接口
+// 浏览器直接访问授权端点时，由本服务渲染的登录、插件验证与授权同意页面完成用户交互
+type HostedLoginService interface {
+	// StartInteraction 保存需要用户交互的授权请求，并记录客户端所在应用的品牌
+	StartInteraction(ctx context.Context, req *model.AuthorizationRequest) (*model.Interaction, error)
+
+	// GetInteraction 获取交互
+	GetInteraction(ctx context.Context, id string) (*model.Interaction, error)
+
+	// Login 在交互中验证用户名密码，需要插件验证时记录验证会话并返回ErrPluginRequired
+	Login(ctx context.Context, interaction *model.Interaction, req *model.LoginRequest) (*model.ExtendedLoginResponse, error)
+
+	// VerificationPlugins 获取登录需要完成的验证插件
+	VerificationPlugins(interaction *model.Interaction) []model.HostedVerificationPlugin
+
+	// ExecutePlugin 执行登录所需的验证插件操作
+	// send操作发送验证码，返回nil；verify操作通过后继续登录，仍有未完成的插件时返回ErrPluginRequired
+	ExecutePlugin(ctx context.Context, interaction *model.Interaction, req *model.HostedVerifyRequest, loginReq *model.LoginRequest) (*model.ExtendedLoginResponse, error)
+
+	// FinishInteraction 授权完成或终止后删除交互
+	FinishInteraction(ctx context.Context, id string) error
+}
+
+// hostedLoginService 托管登录服务实现
+type hostedLoginService struct {
+	redis           *redis.Client
+	clientRepo      repository.OAuthClientRepository
+	appRepo         repository.AppRepository
+	userRepo        repository.UserRepository
+	authService     AuthService
+	verificationSvc VerificationService
+	pluginManager   types.Manager
+}
+
+// NewHostedLoginService 创建托管登录服务实例
+func NewHostedLoginService(
+	redisClient *redis.Client,
+	clientRepo repository.OAuthClientRepository,
+	appRepo repository.AppRepository,
+	userRepo repository.UserRepository,
+	authService AuthService,
+	verificationSvc VerificationService,
+	pluginManager types.Manager,
+) HostedLoginService {
+	return &hostedLoginService{
+		redis:           redisClient,
+		clientRepo:      clientRepo,
+		appRepo:         appRepo,
+		userRepo:        userRepo,
+		authService:     authService,
+		verificationSvc: verificationSvc,
+		pluginManager:   pluginManager,
+	}
+}
+
+// StartInteraction 保存需要用户交互的授权请求
+func (s *hostedLoginService) StartInteraction(ctx context.Context, req *model.AuthorizationRequest) (*model.Interaction, error) {
+	client, err := s.clientRepo.GetByClientID(ctx, req.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	if client == nil || !client.Status {
+		return nil, ErrInvalidClient
+	}
+	app, err := s.appRepo.GetByID(ctx, client.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app: %w", err)
+	}
+	if app == nil || app.Status != model.AppStatusEnabled {
+		return nil, ErrInvalidClient
+	}
+
+	id, err := generateSecureToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate interaction id: %w", err)
+	}
+	csrfToken, err := generateSecureToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate csrf token: %w", err)
+	}
+
+	interaction := &model.Interaction{
+		ID:         id,
+		AppID:      app.ID,
+		AppName:    app.Name,
+		ClientName: client.Name,
+		Branding:   app.Branding,
+		Request:    *req,
+		CSRFToken:  csrfToken,
+		ExpiresAt:  time.Now().Add(interactionExpiry),
+	}
+	if err := s.save(ctx, interaction); err != nil {
+		return nil, err
+	}
+	return interaction, nil
+}
+
+// GetInteraction 获取交互
+func (s *hostedLoginService) GetInteraction(ctx context.Context, id string) (*model.Interaction, error) {
+	if id == "" {
+		return nil, ErrInteractionNotFound
+	}
+	data, err := s.redis.Get(ctx, s.interactionKey(id))
+	if err == goredis.Nil {
+		return nil, ErrInteractionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interaction: %w", err)
+	}
+
+	var interaction model.Interaction
+	if err := json.Unmarshal([]byte(data), &interaction); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal interaction: %w", err)
+	}
+	return &interaction, nil
+}
+
+// Login 在交互中验证用户名密码
+func (s *hostedLoginService) Login(ctx context.Context, interaction *model.Interaction, req *model.LoginRequest) (*model.ExtendedLoginResponse, error) {
+	// 授权请求要求的认证强度决定登录需要完成的验证插件
+	req.ACRValues = interaction.Request.ACRValues
+
+	resp, err := s.authService.Login(ctx, interaction.AppID, req)
+	if err == ErrPluginRequired {
+		interaction.UserID = resp.User.ID
+		interaction.VerificationSessionID = resp.SessionID
+		interaction.Plugins = resp.Plugins
+		if err := s.save(ctx, interaction); err != nil {
+			return nil, err
+		}
+	}
+	return resp, err
+}
+
+// VerificationPlugins 获取登录需要完成的验证插件
+func (s *hostedLoginService) VerificationPlugins(interaction *model.Interaction) []model.HostedVerificationPlugin {
+	plugins := make([]model.HostedVerificationPlugin, 0, len(interaction.Plugins))
+	for _, requirement := range interaction.Plugins {
+		plugin := model.HostedVerificationPlugin{
+			Name:      requirement.Name,
+			Completed: requirement.Status == model.PluginStatusCompleted,
+		}
+		// 插件声明了send操作时，页面提供发送验证码的按钮
+		if p, ok := s.pluginManager.GetPlugin(interaction.AppID, requirement.Name); ok {
+			for _, operation := range p.GetMetadata().Operations {
+				if operation.Name == "send" {
+					plugin.CanSend = true
+				}
+			}
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins
+}
+
+// ExecutePlugin 执行登录所需的验证插件操作
+func (s *hostedLoginService) ExecutePlugin(ctx context.Context, interaction *model.Interaction, req *model.HostedVerifyRequest, loginReq *model.LoginRequest) (*model.ExtendedLoginResponse, error) {
+	if interaction.VerificationSessionID == "" {
+		return nil, ErrInteractionNotFound
+	}
+	if !requiresPlugin(interaction.Plugins, req.Plugin) {
+		log.Printf("Plugin %s is not required by interaction", req.Plugin)
+		return nil, ErrInvalidRequest
+	}
+
+	params := map[string]interface{}{
+		"operation":  req.Operation,
+		"session_id": interaction.VerificationSessionID,
+		"user_id":    interaction.UserID,
+	}
+	if req.Operation == "verify" {
+		params["code"] = req.Code
+	}
+	// 邮箱验证码只发送到用户登记的邮箱
+	user, err := s.userRepo.GetByID(ctx, interaction.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user != nil && user.Email != "" {
+		params["email"] = user.Email
+	}
+
+	if err := s.pluginManager.ExecutePlugin(ctx, interaction.AppID, req.Plugin, params); err != nil {
+		log.Printf("Plugin %s %s failed: %v", req.Plugin, req.Operation, err)
+		return nil, ErrVerificationFailed
+	}
+	if req.Operation != "verify" {
+		return nil, nil
+	}
+
+	if err := s.verificationSvc.UpdatePluginStatusBySession(ctx, interaction.VerificationSessionID, req.Plugin, model.PluginStatusCompleted); err != nil {
+		return nil, fmt.Errorf("failed to update plugin status: %w", err)
+	}
+
+	resp, err := s.authService.ResumeLogin(ctx, interaction.AppID, interaction.VerificationSessionID, loginReq)
+	if err == ErrPluginRequired {
+		interaction.Plugins = resp.Plugins
+		if err := s.save(ctx, interaction); err != nil {
+			return nil, err
+		}
+	}
+	return resp, err
+}
+
+// FinishInteraction 删除交互
+func (s *hostedLoginService) FinishInteraction(ctx context.Context, id string) error {
+	return s.redis.Del(ctx, s.interactionKey(id))
+}
+
+// save 保存交互，有效期从创建时开始计算
+func (s *hostedLoginService) save(ctx context.Context, interaction *model.Interaction) error {
+	ttl := time.Until(interaction.ExpiresAt)
+	if ttl <= 0 {
+		return ErrInteractionNotFound
+	}
+	data, err := json.Marshal(interaction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal interaction: %w", err)
+	}
+	if err := s.redis.Set(ctx, s.interactionKey(interaction.ID), string(data), ttl); err != nil {
+		return fmt.Errorf("failed to store interaction: %w", err)
+	}
+	return nil
+}
+
+// interactionKey 构建交互键
+func (s *hostedLoginService) interactionKey(id string) string {
+	return fmt.Sprintf("interaction:%s", id)
+}
+
+// requiresPlugin 判断登录是否需要该插件
+func requiresPlugin(plugins []model.PluginRequirement, name string) bool {
+	for _, plugin := range plugins {
+		if plugin.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"lauth/internal/model"
+	"lauth/internal/repository"
+)
+
+// backchannelLogoutTimeout 发送后端通道登出通知的超时时间
+const backchannelLogoutTimeout = 5 * time.Second
+
+// LogoutService 登出服务接口(OIDC RP-Initiated/Front-Channel/Back-Channel Logout)
+type LogoutService interface {
+	// EndSession 结束用户的登录会话：吊销会话中颁发的令牌，向客户端发送后端通道登出通知，
+	// 并返回需要由浏览器加载的前端通道登出URL
+	EndSession(ctx context.Context, userID, sessionID string) ([]string, error)
+
+	// RPInitiatedLogout 处理客户端发起的登出请求，authCtx为nil表示用户当前未登录
+	// 需要结束会话但请求未携带属于该会话的有效id_token_hint且用户未确认时返回ErrLogoutConfirmationRequired
+	RPInitiatedLogout(ctx context.Context, authCtx *model.AuthContext, req *model.EndSessionRequest) (*model.EndSessionResponse, error)
+}
+
+// logoutService 登出服务实现
+type logoutService struct {
+	clientRepo   repository.OAuthClientRepository
+	tokenService TokenService
+	oidcService  OIDCService
+	issuer       string
+	httpClient   *http.Client
+}
+
+// NewLogoutService 创建登出服务实例
+func NewLogoutService(
+	clientRepo repository.OAuthClientRepository,
+	tokenService TokenService,
+	oidcService OIDCService,
+	issuer string,
+) LogoutService {
+	return &logoutService{
+		clientRepo:   clientRepo,
+		tokenService: tokenService,
+		oidcService:  oidcService,
+		issuer:       issuer,
+		httpClient:   &http.Client{Timeout: backchannelLogoutTimeout},
+	}
+}
+
+// EndSession 结束用户的登录会话
+func (s *logoutService) EndSession(ctx context.Context, userID, sessionID string) ([]string, error) {
+	clientIDs, err := s.tokenService.RevokeSession(ctx, sessionID)
+	if err != nil {
+		log.Printf("Failed to revoke session %s: %v", sessionID, err)
+		return nil, err
+	}
+
+	frontchannelURIs := []string{}
+	for _, clientID := range clientIDs {
+		client, err := s.clientRepo.GetByClientID(ctx, clientID)
+		if err != nil {
+			log.Printf("Failed to get client %s for logout: %v", clientID, err)
+			continue
+		}
+		if client == nil {
+			continue
+		}
+
+		if client.BackchannelLogoutURI != "" {
+			logoutToken, err := s.oidcService.GenerateLogoutToken(ctx, client, userID, sessionID)
+			if err != nil {
+				log.Printf("Failed to generate logout token for client %s: %v", clientID, err)
+			} else {
+				// 通知在后台发送，客户端不可达不影响用户登出
+				go s.sendBackchannelLogout(client.ClientID, client.BackchannelLogoutURI, logoutToken)
+			}
+		}
+
+		if client.FrontchannelLogoutURI != "" {
+			frontchannelURI, err := s.buildFrontchannelLogoutURI(client, sessionID)
+			if err != nil {
+				log.Printf("Invalid frontchannel logout URI for client %s: %v", clientID, err)
+				continue
+			}
+			frontchannelURIs = append(frontchannelURIs, frontchannelURI)
+		}
+	}
+
+	log.Printf("Ended session %s of user %s, notified %d clients", sessionID, userID, len(clientIDs))
+	return frontchannelURIs, nil
+}
+
+// RPInitiatedLogout 处理客户端发起的登出请求
+func (s *logoutService) RPInitiatedLogout(ctx context.Context, authCtx *model.AuthContext, req *model.EndSessionRequest) (*model.EndSessionResponse, error) {
+	clientID := req.ClientID
+	var hint *model.OIDCClaims
+	if req.IDTokenHint != "" {
+		claims, err := s.oidcService.ParseIDTokenHint(ctx, req.IDTokenHint)
+		if err != nil {
+			log.Printf("Invalid id_token_hint: %v", err)
+			return nil, ErrInvalidRequest
+		}
+		if clientID != "" && clientID != claims.Audience {
+			log.Printf("client_id %s does not match id_token_hint audience %s", clientID, claims.Audience)
+			return nil, ErrInvalidRequest
+		}
+		clientID = claims.Audience
+		hint = claims
+	}
+
+	// 登出后重定向URI必须能确定所属客户端并已注册
+	var client *model.OAuthClient
+	if clientID != "" {
+		var err error
+		client, err = s.clientRepo.GetByClientID(ctx, clientID)
+		if err != nil {
+			return nil, err
+		}
+		if client == nil {
+			return nil, ErrInvalidClient
+		}
+	}
+	if req.PostLogoutRedirectURI != "" {
+		if client == nil || !containsString(client.PostLogoutRedirectURIs, req.PostLogoutRedirectURI) {
+			log.Printf("Unregistered post_logout_redirect_uri: %s", req.PostLogoutRedirectURI)
+			return nil, ErrInvalidRedirectURI
+		}
+	}
+
+	// 优先结束当前登录会话，未登录时结束id_token_hint所在的会话
+	userID, sessionID := "", ""
+	switch {
+	case authCtx != nil:
+		if hint != nil && hint.Subject != authCtx.UserID {
+			log.Printf("id_token_hint subject does not match current user %s", authCtx.UserID)
+			return nil, ErrInvalidRequest
+		}
+		userID, sessionID = authCtx.UserID, authCtx.SessionID
+	case hint != nil:
+		userID, sessionID = hint.Subject, hint.SID
+	}
+
+	// 登出请求可以由任意页面发起，结束会话前需要用户确认，避免第三方诱导浏览器登出(登出CSRF)；
+	// 只有未过期且属于该会话的id_token_hint能证明请求来自用户正在使用的客户端(OIDC RP-Initiated Logout 2)
+	if sessionID != "" && !req.Confirmed && !hintMatchesSession(hint, sessionID) {
+		log.Printf("Logout of session %s requires user confirmation", sessionID)
+		return nil, ErrLogoutConfirmationRequired
+	}
+
+	resp := &model.EndSessionResponse{FrontchannelLogoutURIs: []string{}}
+	if sessionID != "" {
+		frontchannelURIs, err := s.EndSession(ctx, userID, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		resp.FrontchannelLogoutURIs = frontchannelURIs
+	}
+
+	if req.PostLogoutRedirectURI != "" {
+		redirectURL, err := url.Parse(req.PostLogoutRedirectURI)
+		if err != nil {
+			return nil, err
+		}
+		if req.State != "" {
+			query := redirectURL.Query()
+			query.Set("state", req.State)
+			redirectURL.RawQuery = query.Encode()
+		}
+		resp.RedirectURL = redirectURL.String()
+	}
+
+	return resp, nil
+}
+
+// hintMatchesSession 判断id_token_hint是否未过期且颁发于要结束的会话
+func hintMatchesSession(hint *model.OIDCClaims, sessionID string) bool {
+	return hint != nil && hint.SID == sessionID && time.Now().Unix() < hint.ExpiresAt
+}
+
+// buildFrontchannelLogoutURI 构建前端通道登出URL，客户端要求时附加iss与sid
+func (s *logoutService) buildFrontchannelLogoutURI(client *model.OAuthClient, sessionID string) (string, error) {
+	logoutURL, err := url.Parse(client.FrontchannelLogoutURI)
+	if err != nil {
+		return "", err
+	}
+	if client.FrontchannelLogoutSessionRequired {
+		query := logoutURL.Query()
+		query.Set("iss", s.issuer)
+		query.Set("sid", sessionID)
+		logoutURL.RawQuery = query.Encode()
+	}
+	return logoutURL.String(), nil
+}
+
+// sendBackchannelLogout 向客户端的后端通道登出URI发送登出令牌
+func (s *logoutService) sendBackchannelLogout(clientID, logoutURI, logoutToken string) {
+	resp, err := s.httpClient.PostForm(logoutURI, url.Values{"logout_token": {logoutToken}})
+	if err != nil {
+		log.Printf("Failed to send backchannel logout to client %s: %v", clientID, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		log.Printf("Backchannel logout to client %s returned status %d", clientID, resp.StatusCode)
+	}
+}
+
+// containsString 判断字符串切片是否包含指定值
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -3,13 +3,20 @@ package service
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"io"
 	"log"
+	"net/http"
+	"net/url"
 	"time"
 
 	"lauth/internal/model"
 	"lauth/internal/repository"
+	"lauth/pkg/crypto"
 
 	"github.com/google/uuid"
 )
@@ -19,8 +26,26 @@ var (
 	ErrClientExists = errors.New("client already exists")
 	// ErrClientNotFound 客户端不存在
 	ErrClientNotFound = errors.New("client not found")
+	// ErrInvalidClientMetadata 客户端元数据无效
+	ErrInvalidClientMetadata = errors.New("invalid client metadata")
+	// ErrInvalidRegistrationToken 无效的初始访问令牌或注册访问令牌
+	ErrInvalidRegistrationToken = errors.New("invalid registration token")
 )
 
+// sectorIdentifierMaxSize sector_identifier_uri响应的最大长度
+const sectorIdentifierMaxSize = 64 << 10
+
+// supportedGrantTypes 客户端可配置的授权类型
+var supportedGrantTypes = map[string]bool{
+	string(model.AuthorizationCodeGrant): true,
+	string(model.ClientCredentials):      true,
+	string(model.Password):               true,
+	string(model.Implicit):               true,
+	string(model.RefreshTokenGrant):      true,
+	string(model.DeviceCodeGrant):        true,
+	string(model.TokenExchangeGrant):     true,
+}
+
 // OAuthClientService OAuth客户端服务接口
 type OAuthClientService interface {
 	// CreateClient 创建OAuth客户端
@@ -49,22 +74,51 @@ type OAuthClientService interface {
 
 	// ListClientSecrets 获取客户端秘钥列表
 	ListClientSecrets(ctx context.Context, clientID string) ([]*model.ClientSecretResponse, error)
+
+	// CreateInitialAccessToken 为应用创建动态注册使用的初始访问令牌
+	CreateInitialAccessToken(ctx context.Context, appID string, req *model.CreateInitialAccessTokenRequest) (*model.InitialAccessTokenResponse, error)
+
+	// ListInitialAccessTokens 获取应用的初始访问令牌列表
+	ListInitialAccessTokens(ctx context.Context, appID string) ([]*model.InitialAccessTokenResponse, error)
+
+	// DeleteInitialAccessToken 删除初始访问令牌
+	DeleteInitialAccessToken(ctx context.Context, appID, id string) error
+
+	// RegisterClient 动态注册客户端(RFC 7591)
+	RegisterClient(ctx context.Context, initialAccessToken string, req *model.ClientRegistrationRequest) (*model.ClientRegistrationResponse, error)
+
+	// GetClientRegistration 读取客户端注册信息(RFC 7592)
+	GetClientRegistration(ctx context.Context, clientID, registrationToken string) (*model.ClientRegistrationResponse, error)
+
+	// UpdateClientRegistration 更新客户端注册信息(RFC 7592)
+	UpdateClientRegistration(ctx context.Context, clientID, registrationToken string, req *model.ClientRegistrationRequest) (*model.ClientRegistrationResponse, error)
+
+	// DeleteClientRegistration 删除客户端注册(RFC 7592)
+	DeleteClientRegistration(ctx context.Context, clientID, registrationToken string) error
 }
 
 // oauthClientService OAuth客户端服务实现
 type oauthClientService struct {
 	clientRepo repository.OAuthClientRepository
 	secretRepo repository.OAuthClientSecretRepository
+	iatRepo    repository.InitialAccessTokenRepository
+	issuer     string
+	httpClient *http.Client
 }
 
 // NewOAuthClientService 创建OAuth客户端服务实例
 func NewOAuthClientService(
 	clientRepo repository.OAuthClientRepository,
 	secretRepo repository.OAuthClientSecretRepository,
+	iatRepo repository.InitialAccessTokenRepository,
+	issuer string,
 ) OAuthClientService {
 	return &oauthClientService{
 		clientRepo: clientRepo,
 		secretRepo: secretRepo,
+		iatRepo:    iatRepo,
+		issuer:     issuer,
+		httpClient: newExternalHTTPClient(5 * time.Second),
 	}
 }
 
@@ -87,6 +141,225 @@ func generateClientCredentials() (clientID, clientSecret string, err error) {
 	return clientID, clientSecret, nil
 }
 
+// generateSecureToken 生成URL安全的随机令牌
+func generateSecureToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken 计算令牌的SHA-256摘要，用于持久化不可逆的令牌
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// validateClientMetadata 验证客户端元数据
+// 管理接口与动态注册共用，保证两种方式创建的客户端满足相同的约束
+func validateClientMetadata(client *model.OAuthClient) error {
+	if client.Type != model.Confidential && client.Type != model.Public {
+		log.Printf("Invalid client type: %s", client.Type)
+		return ErrInvalidClientMetadata
+	}
+	if len(client.GrantTypes) == 0 || len(client.Scopes) == 0 {
+		log.Printf("Client must have at least one grant type and scope")
+		return ErrInvalidClientMetadata
+	}
+
+	usesRedirect := false
+	for _, grantType := range client.GrantTypes {
+		if !supportedGrantTypes[grantType] {
+			log.Printf("Unsupported grant type: %s", grantType)
+			return ErrInvalidClientMetadata
+		}
+		switch grantType {
+		case string(model.AuthorizationCodeGrant), string(model.Implicit):
+			usesRedirect = true
+		case string(model.ClientCredentials), string(model.TokenExchangeGrant):
+			// 公开客户端无法保存密钥，不能代表自身获取令牌
+			if client.Type == model.Public {
+				log.Printf("Public client cannot use %s grant", grantType)
+				return ErrInvalidClientMetadata
+			}
+		}
+	}
+
+	if usesRedirect && len(client.RedirectURIs) == 0 && len(client.RedirectURIPatterns) == 0 {
+		log.Printf("Redirect URIs are required for redirect-based grants")
+		return ErrInvalidRedirectURI
+	}
+	for _, redirectURI := range client.RedirectURIs {
+		if err := validateRegisteredRedirectURI(redirectURI); err != nil {
+			return err
+		}
+	}
+	for _, pattern := range client.RedirectURIPatterns {
+		if err := validateRedirectURIPattern(pattern); err != nil {
+			return err
+		}
+	}
+
+	for _, redirectURI := range client.PostLogoutRedirectURIs {
+		u, err := url.Parse(redirectURI)
+		if err != nil || u.Scheme == "" || u.Fragment != "" {
+			log.Printf("Invalid post logout redirect URI: %s", redirectURI)
+			return ErrInvalidRedirectURI
+		}
+	}
+
+	// 登出通知由服务端或浏览器直接访问，必须是绝对的http(s) URI
+	for _, logoutURI := range []string{client.FrontchannelLogoutURI, client.BackchannelLogoutURI} {
+		if logoutURI == "" {
+			continue
+		}
+		u, err := url.Parse(logoutURI)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Fragment != "" {
+			log.Printf("Invalid logout URI: %s", logoutURI)
+			return ErrInvalidClientMetadata
+		}
+	}
+
+	for _, audience := range client.TokenExchangeAudiences {
+		if audience == "" {
+			log.Printf("Token exchange audience cannot be empty")
+			return ErrInvalidClientMetadata
+		}
+	}
+	for _, detailType := range client.AuthorizationDetailsTypes {
+		if detailType == "" {
+			log.Printf("Authorization details type cannot be empty")
+			return ErrInvalidClientMetadata
+		}
+	}
+
+	// 客户端公钥只能通过jwks_uri或jwks之一提供(RFC 7591 2)
+	if client.JWKSURI != "" && client.JWKS != "" {
+		log.Printf("jwks_uri and jwks cannot both be set")
+		return ErrInvalidClientMetadata
+	}
+	if client.JWKSURI != "" {
+		u, err := url.Parse(client.JWKSURI)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			log.Printf("Invalid jwks_uri: %s", client.JWKSURI)
+			return ErrInvalidClientMetadata
+		}
+	}
+	if client.JWKS != "" {
+		if _, err := crypto.ParseJWKSet([]byte(client.JWKS)); err != nil {
+			log.Printf("Invalid jwks: %v", err)
+			return ErrInvalidClientMetadata
+		}
+	}
+
+	// 认证方式需与客户端类型一致，private_key_jwt需要登记公钥
+	switch client.TokenEndpointAuthMethod {
+	case "":
+	case model.TokenEndpointAuthNone:
+		if client.Type != model.Public {
+			log.Printf("Confidential client cannot use token_endpoint_auth_method none")
+			return ErrInvalidClientMetadata
+		}
+	case model.TokenEndpointAuthClientSecretBasic, model.TokenEndpointAuthClientSecretPost,
+		model.TokenEndpointAuthClientSecretJWT, model.TokenEndpointAuthPrivateKeyJWT:
+		if client.Type != model.Confidential {
+			log.Printf("Public client cannot use token_endpoint_auth_method %s", client.TokenEndpointAuthMethod)
+			return ErrInvalidClientMetadata
+		}
+		if client.TokenEndpointAuthMethod == model.TokenEndpointAuthPrivateKeyJWT && client.JWKSURI == "" && client.JWKS == "" {
+			log.Printf("private_key_jwt requires jwks or jwks_uri")
+			return ErrInvalidClientMetadata
+		}
+	default:
+		log.Printf("Unsupported token_endpoint_auth_method: %s", client.TokenEndpointAuthMethod)
+		return ErrInvalidClientMetadata
+	}
+
+	if client.RequestObjectSigningAlg != "" && !containsString(supportedClientSigningAlgs, client.RequestObjectSigningAlg) {
+		log.Printf("Unsupported request_object_signing_alg: %s", client.RequestObjectSigningAlg)
+		return ErrInvalidClientMetadata
+	}
+
+	switch client.SubjectType {
+	case "", model.SubjectTypePublic, model.SubjectTypePairwise:
+	default:
+		log.Printf("Unsupported subject_type: %s", client.SubjectType)
+		return ErrInvalidClientMetadata
+	}
+	if client.SectorIdentifierURI != "" {
+		u, err := url.Parse(client.SectorIdentifierURI)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			log.Printf("Invalid sector_identifier_uri: %s", client.SectorIdentifierURI)
+			return ErrInvalidClientMetadata
+		}
+	}
+	// 重定向URI跨多个主机或使用通配模式时无法确定扇区，pairwise客户端必须提供sector_identifier_uri
+	multipleHosts := len(redirectURIHosts(client.RedirectURIs)) > 1 || len(client.RedirectURIPatterns) > 0
+	if client.SubjectType == model.SubjectTypePairwise && client.SectorIdentifierURI == "" && multipleHosts {
+		log.Printf("sector_identifier_uri is required for pairwise clients with multiple redirect hosts")
+		return ErrInvalidClientMetadata
+	}
+
+	return nil
+}
+
+// verifySectorIdentifier 获取sector_identifier_uri发布的重定向URI列表
+// 客户端的所有重定向URI都必须包含在其中(OIDC Registration 5)，防止客户端冒用其他扇区关联用户
+func (s *oauthClientService) verifySectorIdentifier(ctx context.Context, client *model.OAuthClient) error {
+	if client.SectorIdentifierURI == "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.SectorIdentifierURI, nil)
+	if err != nil {
+		return ErrInvalidClientMetadata
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		log.Printf("Failed to fetch sector_identifier_uri %s: %v", client.SectorIdentifierURI, err)
+		return ErrInvalidClientMetadata
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Unexpected status fetching sector_identifier_uri %s: %d", client.SectorIdentifierURI, resp.StatusCode)
+		return ErrInvalidClientMetadata
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, sectorIdentifierMaxSize))
+	if err != nil {
+		return ErrInvalidClientMetadata
+	}
+	var redirectURIs []string
+	if err := json.Unmarshal(data, &redirectURIs); err != nil {
+		log.Printf("Invalid sector_identifier_uri document: %v", err)
+		return ErrInvalidClientMetadata
+	}
+	for _, redirectURI := range client.RedirectURIs {
+		if !containsString(redirectURIs, redirectURI) {
+			log.Printf("Redirect URI %s is not listed in sector_identifier_uri", redirectURI)
+			return ErrInvalidClientMetadata
+		}
+	}
+	return nil
+}
+
+// jwksString 将请求中的JWK Set转换为持久化的字符串，null表示未设置
+func jwksString(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
+
+// jwksRawMessage 将持久化的JWK Set转换为响应中的JSON
+func jwksRawMessage(jwks string) json.RawMessage {
+	if jwks == "" {
+		return nil
+	}
+	return json.RawMessage(jwks)
+}
+
 // toOAuthClientResponse 转换为OAuth客户端响应
 func toOAuthClientResponse(client *model.OAuthClient) *model.OAuthClientResponse {
 	return &model.OAuthClientResponse{
@@ -98,9 +371,30 @@ func toOAuthClientResponse(client *model.OAuthClient) *model.OAuthClientResponse
 		GrantTypes:   client.GrantTypes,
 		RedirectURIs: client.RedirectURIs,
 		Scopes:       client.Scopes,
+		RequirePKCE:  client.RequirePKCE,
 		Status:       client.Status,
 		CreatedAt:    client.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:    client.UpdatedAt.Format(time.RFC3339),
+
+		RedirectURIPatterns: client.RedirectURIPatterns,
+
+		RequirePushedAuthorizationRequests: client.RequirePushedAuthorizationRequests,
+
+		TokenEndpointAuthMethod: client.TokenEndpointAuthMethod,
+		JWKSURI:                 client.JWKSURI,
+		JWKS:                    jwksRawMessage(client.JWKS),
+		RequestObjectSigningAlg: client.RequestObjectSigningAlg,
+		TokenExchangeAudiences:  client.TokenExchangeAudiences,
+		SubjectType:             client.SubjectType,
+		SectorIdentifierURI:     client.SectorIdentifierURI,
+
+		AuthorizationDetailsTypes: client.AuthorizationDetailsTypes,
+
+		PostLogoutRedirectURIs:            client.PostLogoutRedirectURIs,
+		FrontchannelLogoutURI:             client.FrontchannelLogoutURI,
+		FrontchannelLogoutSessionRequired: client.FrontchannelLogoutSessionRequired,
+		BackchannelLogoutURI:              client.BackchannelLogoutURI,
+		BackchannelLogoutSessionRequired:  client.BackchannelLogoutSessionRequired,
 	}
 }
 
@@ -141,9 +435,37 @@ func (s *oauthClientService) CreateClient(ctx context.Context, appID string, req
 		GrantTypes:   req.GrantTypes,
 		RedirectURIs: req.RedirectURIs,
 		Scopes:       req.Scopes,
+		RequirePKCE:  req.RequirePKCE,
 		Status:       true,
 		CreatedAt:    now,
 		UpdatedAt:    now,
+
+		RedirectURIPatterns: req.RedirectURIPatterns,
+
+		RequirePushedAuthorizationRequests: req.RequirePushedAuthorizationRequests,
+
+		TokenEndpointAuthMethod: req.TokenEndpointAuthMethod,
+		JWKSURI:                 req.JWKSURI,
+		JWKS:                    jwksString(req.JWKS),
+		RequestObjectSigningAlg: req.RequestObjectSigningAlg,
+		TokenExchangeAudiences:  req.TokenExchangeAudiences,
+		SubjectType:             req.SubjectType,
+		SectorIdentifierURI:     req.SectorIdentifierURI,
+
+		AuthorizationDetailsTypes: req.AuthorizationDetailsTypes,
+
+		PostLogoutRedirectURIs:            req.PostLogoutRedirectURIs,
+		FrontchannelLogoutURI:             req.FrontchannelLogoutURI,
+		FrontchannelLogoutSessionRequired: req.FrontchannelLogoutSessionRequired,
+		BackchannelLogoutURI:              req.BackchannelLogoutURI,
+		BackchannelLogoutSessionRequired:  req.BackchannelLogoutSessionRequired,
+	}
+
+	if err := validateClientMetadata(client); err != nil {
+		return nil, err
+	}
+	if err := s.verifySectorIdentifier(ctx, client); err != nil {
+		return nil, err
 	}
 
 	if err := s.clientRepo.Create(ctx, client); err != nil {
@@ -174,14 +496,69 @@ func (s *oauthClientService) UpdateClient(ctx context.Context, id string, req *m
 	if len(req.RedirectURIs) > 0 {
 		client.RedirectURIs = req.RedirectURIs
 	}
+	if req.RedirectURIPatterns != nil {
+		client.RedirectURIPatterns = req.RedirectURIPatterns
+	}
 	if len(req.Scopes) > 0 {
 		client.Scopes = req.Scopes
 	}
+	if req.RequirePKCE != nil {
+		client.RequirePKCE = *req.RequirePKCE
+	}
 	if req.Status != nil {
 		client.Status = *req.Status
 	}
+	if req.RequirePushedAuthorizationRequests != nil {
+		client.RequirePushedAuthorizationRequests = *req.RequirePushedAuthorizationRequests
+	}
+	if req.TokenEndpointAuthMethod != nil {
+		client.TokenEndpointAuthMethod = *req.TokenEndpointAuthMethod
+	}
+	if req.JWKSURI != nil {
+		client.JWKSURI = *req.JWKSURI
+	}
+	if req.JWKS != nil {
+		client.JWKS = jwksString(req.JWKS)
+	}
+	if req.RequestObjectSigningAlg != nil {
+		client.RequestObjectSigningAlg = *req.RequestObjectSigningAlg
+	}
+	if req.TokenExchangeAudiences != nil {
+		client.TokenExchangeAudiences = req.TokenExchangeAudiences
+	}
+	if req.AuthorizationDetailsTypes != nil {
+		client.AuthorizationDetailsTypes = req.AuthorizationDetailsTypes
+	}
+	if req.SubjectType != nil {
+		client.SubjectType = *req.SubjectType
+	}
+	if req.SectorIdentifierURI != nil {
+		client.SectorIdentifierURI = *req.SectorIdentifierURI
+	}
+	if req.PostLogoutRedirectURIs != nil {
+		client.PostLogoutRedirectURIs = req.PostLogoutRedirectURIs
+	}
+	if req.FrontchannelLogoutURI != nil {
+		client.FrontchannelLogoutURI = *req.FrontchannelLogoutURI
+	}
+	if req.FrontchannelLogoutSessionRequired != nil {
+		client.FrontchannelLogoutSessionRequired = *req.FrontchannelLogoutSessionRequired
+	}
+	if req.BackchannelLogoutURI != nil {
+		client.BackchannelLogoutURI = *req.BackchannelLogoutURI
+	}
+	if req.BackchannelLogoutSessionRequired != nil {
+		client.BackchannelLogoutSessionRequired = *req.BackchannelLogoutSessionRequired
+	}
 	client.UpdatedAt = time.Now()
 
+	if err := validateClientMetadata(client); err != nil {
+		return nil, err
+	}
+	if err := s.verifySectorIdentifier(ctx, client); err != nil {
+		return nil, err
+	}
+
 	if err := s.clientRepo.Update(ctx, client); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"strings"
+	"time"
+
+	"lauth/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// registeredClientSecretExpiry 动态注册客户端密钥的有效期
+const registeredClientSecretExpiry = 365 * 24 * time.Hour
+
+// CreateInitialAccessToken 为应用创建动态注册使用的初始访问令牌
+func (s *oauthClientService) CreateInitialAccessToken(ctx context.Context, appID string, req *model.CreateInitialAccessTokenRequest) (*model.InitialAccessTokenResponse, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	iat := &model.InitialAccessToken{
+		AppID:       appID,
+		TokenHash:   hashToken(token),
+		Description: req.Description,
+		MaxUses:     req.MaxUses,
+		ExpiresAt:   now.Add(time.Duration(req.ExpiresIn) * time.Second),
+		CreatedAt:   now,
+	}
+	if err := s.iatRepo.Create(ctx, iat); err != nil {
+		return nil, err
+	}
+
+	resp := toInitialAccessTokenResponse(iat)
+	resp.Token = token // 明文令牌只在创建时返回一次
+	return resp, nil
+}
+
+// ListInitialAccessTokens 获取应用的初始访问令牌列表
+func (s *oauthClientService) ListInitialAccessTokens(ctx context.Context, appID string) ([]*model.InitialAccessTokenResponse, error) {
+	tokens, err := s.iatRepo.ListByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []*model.InitialAccessTokenResponse
+	for _, token := range tokens {
+		responses = append(responses, toInitialAccessTokenResponse(token))
+	}
+	return responses, nil
+}
+
+// DeleteInitialAccessToken 删除初始访问令牌
+func (s *oauthClientService) DeleteInitialAccessToken(ctx context.Context, appID, id string) error {
+	return s.iatRepo.Delete(ctx, appID, id)
+}
+
+// RegisterClient 动态注册客户端(RFC 7591)
+func (s *oauthClientService) RegisterClient(ctx context.Context, initialAccessToken string, req *model.ClientRegistrationRequest) (*model.ClientRegistrationResponse, error) {
+	// 初始访问令牌决定新客户端所属的应用
+	iat, err := s.iatRepo.GetByTokenHash(ctx, hashToken(initialAccessToken))
+	if err != nil {
+		return nil, err
+	}
+	if iat == nil || time.Now().After(iat.ExpiresAt) {
+		log.Printf("Invalid or expired initial access token")
+		return nil, ErrInvalidRegistrationToken
+	}
+
+	now := time.Now()
+	client := &model.OAuthClient{
+		ID:        uuid.New().String(),
+		AppID:     iat.AppID,
+		ClientID:  uuid.New().String(),
+		Status:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := applyRegistrationMetadata(client, req); err != nil {
+		return nil, err
+	}
+	if err := s.verifySectorIdentifier(ctx, client); err != nil {
+		return nil, err
+	}
+
+	// 校验通过后再计入使用次数，元数据错误不消耗令牌
+	ok, err := s.iatRepo.IncrementUsage(ctx, iat.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("Initial access token %s has reached its usage limit", iat.ID)
+		return nil, ErrInvalidRegistrationToken
+	}
+
+	registrationToken, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+	client.RegistrationAccessTokenHash = hashToken(registrationToken)
+
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, err
+	}
+
+	resp := s.toClientRegistrationResponse(client)
+	resp.RegistrationAccessToken = registrationToken
+
+	// 机密客户端同时签发客户端密钥
+	if client.Type == model.Confidential {
+		_, secret, err := generateClientCredentials()
+		if err != nil {
+			return nil, err
+		}
+		clientSecret := &model.OAuthClientSecret{
+			ClientID:    client.ClientID,
+			Secret:      secret,
+			Description: "dynamic registration",
+			LastUsedAt:  now,
+			ExpiresAt:   now.Add(registeredClientSecretExpiry),
+			CreatedAt:   now,
+		}
+		if err := s.secretRepo.Create(ctx, clientSecret); err != nil {
+			return nil, err
+		}
+		resp.ClientSecret = secret
+		resp.ClientSecretExpiresAt = clientSecret.ExpiresAt.Unix()
+	}
+
+	log.Printf("Registered client %s for app %s", client.ClientID, client.AppID)
+	return resp, nil
+}
+
+// GetClientRegistration 读取客户端注册信息(RFC 7592)
+func (s *oauthClientService) GetClientRegistration(ctx context.Context, clientID, registrationToken string) (*model.ClientRegistrationResponse, error) {
+	client, err := s.authenticateRegistration(ctx, clientID, registrationToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.toClientRegistrationResponse(client), nil
+}
+
+// UpdateClientRegistration 更新客户端注册信息(RFC 7592)
+// 请求携带完整的元数据并整体替换原有配置
+func (s *oauthClientService) UpdateClientRegistration(ctx context.Context, clientID, registrationToken string, req *model.ClientRegistrationRequest) (*model.ClientRegistrationResponse, error) {
+	client, err := s.authenticateRegistration(ctx, clientID, registrationToken)
+	if err != nil {
+		return nil, err
+	}
+	if req.ClientID != "" && req.ClientID != client.ClientID {
+		log.Printf("client_id in body does not match registration: %s", req.ClientID)
+		return nil, ErrInvalidClientMetadata
+	}
+
+	// 客户端类型决定了是否持有密钥，不允许通过更新注册切换
+	clientType := client.Type
+	if err := applyRegistrationMetadata(client, req); err != nil {
+		return nil, err
+	}
+	if err := s.verifySectorIdentifier(ctx, client); err != nil {
+		return nil, err
+	}
+	if client.Type != clientType {
+		log.Printf("Changing token_endpoint_auth_method class is not allowed for client %s", client.ClientID)
+		return nil, ErrInvalidClientMetadata
+	}
+	client.UpdatedAt = time.Now()
+
+	if err := s.clientRepo.Update(ctx, client); err != nil {
+		return nil, err
+	}
+	return s.toClientRegistrationResponse(client), nil
+}
+
+// DeleteClientRegistration 删除客户端注册(RFC 7592)
+func (s *oauthClientService) DeleteClientRegistration(ctx context.Context, clientID, registrationToken string) error {
+	client, err := s.authenticateRegistration(ctx, clientID, registrationToken)
+	if err != nil {
+		return err
+	}
+
+	// 同时删除客户端的所有密钥
+	secrets, err := s.secretRepo.GetByClientID(ctx, client.ClientID)
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets {
+		if err := s.secretRepo.Delete(ctx, secret.ID); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Deleted registered client %s", client.ClientID)
+	return s.clientRepo.Delete(ctx, client.ID)
+}
+
+// authenticateRegistration 使用注册访问令牌认证客户端
+func (s *oauthClientService) authenticateRegistration(ctx context.Context, clientID, registrationToken string) (*model.OAuthClient, error) {
+	client, err := s.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	// 管理员创建的客户端没有注册访问令牌，不能通过注册接口管理
+	if client == nil || client.RegistrationAccessTokenHash == "" {
+		return nil, ErrInvalidRegistrationToken
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(registrationToken)), []byte(client.RegistrationAccessTokenHash)) != 1 {
+		log.Printf("Invalid registration access token for client %s", clientID)
+		return nil, ErrInvalidRegistrationToken
+	}
+	return client, nil
+}
+
+// applyRegistrationMetadata 将注册元数据应用到客户端，按RFC 7591补全默认值后统一校验
+func applyRegistrationMetadata(client *model.OAuthClient, req *model.ClientRegistrationRequest) error {
+	authMethod := req.TokenEndpointAuthMethod
+	if authMethod == "" {
+		authMethod = model.TokenEndpointAuthClientSecretBasic
+	}
+	switch authMethod {
+	case model.TokenEndpointAuthNone:
+		client.Type = model.Public
+	case model.TokenEndpointAuthClientSecretBasic, model.TokenEndpointAuthClientSecretPost,
+		model.TokenEndpointAuthClientSecretJWT, model.TokenEndpointAuthPrivateKeyJWT:
+		client.Type = model.Confidential
+	default:
+		log.Printf("Unsupported token_endpoint_auth_method: %s", authMethod)
+		return ErrInvalidClientMetadata
+	}
+
+	grantTypes := req.GrantTypes
+	if len(grantTypes) == 0 {
+		grantTypes = []string{string(model.AuthorizationCodeGrant)}
+	}
+
+	// 响应类型必须与授权类型一致(RFC 7591 2.1)
+	for _, responseType := range req.ResponseTypes {
+		if !allowsResponseType(grantTypes, model.ResponseType(responseType)) {
+			log.Printf("Unsupported response_type: %s", responseType)
+			return ErrInvalidClientMetadata
+		}
+	}
+
+	scope := strings.TrimSpace(req.Scope)
+	if scope == "" {
+		scope = model.ScopeOpenID
+	}
+
+	client.Name = req.ClientName
+	if client.Name == "" {
+		client.Name = client.ClientID
+	}
+	client.TokenEndpointAuthMethod = authMethod
+	client.GrantTypes = grantTypes
+	client.RedirectURIs = req.RedirectURIs
+	client.Scopes = strings.Fields(scope)
+	client.RequirePushedAuthorizationRequests = req.RequirePushedAuthorizationRequests
+	client.JWKSURI = req.JWKSURI
+	client.JWKS = jwksString(req.JWKS)
+	client.RequestObjectSigningAlg = req.RequestObjectSigningAlg
+	client.SubjectType = req.SubjectType
+	client.SectorIdentifierURI = req.SectorIdentifierURI
+	client.PostLogoutRedirectURIs = req.PostLogoutRedirectURIs
+	client.FrontchannelLogoutURI = req.FrontchannelLogoutURI
+	client.FrontchannelLogoutSessionRequired = req.FrontchannelLogoutSessionRequired
+	client.BackchannelLogoutURI = req.BackchannelLogoutURI
+	client.BackchannelLogoutSessionRequired = req.BackchannelLogoutSessionRequired
+
+	return validateClientMetadata(client)
+}
+
+// toClientRegistrationResponse 转换为客户端注册响应
+func (s *oauthClientService) toClientRegistrationResponse(client *model.OAuthClient) *model.ClientRegistrationResponse {
+	authMethod := client.TokenEndpointAuthMethod
+	if authMethod == "" {
+		authMethod = model.TokenEndpointAuthClientSecretBasic
+		if client.Type == model.Public {
+			authMethod = model.TokenEndpointAuthNone
+		}
+	}
+
+	var responseTypes []string
+	hasCode := containsString(client.GrantTypes, string(model.AuthorizationCodeGrant))
+	hasImplicit := containsString(client.GrantTypes, string(model.Implicit))
+	if hasCode {
+		responseTypes = append(responseTypes, string(model.CodeResponse))
+	}
+	if hasImplicit {
+		responseTypes = append(responseTypes, string(model.IDTokenResponse), string(model.IDTokenTokenResponse))
+	}
+	if hasCode && hasImplicit {
+		responseTypes = append(responseTypes, string(model.CodeIDTokenResponse), string(model.CodeIDTokenTokenResponse))
+	}
+
+	return &model.ClientRegistrationResponse{
+		ClientID:                client.ClientID,
+		ClientIDIssuedAt:        client.CreatedAt.Unix(),
+		RegistrationClientURI:   s.issuer + "/oauth/register/" + client.ClientID,
+		ClientName:              client.Name,
+		RedirectURIs:            client.RedirectURIs,
+		TokenEndpointAuthMethod: authMethod,
+		GrantTypes:              client.GrantTypes,
+		ResponseTypes:           responseTypes,
+		Scope:                   strings.Join(client.Scopes, " "),
+
+		RequirePushedAuthorizationRequests: client.RequirePushedAuthorizationRequests,
+		JWKSURI:                            client.JWKSURI,
+		JWKS:                               jwksRawMessage(client.JWKS),
+		RequestObjectSigningAlg:            client.RequestObjectSigningAlg,
+		SubjectType:                        client.SubjectType,
+		SectorIdentifierURI:                client.SectorIdentifierURI,
+		PostLogoutRedirectURIs:             client.PostLogoutRedirectURIs,
+		FrontchannelLogoutURI:              client.FrontchannelLogoutURI,
+		FrontchannelLogoutSessionRequired:  client.FrontchannelLogoutSessionRequired,
+		BackchannelLogoutURI:               client.BackchannelLogoutURI,
+		BackchannelLogoutSessionRequired:   client.BackchannelLogoutSessionRequired,
+	}
+}
+
+// toInitialAccessTokenResponse 转换为初始访问令牌响应
+func toInitialAccessTokenResponse(token *model.InitialAccessToken) *model.InitialAccessTokenResponse {
+	return &model.InitialAccessTokenResponse{
+		ID:          token.ID,
+		Description: token.Description,
+		MaxUses:     token.MaxUses,
+		UsedCount:   token.UsedCount,
+		ExpiresAt:   token.ExpiresAt.Format(time.RFC3339),
+		CreatedAt:   token.CreatedAt.Format(time.RFC3339),
+	}
+}
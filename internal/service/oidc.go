@@ -2,27 +2,41 @@ package service
 
 import (
 	"context"
-	"crypto/rsa"
+	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
-	"math/big"
 	"strings"
 	"time"
 
 	"lauth/internal/model"
 	"lauth/internal/repository"
 	"lauth/pkg/config"
+	"lauth/pkg/crypto"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// idTokenExpiry ID Token的有效期
+const idTokenExpiry = time.Hour
+
 // OIDCService OIDC服务接口
 type OIDCService interface {
 	// GenerateIDToken 生成ID Token
-	GenerateIDToken(ctx context.Context, user *model.User, client *model.OAuthClient, nonce string) (string, error)
+	GenerateIDToken(ctx context.Context, user *model.User, client *model.OAuthClient, opts *model.IDTokenOptions) (string, error)
+
+	// GenerateLogoutToken 生成后端通道登出令牌(OIDC Back-Channel Logout)
+	GenerateLogoutToken(ctx context.Context, client *model.OAuthClient, userID, sessionID string) (string, error)
+
+	// ParseIDTokenHint 解析之前颁发的ID Token(id_token_hint)，只校验签名与颁发者，允许已过期
+	// 返回的sub已解析为用户ID，pairwise客户端的sub不会暴露给调用方
+	ParseIDTokenHint(ctx context.Context, idToken string) (*model.OIDCClaims, error)
 
-	// GetUserInfo 获取用户信息
-	GetUserInfo(ctx context.Context, userID string, scope string) (*model.OIDCClaims, error)
+	// SubjectFor 获取用户在客户端看到的sub，pairwise客户端为按扇区派生的成对sub
+	SubjectFor(ctx context.Context, client *model.OAuthClient, userID string) (string, error)
+
+	// GetUserInfo 获取用户信息，sub按客户端的主体标识类型返回
+	GetUserInfo(ctx context.Context, userID, clientID, scope string) (*model.OIDCClaims, error)
 
 	// GetConfiguration 获取OIDC配置
 	GetConfiguration(ctx context.Context) (*model.OIDCConfiguration, error)
@@ -34,41 +48,65 @@ type OIDCService interface {
 // oidcService OIDC服务实现
 type oidcService struct {
 	userRepo     repository.UserRepository
+	clientRepo   repository.OAuthClientRepository
+	pairwiseRepo repository.PairwiseSubjectRepository
 	tokenService TokenService
 	config       *config.Config
-	privateKey   *rsa.PrivateKey
-	publicKey    *rsa.PublicKey
+	keyService   SigningKeyService
 }
 
 // NewOIDCService 创建OIDC服务实例
 func NewOIDCService(
 	userRepo repository.UserRepository,
+	clientRepo repository.OAuthClientRepository,
+	pairwiseRepo repository.PairwiseSubjectRepository,
 	tokenService TokenService,
 	config *config.Config,
-	privateKey *rsa.PrivateKey,
-	publicKey *rsa.PublicKey,
+	keyService SigningKeyService,
 ) OIDCService {
 	return &oidcService{
 		userRepo:     userRepo,
+		clientRepo:   clientRepo,
+		pairwiseRepo: pairwiseRepo,
 		tokenService: tokenService,
 		config:       config,
-		privateKey:   privateKey,
-		publicKey:    publicKey,
+		keyService:   keyService,
 	}
 }
 
+// sign 使用当前签名密钥签名，kid标识所用密钥
+func (s *oidcService) sign(ctx context.Context, claims jwt.Claims, typ string) (string, error) {
+	return signWithCurrentKey(ctx, s.keyService, claims, typ)
+}
+
 // GenerateIDToken 生成ID Token
-func (s *oidcService) GenerateIDToken(ctx context.Context, user *model.User, client *model.OAuthClient, nonce string) (string, error) {
+func (s *oidcService) GenerateIDToken(ctx context.Context, user *model.User, client *model.OAuthClient, opts *model.IDTokenOptions) (string, error) {
 	now := time.Now()
 
+	// 未记录认证时间时(如旧版本颁发的授权码)退化为当前时间
+	authTime := opts.AuthTime
+	if authTime.IsZero() {
+		authTime = now
+	}
+
+	subject, err := s.SubjectFor(ctx, client, user.ID)
+	if err != nil {
+		return "", err
+	}
+
 	claims := &model.OIDCClaims{
 		Issuer:    s.config.OIDC.Issuer,
-		Subject:   user.ID,
+		Subject:   subject,
 		Audience:  client.ClientID,
-		ExpiresAt: now.Add(time.Hour).Unix(),
+		ExpiresAt: now.Add(idTokenExpiry).Unix(),
 		IssuedAt:  now.Unix(),
-		AuthTime:  now.Unix(),
-		Nonce:     nonce,
+		AuthTime:  authTime.Unix(),
+		Nonce:     opts.Nonce,
+		ACR:       opts.ACR,
+		AMR:       opts.AMR,
+		SID:       opts.SessionID,
+		AtHash:    tokenHash(opts.AccessToken),
+		CHash:     tokenHash(opts.Code),
 
 		// 用户信息Claims
 		Name:              user.Name,
@@ -80,14 +118,65 @@ func (s *oidcService) GenerateIDToken(ctx context.Context, user *model.User, cli
 		UpdatedAt:         user.UpdatedAt.Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	token.Header["kid"] = "default" // 使用默认的密钥ID
+	return s.sign(ctx, claims, "")
+}
+
+// GenerateLogoutToken 生成后端通道登出令牌
+func (s *oidcService) GenerateLogoutToken(ctx context.Context, client *model.OAuthClient, userID, sessionID string) (string, error) {
+	subject, err := s.SubjectFor(ctx, client, userID)
+	if err != nil {
+		return "", err
+	}
 
-	return token.SignedString(s.privateKey)
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": s.config.OIDC.Issuer,
+		"sub": subject,
+		"aud": client.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(2 * time.Minute).Unix(),
+		"jti": uuid.NewString(),
+		"events": map[string]interface{}{
+			model.BackchannelLogoutEvent: map[string]interface{}{},
+		},
+	}
+	// 登出令牌禁止包含nonce，sub与sid至少包含其一(OIDC Back-Channel Logout 2.4)
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
+
+	return s.sign(ctx, claims, "logout+jwt")
+}
+
+// ParseIDTokenHint 解析之前颁发的ID Token
+func (s *oidcService) ParseIDTokenHint(ctx context.Context, idToken string) (*model.OIDCClaims, error) {
+	claims := &model.OIDCClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return s.keyService.VerificationKey(ctx, kid, token.Method.Alg())
+	}, jwt.WithoutClaimsValidation())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse id_token_hint: %w", err)
+	}
+
+	if claims.Issuer != s.config.OIDC.Issuer {
+		return nil, fmt.Errorf("id_token_hint issued by unexpected issuer: %s", claims.Issuer)
+	}
+
+	if claims.Subject, err = s.resolveSubject(ctx, claims.Audience, claims.Subject); err != nil {
+		return nil, fmt.Errorf("failed to resolve id_token_hint subject: %w", err)
+	}
+
+	return claims, nil
 }
 
 // GetUserInfo 获取用户信息
-func (s *oidcService) GetUserInfo(ctx context.Context, userID string, scope string) (*model.OIDCClaims, error) {
+func (s *oidcService) GetUserInfo(ctx context.Context, userID, clientID, scope string) (*model.OIDCClaims, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -96,10 +185,24 @@ func (s *oidcService) GetUserInfo(ctx context.Context, userID string, scope stri
 		return nil, fmt.Errorf("user not found")
 	}
 
+	// UserInfo返回的sub必须与颁发给该客户端的ID Token一致
+	subject := user.ID
+	if clientID != "" {
+		client, err := s.clientRepo.GetByClientID(ctx, clientID)
+		if err != nil {
+			return nil, err
+		}
+		if client != nil {
+			if subject, err = s.SubjectFor(ctx, client, user.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	now := time.Now()
 	claims := &model.OIDCClaims{
 		Issuer:    s.config.OIDC.Issuer,
-		Subject:   user.ID,
+		Subject:   subject,
 		Audience:  user.AppID,
 		ExpiresAt: now.Add(time.Hour).Unix(),
 		IssuedAt:  now.Unix(),
@@ -162,38 +265,66 @@ func (s *oidcService) GetConfiguration(ctx context.Context) (*model.OIDCConfigur
 		TokenEndpoint:                    s.config.OIDC.Issuer + "/oauth/token",
 		UserInfoEndpoint:                 s.config.OIDC.Issuer + "/userinfo",
 		JWKSUri:                          s.config.OIDC.Issuer + "/.well-known/jwks.json",
+		IntrospectionEndpoint:            s.config.OIDC.Issuer + "/oauth/introspect",
+		RevocationEndpoint:               s.config.OIDC.Issuer + "/oauth/revoke",
+		DeviceAuthorizationEndpoint:      s.config.OIDC.Issuer + "/oauth/device_authorization",
+		RegistrationEndpoint:             s.config.OIDC.Issuer + "/oauth/register",
+		EndSessionEndpoint:               s.config.OIDC.Issuer + "/oauth/logout",
+		PushedAuthorizationEndpoint:      s.config.OIDC.Issuer + "/oauth/par",
+		RequestParameterSupported:        true,
+		TokenEndpointAuthMethods:         supportedTokenEndpointAuthMethods,
+		TokenEndpointAuthSigningAlgs:     append(append([]string{}, supportedClientSecretJWTAlgs...), supportedClientSigningAlgs...),
+		RequestObjectSigningAlgs:         supportedClientSigningAlgs,
+		DPoPSigningAlgs:                  supportedClientSigningAlgs,
 		ScopesSupported:                  []string{model.ScopeOpenID, model.ScopeProfile, model.ScopeEmail, model.ScopePhone, model.ScopeAddress},
-		ResponseTypesSupported:           []string{"code", "id_token", "code id_token"},
-		SubjectTypesSupported:            []string{"public"},
-		IDTokenSigningAlgValuesSupported: []string{"RS256"},
+		ResponseTypesSupported:           []string{"code", "id_token", "id_token token", "code id_token", "code id_token token"},
+		ResponseModesSupported:           []string{model.ResponseModeQuery, model.ResponseModeFragment, model.ResponseModeFormPost},
+		SubjectTypesSupported:            []string{model.SubjectTypePublic, model.SubjectTypePairwise},
+		ACRValuesSupported:               []string{model.ACRSingleFactor, model.ACRMultiFactor},
+		IDTokenSigningAlgValuesSupported: []string{model.SigningAlgorithmRS256, model.SigningAlgorithmES256},
 		ClaimsSupported: []string{
-			"iss", "sub", "aud", "exp", "iat", "auth_time",
-			"nonce", "name", "preferred_username", "email",
-			"email_verified", "phone_number", "phone_verified",
+			"iss", "sub", "aud", "exp", "iat", "auth_time", "acr", "amr",
+			"nonce", "at_hash", "c_hash", "name", "preferred_username", "email",
+			"email_verified", "phone_number", "phone_verified", "sid",
 		},
+		CodeChallengeMethodsSupported:      []string{model.CodeChallengeMethodS256, model.CodeChallengeMethodPlain},
+		FrontchannelLogoutSupported:        true,
+		FrontchannelLogoutSessionSupported: true,
+		BackchannelLogoutSupported:         true,
+		BackchannelLogoutSessionSupported:  true,
 	}, nil
 }
 
 // GetJWKS 获取JSON Web Key Set
 func (s *oidcService) GetJWKS(ctx context.Context) (map[string]interface{}, error) {
-	// 将公钥转换为JWKS格式
-	n := base64.RawURLEncoding.EncodeToString(s.publicKey.N.Bytes())
-	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.publicKey.E)).Bytes())
-
-	jwks := map[string]interface{}{
-		"keys": []map[string]interface{}{
-			{
-				"kid": "default",
-				"kty": "RSA",
-				"use": "sig",
-				"alg": "RS256",
-				"n":   n,
-				"e":   e,
-			},
-		},
+	keys, err := s.keyService.PublishedKeys(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return jwks, nil
+	// 发布下一个、当前及未过期的退役密钥，验证方可按kid选择
+	jwks := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		jwk, err := crypto.PublicKeyJWK(key.Key.Public())
+		if err != nil {
+			return nil, err
+		}
+		jwk["kid"] = key.ID
+		jwk["use"] = "sig"
+		jwk["alg"] = key.Algorithm
+		jwks = append(jwks, jwk)
+	}
+
+	return map[string]interface{}{"keys": jwks}, nil
+}
+
+// tokenHash 计算at_hash/c_hash：令牌ASCII值SHA-256摘要的左半部分做base64url编码(OIDC Core 3.3.2.11)
+func tokenHash(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
 }
 
 // splitScope 分割scope字符串
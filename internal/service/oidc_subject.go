@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+
+	"lauth/internal/model"
+)
+
+// SubjectFor 获取用户在客户端看到的sub
+// pairwise客户端的sub由扇区标识与用户ID经HMAC派生，同一扇区的客户端得到相同的sub
+func (s *oidcService) SubjectFor(ctx context.Context, client *model.OAuthClient, userID string) (string, error) {
+	if client.SubjectType != model.SubjectTypePairwise {
+		return userID, nil
+	}
+
+	sector := sectorIdentifier(client)
+	salt := s.config.OIDC.PairwiseSalt
+	if salt == "" {
+		salt = s.config.JWT.Secret
+	}
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(sector))
+	mac.Write([]byte{0})
+	mac.Write([]byte(userID))
+	subject := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	// 派生是单向的，保存对应关系以便解析客户端回传的sub与令牌中的sub
+	// 对应关系不会变化，已保存时不再写入数据库
+	saved, err := s.pairwiseRepo.GetUserID(ctx, sector, subject)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pairwise subject: %w", err)
+	}
+	if saved != "" {
+		return subject, nil
+	}
+	if err := s.pairwiseRepo.Save(ctx, &model.PairwiseSubject{
+		SectorIdentifier: sector,
+		Subject:          subject,
+		UserID:           userID,
+	}); err != nil {
+		return "", fmt.Errorf("failed to save pairwise subject: %w", err)
+	}
+	return subject, nil
+}
+
+// resolveSubject 将客户端看到的sub解析为用户ID
+func (s *oidcService) resolveSubject(ctx context.Context, clientID, subject string) (string, error) {
+	client, err := s.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		return "", err
+	}
+	if client == nil || client.SubjectType != model.SubjectTypePairwise {
+		return subject, nil
+	}
+
+	userID, err := s.pairwiseRepo.GetUserID(ctx, sectorIdentifier(client), subject)
+	if err != nil {
+		return "", err
+	}
+	if userID == "" {
+		return "", fmt.Errorf("unknown pairwise subject for client %s", clientID)
+	}
+	return userID, nil
+}
+
+// sectorIdentifier 获取客户端的扇区标识(OIDC Core 8.1)
+// 优先使用sector_identifier_uri的主机名，否则使用重定向URI的主机名；没有重定向URI时以客户端ID作为扇区
+func sectorIdentifier(client *model.OAuthClient) string {
+	if client.SectorIdentifierURI != "" {
+		if u, err := url.Parse(client.SectorIdentifierURI); err == nil && u.Host != "" {
+			return u.Hostname()
+		}
+	}
+	hosts := redirectURIHosts(client.RedirectURIs)
+	if len(hosts) > 0 {
+		return hosts[0]
+	}
+	return client.ClientID
+}
+
+// redirectURIHosts 获取重定向URI中不重复的主机名，没有主机名的私有scheme以scheme代替
+func redirectURIHosts(redirectURIs []string) []string {
+	var hosts []string
+	for _, redirectURI := range redirectURIs {
+		u, err := url.Parse(redirectURI)
+		if err != nil {
+			continue
+		}
+		host := u.Hostname()
+		if host == "" {
+			host = u.Scheme
+		}
+		if !containsString(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"lauth/internal/model"
+	"lauth/internal/repository"
+)
+
+var (
+	// ErrProtectedResourceExists 受保护资源已登记
+	ErrProtectedResourceExists = errors.New("protected resource already exists")
+	// ErrProtectedResourceNotFound 受保护资源未登记
+	ErrProtectedResourceNotFound = errors.New("protected resource not found")
+	// ErrInvalidResourceIdentifier 资源标识不是不含片段的绝对URI
+	ErrInvalidResourceIdentifier = errors.New("resource identifier must be an absolute uri without fragment")
+)
+
+// oidcScopes OIDC标准权限范围，访问的是本服务的用户信息，不属于任何受保护资源
+var oidcScopes = []string{model.ScopeOpenID, model.ScopeProfile, model.ScopeEmail, model.ScopePhone, model.ScopeAddress}
+
+// ProtectedResourceService 受保护资源服务接口(RFC 8707)
+type ProtectedResourceService interface {
+	// CreateResource 为应用登记受保护资源
+	CreateResource(ctx context.Context, appID string, req *model.CreateProtectedResourceRequest) (*model.ProtectedResource, error)
+	// ListResources 获取应用登记的受保护资源
+	ListResources(ctx context.Context, appID string) ([]*model.ProtectedResource, error)
+	// DeleteResource 删除应用登记的受保护资源
+	DeleteResource(ctx context.Context, appID, id string) error
+
+	// ValidateResources 校验resource参数：每个资源必须是应用登记的资源或本服务的issuer
+	// scope非空时，OIDC权限范围以外的每个权限都必须被至少一个所请求的资源接受
+	ValidateResources(ctx context.Context, appID string, resources []string, scope string) error
+}
+
+// protectedResourceService 受保护资源服务实现
+type protectedResourceService struct {
+	resourceRepo repository.ProtectedResourceRepository
+	issuer       string
+}
+
+// NewProtectedResourceService 创建受保护资源服务实例
+func NewProtectedResourceService(resourceRepo repository.ProtectedResourceRepository, issuer string) ProtectedResourceService {
+	return &protectedResourceService{
+		resourceRepo: resourceRepo,
+		issuer:       issuer,
+	}
+}
+
+// CreateResource 为应用登记受保护资源
+func (s *protectedResourceService) CreateResource(ctx context.Context, appID string, req *model.CreateProtectedResourceRequest) (*model.ProtectedResource, error) {
+	if !validResourceIdentifier(req.Identifier) || req.Identifier == s.issuer {
+		return nil, ErrInvalidResourceIdentifier
+	}
+
+	existing, err := s.resourceRepo.GetByIdentifier(ctx, appID, req.Identifier)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrProtectedResourceExists
+	}
+
+	resource := &model.ProtectedResource{
+		AppID:       appID,
+		Identifier:  req.Identifier,
+		Name:        req.Name,
+		Description: req.Description,
+		Scopes:      req.Scopes,
+	}
+	if err := s.resourceRepo.Create(ctx, resource); err != nil {
+		return nil, fmt.Errorf("failed to create protected resource: %w", err)
+	}
+	return resource, nil
+}
+
+// ListResources 获取应用登记的受保护资源
+func (s *protectedResourceService) ListResources(ctx context.Context, appID string) ([]*model.ProtectedResource, error) {
+	return s.resourceRepo.ListByAppID(ctx, appID)
+}
+
+// DeleteResource 删除应用登记的受保护资源
+func (s *protectedResourceService) DeleteResource(ctx context.Context, appID, id string) error {
+	existing, err := s.resourceRepo.GetByID(ctx, appID, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrProtectedResourceNotFound
+	}
+	return s.resourceRepo.Delete(ctx, appID, id)
+}
+
+// ValidateResources 校验resource参数(RFC 8707 2)
+func (s *protectedResourceService) ValidateResources(ctx context.Context, appID string, resources []string, scope string) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	// 本服务的issuer接受客户端的全部权限范围
+	acceptedScopes := make(map[string]bool)
+	acceptsAll := false
+	for _, identifier := range resources {
+		if !validResourceIdentifier(identifier) {
+			log.Printf("Invalid resource indicator: %s", identifier)
+			return ErrInvalidTarget
+		}
+		if identifier == s.issuer {
+			acceptsAll = true
+			continue
+		}
+
+		resource, err := s.resourceRepo.GetByIdentifier(ctx, appID, identifier)
+		if err != nil {
+			return err
+		}
+		if resource == nil {
+			log.Printf("Resource %s is not registered for app %s", identifier, appID)
+			return ErrInvalidTarget
+		}
+		for _, accepted := range resource.Scopes {
+			acceptedScopes[accepted] = true
+		}
+	}
+
+	if acceptsAll {
+		return nil
+	}
+	for _, requested := range strings.Fields(scope) {
+		if !acceptedScopes[requested] && !containsString(oidcScopes, requested) {
+			log.Printf("Scope %s is not accepted by any of the requested resources %v", requested, resources)
+			return ErrInvalidScope
+		}
+	}
+	return nil
+}
+
+// validResourceIdentifier 资源标识必须是不含片段的绝对URI
+func validResourceIdentifier(identifier string) bool {
+	u, err := url.Parse(identifier)
+	return err == nil && u.IsAbs() && u.Host != "" && u.Fragment == "" && !strings.Contains(identifier, "#")
+}
+
+// resourcesSubset 判断requested中的资源是否都在granted之内
+func resourcesSubset(granted, requested []string) bool {
+	for _, resource := range requested {
+		if !containsString(granted, resource) {
+			return false
+		}
+	}
+	return true
+}
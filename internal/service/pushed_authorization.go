@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"lauth/internal/model"
+	"lauth/pkg/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// pushedRequestExpiry 推送授权请求的有效期，需覆盖用户登录与确认授权的时间
+const pushedRequestExpiry = 5 * time.Minute
+
+// PushedAuthorizationService 推送授权请求服务接口(RFC 9126)
+type PushedAuthorizationService interface {
+	// Push 保存已验证的授权请求，返回引用该请求的request_uri
+	Push(ctx context.Context, req *model.AuthorizationRequest) (*model.PushedAuthorizationResponse, error)
+	// Resolve 获取request_uri引用的授权请求，request_uri只能由推送它的客户端使用
+	Resolve(ctx context.Context, clientID, requestURI string) (*model.AuthorizationRequest, error)
+	// Consume 颁发授权响应前使request_uri失效，request_uri已被使用时返回ErrInvalidRequestURI
+	Consume(ctx context.Context, requestURI string) error
+}
+
+// pushedAuthorizationService 推送授权请求服务实现
+type pushedAuthorizationService struct {
+	redis *redis.Client
+}
+
+// NewPushedAuthorizationService 创建推送授权请求服务实例
+func NewPushedAuthorizationService(redisClient *redis.Client) PushedAuthorizationService {
+	return &pushedAuthorizationService{
+		redis: redisClient,
+	}
+}
+
+// Push 保存已验证的授权请求
+func (s *pushedAuthorizationService) Push(ctx context.Context, req *model.AuthorizationRequest) (*model.PushedAuthorizationResponse, error) {
+	reference, err := generateSecureToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate request uri: %w", err)
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal authorization request: %w", err)
+	}
+	if err := s.redis.Set(ctx, s.requestKey(reference), string(data), pushedRequestExpiry); err != nil {
+		return nil, fmt.Errorf("failed to store authorization request: %w", err)
+	}
+
+	return &model.PushedAuthorizationResponse{
+		RequestURI: model.RequestURIPrefix + reference,
+		ExpiresIn:  int64(pushedRequestExpiry.Seconds()),
+	}, nil
+}
+
+// Resolve 获取request_uri引用的授权请求
+func (s *pushedAuthorizationService) Resolve(ctx context.Context, clientID, requestURI string) (*model.AuthorizationRequest, error) {
+	reference, ok := strings.CutPrefix(requestURI, model.RequestURIPrefix)
+	if !ok || reference == "" {
+		return nil, ErrInvalidRequestURI
+	}
+
+	data, err := s.redis.Get(ctx, s.requestKey(reference))
+	if err == goredis.Nil {
+		return nil, ErrInvalidRequestURI
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authorization request: %w", err)
+	}
+
+	var req model.AuthorizationRequest
+	if err := json.Unmarshal([]byte(data), &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal authorization request: %w", err)
+	}
+	if req.ClientID != clientID {
+		return nil, ErrInvalidRequestURI
+	}
+
+	req.RequestURI = requestURI
+	return &req, nil
+}
+
+// Consume 颁发授权响应前使request_uri失效
+func (s *pushedAuthorizationService) Consume(ctx context.Context, requestURI string) error {
+	reference := strings.TrimPrefix(requestURI, model.RequestURIPrefix)
+	// 以删除结果判断是否为首次使用，避免并发的授权请求重复颁发授权码
+	deleted, err := s.redis.Client.Del(ctx, s.requestKey(reference)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to consume request uri: %w", err)
+	}
+	if deleted == 0 {
+		return ErrInvalidRequestURI
+	}
+	return nil
+}
+
+// requestKey 构建推送授权请求键
+func (s *pushedAuthorizationService) requestKey(reference string) string {
+	return fmt.Sprintf("par_request:%s", reference)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"log"
+	"net"
+	"net/url"
+	"strings"
+
+	"lauth/internal/model"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// matchRedirectURI 判断授权请求的重定向URI是否属于客户端
+// 除与登记的重定向URI完全一致外，还接受：
+//   - 登记为回环IP地址的http重定向URI，忽略端口(RFC 8252 7.3)，原生应用每次可以监听不同的临时端口
+//   - 与管理员配置的通配模式匹配的https重定向URI，通配符只匹配一级子域名
+func matchRedirectURI(client *model.OAuthClient, redirectURI string) bool {
+	if containsString(client.RedirectURIs, redirectURI) {
+		return true
+	}
+
+	u, err := url.Parse(redirectURI)
+	if err != nil || u.User != nil || u.Fragment != "" {
+		return false
+	}
+	for _, registered := range client.RedirectURIs {
+		if matchLoopbackRedirectURI(registered, u) {
+			return true
+		}
+	}
+	for _, pattern := range client.RedirectURIPatterns {
+		if matchRedirectURIPattern(pattern, u) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLoopbackRedirectURI 按回环重定向规则匹配，除端口外的各部分必须一致
+// localhost可能被解析到非回环地址(RFC 8252 8.3)，只有IP字面量才忽略端口
+func matchLoopbackRedirectURI(registered string, u *url.URL) bool {
+	r, err := url.Parse(registered)
+	if err != nil || r.Scheme != "http" || u.Scheme != "http" {
+		return false
+	}
+	if ip := net.ParseIP(r.Hostname()); ip == nil || !ip.IsLoopback() {
+		return false
+	}
+	return u.Hostname() == r.Hostname() && u.EscapedPath() == r.EscapedPath() && u.RawQuery == r.RawQuery
+}
+
+// matchRedirectURIPattern 按通配模式匹配，除通配的子域名外的各部分必须一致
+func matchRedirectURIPattern(pattern string, u *url.URL) bool {
+	p, err := url.Parse(pattern)
+	if err != nil || u.Scheme != "https" || u.Port() != p.Port() {
+		return false
+	}
+	if u.EscapedPath() != p.EscapedPath() || u.RawQuery != p.RawQuery {
+		return false
+	}
+	suffix := strings.TrimPrefix(strings.ToLower(p.Hostname()), "*.")
+	label, rest, ok := strings.Cut(strings.ToLower(u.Hostname()), ".")
+	return ok && rest == suffix && isDNSLabel(label)
+}
+
+// validateRegisteredRedirectURI 验证登记的重定向URI
+// 重定向URI必须是不含fragment与用户信息的绝对URI(RFC 6749 3.1.2)；
+// 私有URI scheme必须是反向域名形式(RFC 8252 7.1)，以免与其他应用或javascript:等scheme冲突
+func validateRegisteredRedirectURI(redirectURI string) error {
+	u, err := url.Parse(redirectURI)
+	if err != nil || u.Scheme == "" || u.Fragment != "" || u.User != nil {
+		log.Printf("Invalid redirect URI: %s", redirectURI)
+		return ErrInvalidRedirectURI
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			log.Printf("Invalid redirect URI: %s", redirectURI)
+			return ErrInvalidRedirectURI
+		}
+	default:
+		if !strings.Contains(u.Scheme, ".") {
+			log.Printf("Private-use URI scheme must be a reverse domain name: %s", redirectURI)
+			return ErrInvalidRedirectURI
+		}
+	}
+	return nil
+}
+
+// validateRedirectURIPattern 验证管理员配置的重定向URI通配模式
+// 模式必须使用https，*只能作为主机名最左侧的完整标签出现一次；
+// *之后必须包含可注册域名，避免*.com、*.github.io这类覆盖公共后缀下所有站点的模式；
+// 端口、路径与查询参数按原样匹配
+func validateRedirectURIPattern(pattern string) error {
+	u, err := url.Parse(pattern)
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Fragment != "" || strings.Count(pattern, "*") != 1 {
+		log.Printf("Invalid redirect URI pattern: %s", pattern)
+		return ErrInvalidRedirectURI
+	}
+	suffix, ok := strings.CutPrefix(strings.ToLower(u.Hostname()), "*.")
+	if !ok {
+		log.Printf("Redirect URI pattern must start with a wildcard subdomain: %s", pattern)
+		return ErrInvalidRedirectURI
+	}
+	for _, label := range strings.Split(suffix, ".") {
+		if !isDNSLabel(label) {
+			log.Printf("Invalid redirect URI pattern: %s", pattern)
+			return ErrInvalidRedirectURI
+		}
+	}
+	// 公共后缀本身没有可注册域名，通配的子域名可以由任何人注册
+	if _, err := publicsuffix.EffectiveTLDPlusOne(suffix); err != nil {
+		log.Printf("Redirect URI pattern is too broad: %s", pattern)
+		return ErrInvalidRedirectURI
+	}
+	return nil
+}
+
+// isDNSLabel 判断是否为合法的域名标签(RFC 1123 2.1)
+func isDNSLabel(label string) bool {
+	if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for _, r := range label {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
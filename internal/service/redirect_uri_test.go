@@ -0,0 +1,96 @@
+package service
+
+import (
+	"testing"
+
+	"lauth/internal/model"
+)
+
+func TestMatchRedirectURI(t *testing.T) {
+	client := &model.OAuthClient{
+		RedirectURIs: []string{
+			"https://app.example.com/callback",
+			"http://127.0.0.1:8080/callback",
+			"http://[::1]/callback",
+			"http://localhost:3000/callback",
+			"com.example.app:/callback",
+		},
+		RedirectURIPatterns: []string{
+			"https://*.preview.example.com/callback",
+			"https://*.example.org:8443/callback?tenant=1",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		redirectURI string
+		want        bool
+	}{
+		{"exact", "https://app.example.com/callback", true},
+		{"exact private-use scheme", "com.example.app:/callback", true},
+		{"different path", "https://app.example.com/other", false},
+		{"extra query", "https://app.example.com/callback?x=1", false},
+		{"loopback ipv4 other port", "http://127.0.0.1:51234/callback", true},
+		{"loopback ipv6 any port", "http://[::1]:51234/callback", true},
+		{"loopback different path", "http://127.0.0.1:51234/other", false},
+		{"loopback https", "https://127.0.0.1:51234/callback", false},
+		{"localhost port must match", "http://localhost:4000/callback", false},
+		{"pattern subdomain", "https://pr-42.preview.example.com/callback", true},
+		{"pattern uppercase host", "https://PR-42.Preview.Example.com/callback", true},
+		{"pattern nested subdomain", "https://a.b.preview.example.com/callback", false},
+		{"pattern bare suffix", "https://preview.example.com/callback", false},
+		{"pattern http", "http://pr-42.preview.example.com/callback", false},
+		{"pattern different path", "https://pr-42.preview.example.com/other", false},
+		{"pattern fragment", "https://pr-42.preview.example.com/callback#x", false},
+		{"pattern userinfo", "https://user@pr-42.preview.example.com/callback", false},
+		{"pattern lookalike suffix", "https://pr-42.preview.example.com.evil.com/callback", false},
+		{"pattern invalid label", "https://pr_42.preview.example.com/callback", false},
+		{"pattern port and query", "https://tenant.example.org:8443/callback?tenant=1", true},
+		{"pattern missing port", "https://tenant.example.org/callback?tenant=1", false},
+		{"pattern different query", "https://tenant.example.org:8443/callback?tenant=2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchRedirectURI(client, tt.redirectURI); got != tt.want {
+				t.Errorf("matchRedirectURI(%q) = %v, want %v", tt.redirectURI, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRedirectURIPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		wantErr bool
+	}{
+		{"subdomain of registrable domain", "https://*.example.com/callback", false},
+		{"nested suffix", "https://*.preview.example.com/callback", false},
+		{"port and query", "https://*.example.org:8443/callback?tenant=1", false},
+		{"registrable domain under multi-label suffix", "https://*.example.co.uk/callback", false},
+		{"registrable domain under private suffix", "https://*.myapp.vercel.app/callback", false},
+		{"http", "http://*.example.com/callback", true},
+		{"no wildcard", "https://app.example.com/callback", true},
+		{"wildcard not leftmost", "https://app.*.example.com/callback", true},
+		{"partial label wildcard", "https://pr-*.example.com/callback", true},
+		{"two wildcards", "https://*.example.com/*", true},
+		{"wildcard in path", "https://example.com/*", true},
+		{"userinfo", "https://user@*.example.com/callback", true},
+		{"fragment", "https://*.example.com/callback#x", true},
+		{"invalid label", "https://*.exa_mple.com/callback", true},
+		{"top-level domain", "https://*.com/callback", true},
+		{"multi-label public suffix", "https://*.co.uk/callback", true},
+		{"vercel.app", "https://*.vercel.app/callback", true},
+		{"github.io", "https://*.github.io/callback", true},
+		{"netlify.app", "https://*.netlify.app/callback", true},
+		{"herokuapp.com", "https://*.herokuapp.com/callback", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRedirectURIPattern(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRedirectURIPattern(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			}
+		})
+	}
+}
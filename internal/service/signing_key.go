@@ -0,0 +1,453 @@
+package service
+
+import (
+	"context"
+	gocrypto "crypto"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"lauth/internal/model"
+	"lauth/internal/repository"
+	"lauth/pkg/crypto"
+	"lauth/pkg/redis"
+
+	"github.com/golang-jwt/jwt/v5"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// defaultSigningKeyAlgorithm 没有指定算法且没有当前密钥时使用的签名算法
+	defaultSigningKeyAlgorithm = model.SigningAlgorithmRS256
+	// signingKeyCacheTTL 密钥缓存有效期，多实例部署时其他实例在此时间内获取轮换结果
+	signingKeyCacheTTL = time.Minute
+	// signingKeyMinReload 未知kid触发重新加载的最小间隔，避免携带随机kid的令牌使每个请求都查询数据库
+	signingKeyMinReload = 10 * time.Second
+
+	// signingKeyLockKey 跨实例的签名密钥锁，多个实例同时初始化或轮换密钥时避免产生多个当前密钥
+	signingKeyLockKey = "signing_key_lock"
+	// signingKeyLockTTL 锁的有效期，持锁实例异常退出时自动释放，需覆盖生成RSA密钥与保存的时间
+	signingKeyLockTTL = 30 * time.Second
+	// signingKeyLockWait 等待其他实例释放锁的最长时间
+	signingKeyLockWait = 10 * time.Second
+	// signingKeyLockRetry 获取锁的重试间隔
+	signingKeyLockRetry = 100 * time.Millisecond
+)
+
+var (
+	// ErrSigningKeyNotFound 签名密钥不存在
+	ErrSigningKeyNotFound = errors.New("signing key not found")
+	// ErrSigningKeyLocked 其他实例正在初始化或轮换签名密钥
+	ErrSigningKeyLocked = errors.New("signing keys are being changed by another instance")
+)
+
+// releaseSigningKeyLock 只释放本实例持有的锁，避免锁过期后误删其他实例的锁
+var releaseSigningKeyLock = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// SigningKeyService 签名密钥服务接口
+type SigningKeyService interface {
+	// EnsureKeys 确保存在当前密钥与下一个密钥，首次启动时导入配置文件中的密钥作为当前密钥
+	EnsureKeys(ctx context.Context, initialKey *rsa.PrivateKey) error
+
+	// CurrentKey 获取当前签名密钥
+	CurrentKey(ctx context.Context) (*model.SigningKey, error)
+
+	// VerificationKey 根据kid获取验签公钥，密钥的算法必须与令牌头部的alg一致
+	VerificationKey(ctx context.Context, kid, alg string) (gocrypto.PublicKey, error)
+
+	// PublishedKeys 获取需要在JWKS中发布的密钥
+	PublishedKeys(ctx context.Context) ([]*model.SigningKey, error)
+
+	// ListKeys 获取签名密钥列表
+	ListKeys(ctx context.Context) ([]*model.SigningKeyResponse, error)
+
+	// RotateKeys 轮换签名密钥：下一个密钥成为当前密钥，当前密钥退役，并以algorithm生成新的下一个密钥
+	// algorithm为空时沿用当前密钥的算法；切换算法需要两次轮换，新算法的密钥先作为下一个密钥发布
+	RotateKeys(ctx context.Context, algorithm string) ([]*model.SigningKeyResponse, error)
+}
+
+// signingKeyService 签名密钥服务实现
+type signingKeyService struct {
+	keyRepo repository.SigningKeyRepository
+	redis   *redis.Client
+	// retention 退役密钥继续发布的时间，覆盖其签发的访问令牌与ID Token的最长有效期
+	retention time.Duration
+
+	mu       sync.RWMutex
+	keys     []*model.SigningKey
+	loadedAt time.Time
+}
+
+// NewSigningKeyService 创建签名密钥服务实例
+// accessTokenExpiry为配置的访问令牌有效期，退役密钥至少保留到以其签发的访问令牌与ID Token全部过期
+func NewSigningKeyService(keyRepo repository.SigningKeyRepository, redisClient *redis.Client, accessTokenExpiry time.Duration) SigningKeyService {
+	retention := idTokenExpiry
+	if accessTokenExpiry > retention {
+		retention = accessTokenExpiry
+	}
+	return &signingKeyService{
+		keyRepo:   keyRepo,
+		redis:     redisClient,
+		retention: retention,
+	}
+}
+
+// EnsureKeys 确保存在当前密钥与下一个密钥
+func (s *signingKeyService) EnsureKeys(ctx context.Context, initialKey *rsa.PrivateKey) error {
+	return s.withLock(ctx, func() error {
+		return s.ensureKeys(ctx, initialKey)
+	})
+}
+
+// ensureKeys 持锁后重新加载密钥，补齐缺少的当前密钥与下一个密钥
+func (s *signingKeyService) ensureKeys(ctx context.Context, initialKey *rsa.PrivateKey) error {
+	keys, err := s.reload(ctx)
+	if err != nil {
+		return err
+	}
+
+	var current, next *model.SigningKey
+	for _, key := range keys {
+		switch key.Status {
+		case model.SigningKeyCurrent:
+			current = key
+		case model.SigningKeyNext:
+			next = key
+		}
+	}
+
+	now := time.Now()
+	var changed []*model.SigningKey
+	if current == nil {
+		if initialKey != nil {
+			if current, err = newSigningKey(initialKey, model.SigningAlgorithmRS256, now); err != nil {
+				return err
+			}
+		} else if current, err = generateSigningKey(defaultSigningKeyAlgorithm, now); err != nil {
+			return err
+		}
+		current.Status = model.SigningKeyCurrent
+		current.ActivatedAt = &now
+		changed = append(changed, current)
+		log.Printf("Initialized current signing key %s", current.ID)
+	}
+	if next == nil {
+		if next, err = generateSigningKey(current.Algorithm, now); err != nil {
+			return err
+		}
+		changed = append(changed, next)
+		log.Printf("Generated next signing key %s", next.ID)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := s.keyRepo.SaveAll(ctx, changed); err != nil {
+		return fmt.Errorf("failed to save signing keys: %w", err)
+	}
+	_, err = s.reload(ctx)
+	return err
+}
+
+// CurrentKey 获取当前签名密钥
+func (s *signingKeyService) CurrentKey(ctx context.Context) (*model.SigningKey, error) {
+	keys, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.Status == model.SigningKeyCurrent {
+			return key, nil
+		}
+	}
+	return nil, ErrSigningKeyNotFound
+}
+
+// VerificationKey 根据kid获取验签公钥
+func (s *signingKeyService) VerificationKey(ctx context.Context, kid, alg string) (gocrypto.PublicKey, error) {
+	keys, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := findSigningKey(keys, kid)
+	if key == nil && s.reloadable() {
+		// 其他实例可能刚完成轮换，缓存已加载超过最小间隔时刷新一次
+		if keys, err = s.reload(ctx); err != nil {
+			return nil, err
+		}
+		key = findSigningKey(keys, kid)
+	}
+	// 算法必须与密钥登记的一致，避免以其他算法验证同一公钥
+	if key == nil || key.Algorithm != alg {
+		return nil, ErrSigningKeyNotFound
+	}
+	return key.Key.Public(), nil
+}
+
+// PublishedKeys 获取需要在JWKS中发布的密钥
+func (s *signingKeyService) PublishedKeys(ctx context.Context) ([]*model.SigningKey, error) {
+	keys, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	published := make([]*model.SigningKey, 0, len(keys))
+	for _, key := range keys {
+		if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+			continue
+		}
+		published = append(published, key)
+	}
+	return published, nil
+}
+
+// ListKeys 获取签名密钥列表
+func (s *signingKeyService) ListKeys(ctx context.Context) ([]*model.SigningKeyResponse, error) {
+	keys, err := s.reload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toSigningKeyResponses(keys), nil
+}
+
+// RotateKeys 轮换签名密钥
+func (s *signingKeyService) RotateKeys(ctx context.Context, algorithm string) ([]*model.SigningKeyResponse, error) {
+	var responses []*model.SigningKeyResponse
+	err := s.withLock(ctx, func() error {
+		var err error
+		responses, err = s.rotateKeys(ctx, algorithm)
+		return err
+	})
+	return responses, err
+}
+
+// rotateKeys 持锁后重新加载密钥并完成轮换
+func (s *signingKeyService) rotateKeys(ctx context.Context, algorithm string) ([]*model.SigningKeyResponse, error) {
+	keys, err := s.reload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var changed []*model.SigningKey
+	var next *model.SigningKey
+	for _, key := range keys {
+		switch key.Status {
+		case model.SigningKeyCurrent:
+			if algorithm == "" {
+				algorithm = key.Algorithm
+			}
+			expiresAt := now.Add(s.retention)
+			key.Status = model.SigningKeyRetired
+			key.RetiredAt = &now
+			key.ExpiresAt = &expiresAt
+			changed = append(changed, key)
+		case model.SigningKeyNext:
+			next = key
+		}
+	}
+
+	if algorithm == "" {
+		algorithm = defaultSigningKeyAlgorithm
+	}
+
+	// 没有预发布的下一个密钥时直接生成，验证方需在刷新JWKS后才能验证新令牌
+	if next == nil {
+		log.Printf("No pre-published signing key found, generating one for immediate use")
+		if next, err = generateSigningKey(algorithm, now); err != nil {
+			return nil, err
+		}
+	}
+	next.Status = model.SigningKeyCurrent
+	next.ActivatedAt = &now
+	changed = append(changed, next)
+
+	newNext, err := generateSigningKey(algorithm, now)
+	if err != nil {
+		return nil, err
+	}
+	changed = append(changed, newNext)
+
+	if err := s.keyRepo.SaveAll(ctx, changed); err != nil {
+		return nil, fmt.Errorf("failed to save signing keys: %w", err)
+	}
+	if err := s.keyRepo.DeleteExpired(ctx, now); err != nil {
+		log.Printf("Failed to delete expired signing keys: %v", err)
+	}
+
+	log.Printf("Rotated signing keys, current key is now %s", next.ID)
+	if keys, err = s.reload(ctx); err != nil {
+		return nil, err
+	}
+	return toSigningKeyResponses(keys), nil
+}
+
+// withLock 持有跨实例的签名密钥锁执行fn，锁被占用时等待其他实例完成
+func (s *signingKeyService) withLock(ctx context.Context, fn func() error) error {
+	token, err := generateSecureToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate lock token: %w", err)
+	}
+
+	deadline := time.Now().Add(signingKeyLockWait)
+	for {
+		acquired, err := s.redis.SetNX(ctx, signingKeyLockKey, token, signingKeyLockTTL).Result()
+		if err != nil {
+			return fmt.Errorf("failed to acquire signing key lock: %w", err)
+		}
+		if acquired {
+			break
+		}
+		if time.Now().After(deadline) {
+			return ErrSigningKeyLocked
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(signingKeyLockRetry):
+		}
+	}
+	defer func() {
+		if err := releaseSigningKeyLock.Run(context.Background(), s.redis.Client, []string{signingKeyLockKey}, token).Err(); err != nil {
+			log.Printf("Failed to release signing key lock: %v", err)
+		}
+	}()
+
+	return fn()
+}
+
+// load 获取缓存的密钥，缓存过期时从数据库重新加载
+func (s *signingKeyService) load(ctx context.Context) ([]*model.SigningKey, error) {
+	s.mu.RLock()
+	keys, loadedAt := s.keys, s.loadedAt
+	s.mu.RUnlock()
+
+	if keys != nil && time.Since(loadedAt) < signingKeyCacheTTL {
+		return keys, nil
+	}
+	return s.reload(ctx)
+}
+
+// reloadable 判断缓存是否已加载超过最小间隔，可以因未知kid重新加载
+func (s *signingKeyService) reloadable() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return time.Since(s.loadedAt) >= signingKeyMinReload
+}
+
+// reload 从数据库加载未过期的密钥并解析私钥
+func (s *signingKeyService) reload(ctx context.Context) ([]*model.SigningKey, error) {
+	keys, err := s.keyRepo.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+	for _, key := range keys {
+		if key.Key, err = crypto.ParsePrivateKeyPEM(key.PrivateKey); err != nil {
+			return nil, fmt.Errorf("invalid signing key %s: %w", key.ID, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return keys, nil
+}
+
+// signWithCurrentKey 使用当前签名密钥签名，kid标识所用密钥，typ非空时写入头部
+func signWithCurrentKey(ctx context.Context, keyService SigningKeyService, claims jwt.Claims, typ string) (string, error) {
+	key, err := keyService.CurrentKey(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get signing key: %w", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	if typ != "" {
+		token.Header["typ"] = typ
+	}
+
+	return token.SignedString(key.Key)
+}
+
+// generateSigningKey 按算法生成新的下一个签名密钥
+func generateSigningKey(algorithm string, now time.Time) (*model.SigningKey, error) {
+	var privateKey gocrypto.Signer
+	var err error
+	switch algorithm {
+	case model.SigningAlgorithmRS256:
+		privateKey, err = crypto.GenerateRSAKey()
+	case model.SigningAlgorithmES256:
+		privateKey, err = crypto.GenerateECKey()
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newSigningKey(privateKey, algorithm, now)
+}
+
+// newSigningKey 根据私钥构建签名密钥，kid取公钥指纹
+func newSigningKey(privateKey gocrypto.Signer, algorithm string, now time.Time) (*model.SigningKey, error) {
+	kid, err := crypto.PublicKeyThumbprint(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := crypto.EncodePrivateKeyPEM(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &model.SigningKey{
+		ID:         kid,
+		Algorithm:  algorithm,
+		Status:     model.SigningKeyNext,
+		PrivateKey: encoded,
+		CreatedAt:  now,
+		Key:        privateKey,
+	}, nil
+}
+
+// findSigningKey 根据kid查找密钥
+func findSigningKey(keys []*model.SigningKey, kid string) *model.SigningKey {
+	for _, key := range keys {
+		if key.ID == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+// toSigningKeyResponses 转换为签名密钥响应
+func toSigningKeyResponses(keys []*model.SigningKey) []*model.SigningKeyResponse {
+	responses := make([]*model.SigningKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		resp := &model.SigningKeyResponse{
+			Kid:       key.ID,
+			Algorithm: key.Algorithm,
+			Status:    key.Status,
+			CreatedAt: key.CreatedAt.Format(time.RFC3339),
+		}
+		if key.ActivatedAt != nil {
+			resp.ActivatedAt = key.ActivatedAt.Format(time.RFC3339)
+		}
+		if key.RetiredAt != nil {
+			resp.RetiredAt = key.RetiredAt.Format(time.RFC3339)
+		}
+		if key.ExpiresAt != nil {
+			resp.ExpiresAt = key.ExpiresAt.Format(time.RFC3339)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
@@ -4,18 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"lauth/internal/model"
+	"lauth/internal/repository"
 	"lauth/pkg/redis"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrTokenExpired = errors.New("token expired")
 	ErrTokenRevoked = errors.New("token revoked")
+	// ErrInvalidAudience 令牌的受众不包含当前资源
+	ErrInvalidAudience = errors.New("invalid audience")
 )
 
 // TokenService Token服务接口
@@ -23,14 +28,45 @@ type TokenService interface {
 	// GenerateTokenPair 生成访问令牌和刷新令牌对
 	GenerateTokenPair(ctx context.Context, user *model.User, scope string) (*model.TokenPair, error)
 
+	// GenerateTokenPairWithOptions 按选项生成令牌对(OAuth授权流程使用，记录颁发令牌的客户端)
+	GenerateTokenPairWithOptions(ctx context.Context, user *model.User, opts *model.TokenOptions) (*model.TokenPair, error)
+
+	// GenerateAccessToken 按选项只生成访问令牌(授权端点直接颁发令牌时使用，不颁发刷新令牌)
+	GenerateAccessToken(ctx context.Context, user *model.User, opts *model.TokenOptions) (string, time.Duration, error)
+
+	// GenerateClientToken 为客户端凭证授权生成服务令牌(不含用户主体，不颁发刷新令牌)
+	GenerateClientToken(ctx context.Context, client *model.OAuthClient, opts *model.ClientTokenOptions) (string, time.Duration, error)
+
+	// GenerateExchangedToken 为令牌交换生成访问令牌(不颁发刷新令牌)
+	// 沿用主体令牌的用户、令牌族与会话，主体令牌被吊销时一并失效，有效期不超过主体令牌
+	GenerateExchangedToken(ctx context.Context, subject *model.TokenClaims, opts *model.TokenExchangeOptions) (string, time.Duration, error)
+
 	// ValidateToken 验证令牌
 	ValidateToken(ctx context.Context, tokenString string, tokenType model.TokenType) (*model.TokenClaims, error)
 
-	// RefreshToken 刷新访问令牌
-	RefreshToken(ctx context.Context, refreshToken string) (*model.TokenPair, error)
+	// ValidateTokenForAudience 验证令牌并校验受众(RFC 8707)
+	// 令牌只能用于受众内的资源，audience为空时表示本服务(issuer)；没有aud的访问令牌视为只面向本服务
+	ValidateTokenForAudience(ctx context.Context, tokenString string, tokenType model.TokenType, audience string) (*model.TokenClaims, error)
+
+	// ParseToken 解析并验证任意类型的令牌(签名、吊销状态与有效期)，令牌类型从声明中读取
+	ParseToken(ctx context.Context, tokenString string) (*model.TokenClaims, error)
+
+	// RefreshToken 刷新访问令牌，刷新令牌只能使用一次，每次刷新颁发同一令牌族的新刷新令牌
+	// 已使用的刷新令牌再次出现时吊销整个令牌族；新令牌沿用原令牌的DPoP绑定
+	// resources非空时新访问令牌的受众限定为这些资源，必须在刷新令牌授权的资源之内
+	RefreshToken(ctx context.Context, refreshToken string, resources []string) (*model.TokenPair, error)
 
 	// RevokeToken 吊销令牌
 	RevokeToken(ctx context.Context, tokenString string, tokenType model.TokenType) error
+
+	// RevokeTokenFamily 吊销整个令牌族(同一次颁发的访问令牌与刷新令牌及其刷新产生的后续令牌)
+	RevokeTokenFamily(ctx context.Context, familyID string) error
+
+	// RevokeClientTokens 吊销通过OAuth授权颁发给指定客户端的用户令牌
+	RevokeClientTokens(ctx context.Context, userID, clientID string) error
+
+	// RevokeSession 吊销登录会话及会话期间通过OAuth授权颁发的全部令牌，返回参与该会话的客户端ID
+	RevokeSession(ctx context.Context, sessionID string) ([]string, error)
 }
 
 // tokenService Token服务实现
@@ -39,68 +75,275 @@ type tokenService struct {
 	jwtSecret     []byte
 	accessExpiry  time.Duration
 	refreshExpiry time.Duration
+
+	// 访问令牌使用at+jwt格式时以OIDC签名密钥签名
+	keyService        SigningKeyService
+	issuer            string
+	accessTokenFormat string
+
+	// pairwise客户端的令牌以成对sub代替用户ID，解析时通过成对主体标识表映射回用户
+	pairwiseRepo repository.PairwiseSubjectRepository
 }
 
 // NewTokenService 创建Token服务实例
-func NewTokenService(redisClient *redis.Client, jwtSecret string, accessExpiry, refreshExpiry time.Duration) TokenService {
+// accessTokenFormat为at+jwt时访问令牌使用OIDC签名密钥签名，刷新令牌始终使用jwtSecret
+func NewTokenService(
+	redisClient *redis.Client,
+	jwtSecret string,
+	accessExpiry, refreshExpiry time.Duration,
+	keyService SigningKeyService,
+	issuer string,
+	accessTokenFormat string,
+	pairwiseRepo repository.PairwiseSubjectRepository,
+) TokenService {
 	return &tokenService{
-		redis:         redisClient,
-		jwtSecret:     []byte(jwtSecret),
-		accessExpiry:  accessExpiry,
-		refreshExpiry: refreshExpiry,
+		redis:             redisClient,
+		jwtSecret:         []byte(jwtSecret),
+		accessExpiry:      accessExpiry,
+		refreshExpiry:     refreshExpiry,
+		keyService:        keyService,
+		issuer:            issuer,
+		accessTokenFormat: accessTokenFormat,
+		pairwiseRepo:      pairwiseRepo,
 	}
 }
 
+// sign 签名令牌声明
+// at+jwt格式下访问令牌按RFC 9068补充iss、sub与jti并使用OIDC签名密钥签名，其余令牌使用HS256
+func (s *tokenService) sign(ctx context.Context, mapClaims jwt.MapClaims, subject string) (string, error) {
+	tokenType, _ := mapClaims["type"].(model.TokenType)
+	if s.accessTokenFormat != model.AccessTokenFormatATJWT || tokenType == model.RefreshToken {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, mapClaims)
+		return token.SignedString(s.jwtSecret)
+	}
+
+	mapClaims["iss"] = s.issuer
+	mapClaims["sub"] = subject
+	mapClaims["jti"] = uuid.NewString()
+	return signWithCurrentKey(ctx, s.keyService, mapClaims, "at+jwt")
+}
+
 // generateToken 生成JWT令牌
-func (s *tokenService) generateToken(claims *model.TokenClaims, expiry time.Duration) (string, error) {
-	expiresAt := time.Now().Add(expiry)
+func (s *tokenService) generateToken(ctx context.Context, claims *model.TokenClaims, expiry time.Duration) (string, error) {
+	issuedAt := time.Now()
+	expiresAt := issuedAt.Add(expiry)
+	claims.IssuedAt = issuedAt
 	claims.ExpiresAt = expiresAt
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	mapClaims := jwt.MapClaims{
 		"user_id":    claims.UserID,
 		"app_id":     claims.AppID,
 		"username":   claims.Username,
 		"type":       claims.Type,
+		"iat":        issuedAt.Unix(),
 		"exp":        expiresAt.Unix(),
 		"expires_at": expiresAt,
 		"scope":      claims.Scope,
-	})
+	}
+	// 通过OAuth授权颁发的令牌记录客户端ID，直接登录颁发的令牌不包含
+	if claims.ClientID != "" {
+		mapClaims["client_id"] = claims.ClientID
+	}
+	if claims.FamilyID != "" {
+		mapClaims["family_id"] = claims.FamilyID
+	}
+	if claims.SessionID != "" {
+		mapClaims["sid"] = claims.SessionID
+	}
+	if !claims.AuthTime.IsZero() {
+		mapClaims["auth_time"] = claims.AuthTime.Unix()
+	}
+	if claims.ACR != "" {
+		mapClaims["acr"] = claims.ACR
+	}
+	if len(claims.AMR) > 0 {
+		mapClaims["amr"] = claims.AMR
+	}
+	// 未限定受众的访问令牌只能用于访问本服务；刷新令牌的aud记录授权覆盖的资源，可以为空
+	if len(claims.Audience) > 0 {
+		mapClaims["aud"] = claims.Audience
+	} else if claims.Type != model.RefreshToken {
+		mapClaims["aud"] = s.issuer
+	}
+	if claims.Actor != nil {
+		mapClaims["act"] = claims.Actor
+	}
+	if len(claims.AuthorizationDetails) > 0 {
+		mapClaims["authorization_details"] = claims.AuthorizationDetails
+	}
+	if claims.Confirmation != nil {
+		mapClaims["cnf"] = claims.Confirmation
+	}
+
+	// pairwise客户端的令牌不暴露用户ID与用户名，以成对sub代替；sub与用户的对应关系已由SubjectFor保存
+	subject := claims.UserID
+	if claims.Subject != "" {
+		subject = claims.Subject
+		delete(mapClaims, "user_id")
+		delete(mapClaims, "username")
+		mapClaims["sub"] = subject
+	}
 
-	return token.SignedString(s.jwtSecret)
+	return s.sign(ctx, mapClaims, subject)
+}
+
+// resolvePairwiseSubject 将令牌中的成对sub解析为用户ID
+func (s *tokenService) resolvePairwiseSubject(ctx context.Context, subject string) (string, error) {
+	userID, err := s.pairwiseRepo.GetUserIDBySubject(ctx, subject)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve pairwise subject: %w", err)
+	}
+	if userID == "" {
+		return "", ErrInvalidToken
+	}
+	return userID, nil
+}
+
+// GenerateClientToken 为客户端凭证授权生成服务令牌(不含用户主体，不颁发刷新令牌)
+func (s *tokenService) GenerateClientToken(ctx context.Context, client *model.OAuthClient, opts *model.ClientTokenOptions) (string, time.Duration, error) {
+	issuedAt := time.Now()
+	expiresAt := issuedAt.Add(s.accessExpiry)
+
+	// 服务令牌使用独立的声明集合，不包含user_id/username，at+jwt格式下sub为客户端ID
+	mapClaims := jwt.MapClaims{
+		"client_id":  client.ClientID,
+		"app_id":     client.AppID,
+		"type":       model.ClientAccessToken,
+		"iat":        issuedAt.Unix(),
+		"exp":        expiresAt.Unix(),
+		"expires_at": expiresAt,
+		"scope":      opts.Scope,
+	}
+	if len(opts.Audience) > 0 {
+		mapClaims["aud"] = opts.Audience
+	} else {
+		mapClaims["aud"] = s.issuer
+	}
+	if len(opts.AuthorizationDetails) > 0 {
+		mapClaims["authorization_details"] = opts.AuthorizationDetails
+	}
+	if opts.DPoPJKT != "" {
+		mapClaims["cnf"] = &model.TokenConfirmation{JKT: opts.DPoPJKT}
+	}
+	tokenString, err := s.sign(ctx, mapClaims, client.ClientID)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to generate client token: %w", err)
+	}
+
+	return tokenString, s.accessExpiry, nil
+}
+
+// GenerateExchangedToken 为令牌交换生成访问令牌
+func (s *tokenService) GenerateExchangedToken(ctx context.Context, subject *model.TokenClaims, opts *model.TokenExchangeOptions) (string, time.Duration, error) {
+	expiry := s.accessExpiry
+	if remaining := time.Until(subject.ExpiresAt); remaining < expiry {
+		expiry = remaining
+	}
+	if expiry <= 0 {
+		return "", 0, ErrTokenExpired
+	}
+
+	claims := &model.TokenClaims{
+		UserID:    subject.UserID,
+		AppID:     subject.AppID,
+		Username:  subject.Username,
+		ClientID:  opts.ClientID,
+		FamilyID:  subject.FamilyID,
+		SessionID: subject.SessionID,
+		AuthTime:  subject.AuthTime,
+		ACR:       subject.ACR,
+		AMR:       subject.AMR,
+		Type:      model.AccessToken,
+		Scope:     opts.Scope,
+		Audience:  opts.Audience,
+		Actor:     opts.Actor,
+		Subject:   opts.Subject,
+
+		Confirmation: dpopConfirmation(opts.DPoPJKT),
+	}
+	token, err := s.generateToken(ctx, claims, expiry)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to generate exchanged token: %w", err)
+	}
+
+	return token, expiry, nil
 }
 
 // GenerateTokenPair 生成访问令牌和刷新令牌对
 func (s *tokenService) GenerateTokenPair(ctx context.Context, user *model.User, scope string) (*model.TokenPair, error) {
+	return s.GenerateTokenPairWithOptions(ctx, user, &model.TokenOptions{Scope: scope})
+}
+
+// GenerateTokenPairWithOptions 按选项生成令牌对
+func (s *tokenService) GenerateTokenPairWithOptions(ctx context.Context, user *model.User, opts *model.TokenOptions) (*model.TokenPair, error) {
+	familyID := opts.FamilyID
+	if familyID == "" {
+		familyID = uuid.NewString()
+	}
+	// 直接登录时令牌颁发时间即认证时间
+	authTime := opts.AuthTime
+	if authTime.IsZero() {
+		authTime = time.Now()
+	}
+	// 直接登录开启新的会话，会话ID即登录令牌族ID
+	sessionID := opts.SessionID
+	if sessionID == "" && opts.ClientID == "" {
+		sessionID = familyID
+	}
+
 	// 生成访问令牌
 	accessClaims := &model.TokenClaims{
-		UserID:   user.ID,
-		AppID:    user.AppID,
-		Username: user.Username,
-		Type:     model.AccessToken,
-		Scope:    scope,
+		UserID:    user.ID,
+		AppID:     user.AppID,
+		Username:  user.Username,
+		ClientID:  opts.ClientID,
+		FamilyID:  familyID,
+		SessionID: sessionID,
+		AuthTime:  authTime,
+		ACR:       opts.ACR,
+		AMR:       opts.AMR,
+		Type:      model.AccessToken,
+		Scope:     opts.Scope,
+		Audience:  accessTokenAudience(opts),
+		Subject:   opts.Subject,
+
+		AuthorizationDetails: opts.AuthorizationDetails,
+		Confirmation:         dpopConfirmation(opts.DPoPJKT),
 	}
-	accessToken, err := s.generateToken(accessClaims, s.accessExpiry)
+	accessToken, err := s.generateToken(ctx, accessClaims, s.accessExpiry)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	// 生成刷新令牌
 	refreshClaims := &model.TokenClaims{
-		UserID:   user.ID,
-		AppID:    user.AppID,
-		Username: user.Username,
-		Type:     model.RefreshToken,
-		Scope:    scope,
+		UserID:    user.ID,
+		AppID:     user.AppID,
+		Username:  user.Username,
+		ClientID:  opts.ClientID,
+		FamilyID:  familyID,
+		SessionID: sessionID,
+		AuthTime:  authTime,
+		ACR:       opts.ACR,
+		AMR:       opts.AMR,
+		Type:      model.RefreshToken,
+		Scope:     opts.Scope,
+		Audience:  opts.Resources,
+		Subject:   opts.Subject,
+
+		AuthorizationDetails: opts.AuthorizationDetails,
+		Confirmation:         dpopConfirmation(opts.DPoPJKT),
 	}
-	refreshToken, err := s.generateToken(refreshClaims, s.refreshExpiry)
+	refreshToken, err := s.generateToken(ctx, refreshClaims, s.refreshExpiry)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
-	// 将刷新令牌存储到Redis
-	refreshKey := fmt.Sprintf("refresh_token:%s", user.ID)
-	if err := s.redis.Set(ctx, refreshKey, refreshToken, s.refreshExpiry); err != nil {
-		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	// 记录用户在该客户端下的令牌族，撤销授权时据此吊销；刷新时重复记录以延长记录的有效期
+	if opts.ClientID != "" {
+		if err := s.trackClientFamily(ctx, user.ID, opts.ClientID, sessionID, familyID); err != nil {
+			return nil, err
+		}
 	}
 
 	return &model.TokenPair{
@@ -111,14 +354,108 @@ func (s *tokenService) GenerateTokenPair(ctx context.Context, user *model.User,
 	}, nil
 }
 
+// GenerateAccessToken 按选项只生成访问令牌
+func (s *tokenService) GenerateAccessToken(ctx context.Context, user *model.User, opts *model.TokenOptions) (string, time.Duration, error) {
+	familyID := uuid.NewString()
+	authTime := opts.AuthTime
+	if authTime.IsZero() {
+		authTime = time.Now()
+	}
+
+	claims := &model.TokenClaims{
+		UserID:    user.ID,
+		AppID:     user.AppID,
+		Username:  user.Username,
+		ClientID:  opts.ClientID,
+		FamilyID:  familyID,
+		SessionID: opts.SessionID,
+		AuthTime:  authTime,
+		ACR:       opts.ACR,
+		AMR:       opts.AMR,
+		Type:      model.AccessToken,
+		Scope:     opts.Scope,
+		Audience:  accessTokenAudience(opts),
+		Subject:   opts.Subject,
+
+		AuthorizationDetails: opts.AuthorizationDetails,
+		Confirmation:         dpopConfirmation(opts.DPoPJKT),
+	}
+	accessToken, err := s.generateToken(ctx, claims, s.accessExpiry)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	if opts.ClientID != "" {
+		if err := s.trackClientFamily(ctx, user.ID, opts.ClientID, opts.SessionID, familyID); err != nil {
+			return "", 0, err
+		}
+	}
+
+	return accessToken, s.accessExpiry, nil
+}
+
+// accessTokenAudience 访问令牌的受众，未指定时取授权覆盖的全部资源
+func accessTokenAudience(opts *model.TokenOptions) []string {
+	if len(opts.Audience) > 0 {
+		return opts.Audience
+	}
+	return opts.Resources
+}
+
 // ValidateToken 验证令牌
 func (s *tokenService) ValidateToken(ctx context.Context, tokenString string, tokenType model.TokenType) (*model.TokenClaims, error) {
-	// 解析JWT令牌
+	claims, err := s.ParseToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查令牌类型
+	if claims.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// ValidateTokenForAudience 验证令牌并校验受众
+func (s *tokenService) ValidateTokenForAudience(ctx context.Context, tokenString string, tokenType model.TokenType, audience string) (*model.TokenClaims, error) {
+	claims, err := s.ValidateToken(ctx, tokenString, tokenType)
+	if err != nil {
+		return nil, err
+	}
+
+	if audience == "" {
+		audience = s.issuer
+	}
+	if !audienceAllows(claims.Audience, audience) {
+		log.Printf("Token audience %v does not include %s", claims.Audience, audience)
+		return nil, ErrInvalidAudience
+	}
+	return claims, nil
+}
+
+// audienceAllows 判断令牌能否用于指定受众
+// 解析令牌时没有aud的访问令牌已视为只面向本服务，受众必须明确包含该资源
+func audienceAllows(aud []string, audience string) bool {
+	return containsString(aud, audience)
+}
+
+// ParseToken 解析并验证任意类型的令牌
+func (s *tokenService) ParseToken(ctx context.Context, tokenString string) (*model.TokenClaims, error) {
+	// 解析JWT令牌，迁移期间同时接受HS256令牌与OIDC签名密钥签名的at+jwt令牌
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			return s.jwtSecret, nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+			if typ, _ := token.Header["typ"].(string); typ != "at+jwt" {
+				return nil, fmt.Errorf("unexpected token type: %v", token.Header["typ"])
+			}
+			kid, _ := token.Header["kid"].(string)
+			return s.keyService.VerificationKey(ctx, kid, token.Method.Alg())
+		default:
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return s.jwtSecret, nil
 	})
 
 	if err != nil {
@@ -131,8 +468,11 @@ func (s *tokenService) ValidateToken(ctx context.Context, tokenString string, to
 		return nil, ErrInvalidToken
 	}
 
-	// 检查令牌类型
-	if claims["type"].(string) != string(tokenType) {
+	claimType, _ := claims["type"].(string)
+	if claimType == "" {
+		return nil, ErrInvalidToken
+	}
+	if iss, ok := claims["iss"]; ok && iss != s.issuer {
 		return nil, ErrInvalidToken
 	}
 
@@ -146,6 +486,18 @@ func (s *tokenService) ValidateToken(ctx context.Context, tokenString string, to
 		return nil, ErrTokenRevoked
 	}
 
+	// 检查所属令牌族是否已被吊销
+	familyID, _ := claims["family_id"].(string)
+	if familyID != "" {
+		familyRevoked, err := s.redis.Exists(ctx, fmt.Sprintf("revoked_family:%s", familyID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token family revocation: %w", err)
+		}
+		if familyRevoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
 	// 获取过期时间
 	exp, ok := claims["exp"].(float64)
 	if !ok {
@@ -158,35 +510,146 @@ func (s *tokenService) ValidateToken(ctx context.Context, tokenString string, to
 		return nil, ErrTokenExpired
 	}
 
+	// 旧版本颁发的令牌可能没有iat
+	var issuedAt time.Time
+	if iat, ok := claims["iat"].(float64); ok {
+		issuedAt = time.Unix(int64(iat), 0)
+	}
+
+	var authTime time.Time
+	if at, ok := claims["auth_time"].(float64); ok {
+		authTime = time.Unix(int64(at), 0)
+	}
+
 	// 获取 scope 字段
 	scope, _ := claims["scope"].(string)
+	acr, _ := claims["acr"].(string)
+	var amr []string
+	if values, ok := claims["amr"].([]interface{}); ok {
+		for _, v := range values {
+			if method, ok := v.(string); ok {
+				amr = append(amr, method)
+			}
+		}
+	}
+
+	// 服务令牌不包含用户字段，用户令牌不包含client_id
+	userID, _ := claims["user_id"].(string)
+	appID, _ := claims["app_id"].(string)
+	username, _ := claims["username"].(string)
+	clientID, _ := claims["client_id"].(string)
+	sessionID, _ := claims["sid"].(string)
+	// pairwise客户端的令牌以成对sub代替用户ID
+	var subject string
+	if _, ok := claims["user_id"]; !ok && model.TokenType(claimType) != model.ClientAccessToken {
+		subject, _ = claims["sub"].(string)
+		if subject == "" {
+			return nil, ErrInvalidToken
+		}
+		if userID, err = s.resolvePairwiseSubject(ctx, subject); err != nil {
+			return nil, err
+		}
+	}
+	audience, _ := claims.GetAudience()
+	// 旧版本颁发的访问令牌可能没有aud，只能用于访问本服务
+	if len(audience) == 0 && model.TokenType(claimType) != model.RefreshToken {
+		audience = jwt.ClaimStrings{s.issuer}
+	}
 
 	return &model.TokenClaims{
-		UserID:    claims["user_id"].(string),
-		AppID:     claims["app_id"].(string),
-		Username:  claims["username"].(string),
-		Type:      model.TokenType(claims["type"].(string)),
+		UserID:    userID,
+		AppID:     appID,
+		Username:  username,
+		ClientID:  clientID,
+		FamilyID:  familyID,
+		SessionID: sessionID,
+		Type:      model.TokenType(claimType),
+		IssuedAt:  issuedAt,
+		AuthTime:  authTime,
+		ACR:       acr,
+		AMR:       amr,
 		ExpiresAt: expiresAt,
 		Scope:     scope,
+		Audience:  audience,
+		Actor:     parseTokenActor(claims["act"]),
+		Subject:   subject,
+
+		AuthorizationDetails: parseAuthorizationDetailsClaim(claims["authorization_details"]),
+		Confirmation:         parseTokenConfirmation(claims["cnf"]),
 	}, nil
 }
 
+// parseTokenActor 解析act声明，嵌套的act递归解析
+func parseTokenActor(value interface{}) *model.TokenActor {
+	act, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	actor := &model.TokenActor{Actor: parseTokenActor(act["act"])}
+	actor.Subject, _ = act["sub"].(string)
+	actor.ClientID, _ = act["client_id"].(string)
+	return actor
+}
+
+// parseTokenConfirmation 解析cnf声明，只识别DPoP公钥指纹
+func parseTokenConfirmation(value interface{}) *model.TokenConfirmation {
+	cnf, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	jkt, _ := cnf["jkt"].(string)
+	return dpopConfirmation(jkt)
+}
+
+// dpopConfirmation 构建DPoP绑定的cnf声明，jkt为空时令牌不绑定
+func dpopConfirmation(jkt string) *model.TokenConfirmation {
+	if jkt == "" {
+		return nil
+	}
+	return &model.TokenConfirmation{JKT: jkt}
+}
+
+// parseAuthorizationDetailsClaim 解析authorization_details声明
+func parseAuthorizationDetailsClaim(value interface{}) []model.AuthorizationDetail {
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	details := make([]model.AuthorizationDetail, 0, len(values))
+	for _, v := range values {
+		if detail, ok := v.(map[string]interface{}); ok {
+			details = append(details, detail)
+		}
+	}
+	return details
+}
+
 // RefreshToken 刷新访问令牌
-func (s *tokenService) RefreshToken(ctx context.Context, refreshToken string) (*model.TokenPair, error) {
+func (s *tokenService) RefreshToken(ctx context.Context, refreshToken string, resources []string) (*model.TokenPair, error) {
 	// 验证刷新令牌
 	claims, err := s.ValidateToken(ctx, refreshToken, model.RefreshToken)
 	if err != nil {
 		return nil, err
 	}
+	// 刷新令牌的受众即授权覆盖的资源，新访问令牌只能从中选择
+	if !resourcesSubset(claims.Audience, resources) {
+		log.Printf("Requested resources %v exceed those granted to the refresh token %v", resources, claims.Audience)
+		return nil, ErrInvalidTarget
+	}
 
-	// 检查Redis中存储的刷新令牌是否匹配
-	refreshKey := fmt.Sprintf("refresh_token:%s", claims.UserID)
-	storedToken, err := s.redis.Get(ctx, refreshKey)
+	// 刷新令牌只能使用一次，使用记录保留到令牌过期
+	// 已轮换的令牌再次出现说明令牌可能已泄露，无法区分合法持有者与攻击者，吊销整个令牌族
+	usedKey := s.usedRefreshTokenKey(refreshToken)
+	first, err := s.redis.SetNX(ctx, usedKey, "used", time.Until(claims.ExpiresAt)).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stored refresh token: %w", err)
+		return nil, fmt.Errorf("failed to record refresh token use: %w", err)
 	}
-	if storedToken != refreshToken {
-		return nil, ErrInvalidToken
+	if !first {
+		log.Printf("Refresh token reuse detected for user %s, revoking token family %s", claims.UserID, claims.FamilyID)
+		if err := s.RevokeTokenFamily(ctx, claims.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenRevoked
 	}
 
 	// 生成新的令牌对
@@ -195,7 +658,21 @@ func (s *tokenService) RefreshToken(ctx context.Context, refreshToken string) (*
 		AppID:    claims.AppID,
 		Username: claims.Username,
 	}
-	return s.GenerateTokenPair(ctx, user, claims.Scope)
+	return s.GenerateTokenPairWithOptions(ctx, user, &model.TokenOptions{
+		ClientID:  claims.ClientID,
+		Scope:     claims.Scope,
+		FamilyID:  claims.FamilyID,
+		SessionID: claims.SessionID,
+		AuthTime:  claims.AuthTime,
+		ACR:       claims.ACR,
+		AMR:       claims.AMR,
+
+		AuthorizationDetails: claims.AuthorizationDetails,
+		DPoPJKT:              claims.DPoPJKT(),
+		Resources:            claims.Audience,
+		Audience:             resources,
+		Subject:              claims.Subject,
+	})
 }
 
 // RevokeToken 吊销令牌
@@ -209,23 +686,145 @@ func (s *tokenService) RevokeToken(ctx context.Context, tokenString string, toke
 	// 将令牌加入吊销列表
 	revokedKey := fmt.Sprintf("revoked_token:%s", tokenString)
 	var expiry time.Duration
-	if tokenType == model.AccessToken {
-		expiry = s.accessExpiry
-	} else {
+	if tokenType == model.RefreshToken {
 		expiry = s.refreshExpiry
+	} else {
+		expiry = s.accessExpiry
 	}
 
 	if err := s.redis.Set(ctx, revokedKey, "revoked", expiry); err != nil {
 		return fmt.Errorf("failed to revoke token: %w", err)
 	}
 
-	// 如果是刷新令牌，同时删除存储的刷新令牌
+	// 如果是刷新令牌，同时吊销同族的访问令牌
 	if tokenType == model.RefreshToken {
-		refreshKey := fmt.Sprintf("refresh_token:%s", claims.UserID)
-		if err := s.redis.Del(ctx, refreshKey); err != nil {
-			return fmt.Errorf("failed to delete refresh token: %w", err)
+		if err := s.RevokeTokenFamily(ctx, claims.FamilyID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RevokeTokenFamily 吊销整个令牌族
+func (s *tokenService) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	// 旧版本颁发的令牌没有令牌族
+	if familyID == "" {
+		return nil
+	}
+
+	// 令牌族中寿命最长的是刷新令牌，吊销标记保留相同时长即可
+	familyKey := fmt.Sprintf("revoked_family:%s", familyID)
+	if err := s.redis.Set(ctx, familyKey, "revoked", s.refreshExpiry); err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeClientTokens 吊销通过OAuth授权颁发给指定客户端的用户令牌
+func (s *tokenService) RevokeClientTokens(ctx context.Context, userID, clientID string) error {
+	familiesKey := s.clientFamiliesKey(userID, clientID)
+	familyIDs, err := s.redis.SMembers(ctx, familiesKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get token families: %w", err)
+	}
+
+	for _, familyID := range familyIDs {
+		if err := s.RevokeTokenFamily(ctx, familyID); err != nil {
+			return err
 		}
 	}
 
+	return s.redis.Del(ctx, familiesKey)
+}
+
+// RevokeSession 吊销登录会话及会话期间通过OAuth授权颁发的全部令牌
+func (s *tokenService) RevokeSession(ctx context.Context, sessionID string) ([]string, error) {
+	if sessionID == "" {
+		return nil, nil
+	}
+
+	// 登录令牌族ID即会话ID
+	if err := s.RevokeTokenFamily(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	familiesKey := s.sessionFamiliesKey(sessionID)
+	familyIDs, err := s.redis.SMembers(ctx, familiesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session token families: %w", err)
+	}
+	for _, familyID := range familyIDs {
+		if err := s.RevokeTokenFamily(ctx, familyID); err != nil {
+			return nil, err
+		}
+	}
+
+	clientsKey := s.sessionClientsKey(sessionID)
+	clientIDs, err := s.redis.SMembers(ctx, clientsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session clients: %w", err)
+	}
+
+	if err := s.redis.Del(ctx, familiesKey, clientsKey); err != nil {
+		return nil, fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return clientIDs, nil
+}
+
+// trackClientFamily 记录用户在客户端下的令牌族
+func (s *tokenService) trackClientFamily(ctx context.Context, userID, clientID, sessionID, familyID string) error {
+	familiesKey := s.clientFamiliesKey(userID, clientID)
+	if err := s.redis.SAdd(ctx, familiesKey, familyID).Err(); err != nil {
+		return fmt.Errorf("failed to track token family: %w", err)
+	}
+	if err := s.redis.Expire(ctx, familiesKey, s.refreshExpiry).Err(); err != nil {
+		return fmt.Errorf("failed to track token family: %w", err)
+	}
+
+	// 记录会话期间授权的客户端及其令牌族，会话结束时一并吊销并通知客户端
+	if sessionID != "" {
+		return s.trackSession(ctx, sessionID, clientID, familyID)
+	}
+	return nil
+}
+
+// trackSession 记录会话中授权的客户端与令牌族
+func (s *tokenService) trackSession(ctx context.Context, sessionID, clientID, familyID string) error {
+	familiesKey := s.sessionFamiliesKey(sessionID)
+	clientsKey := s.sessionClientsKey(sessionID)
+	if err := s.redis.SAdd(ctx, familiesKey, familyID).Err(); err != nil {
+		return fmt.Errorf("failed to track session: %w", err)
+	}
+	if err := s.redis.SAdd(ctx, clientsKey, clientID).Err(); err != nil {
+		return fmt.Errorf("failed to track session: %w", err)
+	}
+	for _, key := range []string{familiesKey, clientsKey} {
+		if err := s.redis.Expire(ctx, key, s.refreshExpiry).Err(); err != nil {
+			return fmt.Errorf("failed to track session: %w", err)
+		}
+	}
 	return nil
 }
+
+// usedRefreshTokenKey 构建已使用的刷新令牌键
+func (s *tokenService) usedRefreshTokenKey(refreshToken string) string {
+	return fmt.Sprintf("used_refresh_token:%s", hashToken(refreshToken))
+}
+
+// sessionFamiliesKey 构建会话中令牌族集合键
+func (s *tokenService) sessionFamiliesKey(sessionID string) string {
+	return fmt.Sprintf("session_families:%s", sessionID)
+}
+
+// sessionClientsKey 构建会话中客户端集合键
+func (s *tokenService) sessionClientsKey(sessionID string) string {
+	return fmt.Sprintf("session_clients:%s", sessionID)
+}
+
+// clientFamiliesKey 构建用户在客户端下的令牌族集合键
+func (s *tokenService) clientFamiliesKey(userID, clientID string) string {
+	return fmt.Sprintf("client_families:%s:%s", userID, clientID)
+}
@@ -48,9 +48,17 @@ func (s *verificationPluginService) checkPluginVerification(ctx context.Context,
 
 // GetRequiredPlugins 获取指定操作需要的插件
 func (s *verificationPluginService) GetRequiredPlugins(ctx context.Context, appID string, action string, verificationContext map[string]interface{}, userID string) ([]model.PluginRequirement, error) {
+	// 授权请求要求多因素认证强度时，至少需要完成一个能提供第二因素的插件
+	acrValues, _ := verificationContext["acr_values"].(string)
+	requireMFA := requiredACRLevel(acrValues) >= acrLevels[model.ACRMultiFactor]
+
 	// 获取App已安装的插件列表
 	installedPlugins := s.pluginManager.ListPlugins(appID)
 	if len(installedPlugins) == 0 {
+		if requireMFA {
+			log.Printf("[Plugin] acr_values=%q 要求多因素认证，但应用未安装插件", acrValues)
+			return nil, ErrUnmetAuthenticationRequirements
+		}
 		return nil, nil
 	}
 
@@ -60,6 +68,8 @@ func (s *verificationPluginService) GetRequiredPlugins(ctx context.Context, appI
 		return nil, err
 	}
 
+	var mfaCandidates []model.PluginRequirement
+
 	// 根据action筛选需要的插件
 	var requirements []model.PluginRequirement
 	for _, config := range configs {
@@ -108,6 +118,14 @@ func (s *verificationPluginService) GetRequiredPlugins(ctx context.Context, appI
 		}
 
 		if !needsVerify {
+			if requireMFA && pluginAMR[config.Name] != "" {
+				mfaCandidates = append(mfaCandidates, model.PluginRequirement{
+					Name:     config.Name,
+					Required: true,
+					Stage:    metadata.Stage,
+					Status:   model.PluginStatusPending,
+				})
+			}
 			continue
 		}
 
@@ -120,12 +138,32 @@ func (s *verificationPluginService) GetRequiredPlugins(ctx context.Context, appI
 		})
 	}
 
+	// 没有能提供第二因素的插件时，重新登录也无法达到要求的认证强度
+	if requireMFA && !hasMFAPlugin(requirements) && len(mfaCandidates) == 0 {
+		log.Printf("[Plugin] acr_values=%q 要求多因素认证，但应用没有可用的多因素认证插件", acrValues)
+		return nil, ErrUnmetAuthenticationRequirements
+	}
+	if requireMFA && !hasMFAPlugin(requirements) {
+		log.Printf("[Plugin] acr_values=%q 要求多因素认证，插件 %s 添加到验证列表", acrValues, mfaCandidates[0].Name)
+		requirements = append(requirements, mfaCandidates[0])
+	}
+
 	if len(requirements) > 0 {
 		log.Printf("[Plugin] 需要验证的插件列表: %+v", requirements)
 	}
 	return requirements, nil
 }
 
+// hasMFAPlugin 判断插件列表中是否已有能提供第二因素的插件
+func hasMFAPlugin(requirements []model.PluginRequirement) bool {
+	for _, requirement := range requirements {
+		if pluginAMR[requirement.Name] != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // getActiveSession 获取活动会话
 func (s *verificationPluginService) getActiveSession(ctx context.Context, appID string, userID string) (*model.VerificationSession, error) {
 	session, err := s.sessionRepo.GetActiveSession(ctx, appID, userID)
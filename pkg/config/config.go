@@ -25,6 +25,8 @@ type ServerConfig struct {
 	Port        int
 	Mode        string
 	AuthEnabled bool `mapstructure:"auth_enabled"` // 是否启用认证
+	// TemplatePath 托管登录页面模板目录，默认templates/pages
+	TemplatePath string `mapstructure:"template_path"`
 }
 
 // MongoDBConfig MongoDB配置
@@ -46,8 +48,9 @@ type RedisConfig struct {
 // JWTConfig JWT配置
 type JWTConfig struct {
 	Secret             string
-	AccessTokenExpire  int `mapstructure:"access_token_expire"`
-	RefreshTokenExpire int `mapstructure:"refresh_token_expire"`
+	AccessTokenExpire  int    `mapstructure:"access_token_expire"`
+	RefreshTokenExpire int    `mapstructure:"refresh_token_expire"`
+	AccessTokenFormat  string `mapstructure:"access_token_format"` // 访问令牌格式：jwt(默认，HS256)或at+jwt(RFC 9068，使用OIDC签名密钥)
 }
 
 // OIDCConfig OIDC配置
@@ -55,6 +58,10 @@ type OIDCConfig struct {
 	Issuer         string `mapstructure:"issuer"`           // OIDC颁发者标识符
 	PrivateKeyPath string `mapstructure:"private_key_path"` // RSA私钥路径
 	PublicKeyPath  string `mapstructure:"public_key_path"`  // RSA公钥路径
+	PairwiseSalt   string `mapstructure:"pairwise_salt"`    // 成对主体标识的派生盐，未配置时使用JWT密钥
+
+	// DeviceVerificationURI 设备授权返回给用户的验证地址，应指向应用提供的用户码确认页面，未配置时为设备确认接口
+	DeviceVerificationURI string `mapstructure:"device_verification_uri"`
 }
 
 // AuditConfig 审计配置
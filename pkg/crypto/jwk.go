@@ -0,0 +1,292 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+)
+
+// GenerateRSAKey 生成2048位RSA私钥
+func GenerateRSAKey() (*rsa.PrivateKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+	return key, nil
+}
+
+// EncodeRSAPrivateKeyPEM 将RSA私钥编码为PKCS#1 PEM
+func EncodeRSAPrivateKeyPEM(key *rsa.PrivateKey) string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
+
+// ParseRSAPrivateKeyPEM 解析PKCS#1 PEM格式的RSA私钥
+func ParseRSAPrivateKeyPEM(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key PEM")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return key, nil
+}
+
+// GenerateECKey 生成P-256椭圆曲线私钥，用于ES256签名
+func GenerateECKey() (*ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+	return key, nil
+}
+
+// EncodePrivateKeyPEM 将私钥编码为PEM，RSA私钥使用PKCS#1，EC私钥使用SEC 1
+func EncodePrivateKeyPEM(key crypto.Signer) (string, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return EncodeRSAPrivateKeyPEM(k), nil
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal private key: %w", err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), nil
+	default:
+		return "", fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// ParsePrivateKeyPEM 解析PKCS#1、SEC 1或PKCS#8 PEM格式的RSA或EC私钥
+func ParsePrivateKeyPEM(data string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key PEM")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return ParseRSAPrivateKeyPEM(data)
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return k, nil
+		case *ecdsa.PrivateKey:
+			return k, nil
+		}
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	default:
+		return nil, fmt.Errorf("unsupported private key PEM type: %s", block.Type)
+	}
+}
+
+// PublicKeyJWK 将RSA或EC公钥转换为JWK公钥参数(不含kid、use与alg)
+func PublicKeyJWK(key crypto.PublicKey) (map[string]interface{}, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		n, e := RSAPublicJWK(k)
+		return map[string]interface{}{"kty": "RSA", "n": n, "e": e}, nil
+	case *ecdsa.PublicKey:
+		// 坐标按曲线长度补齐前导零
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return map[string]interface{}{
+			"kty": "EC",
+			"crv": k.Curve.Params().Name,
+			"x":   base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size))),
+			"y":   base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size))),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// RSAPublicJWK 将RSA公钥转换为JWK的n与e参数(base64url编码)
+func RSAPublicJWK(key *rsa.PublicKey) (n, e string) {
+	n = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	return n, e
+}
+
+// RSAThumbprint 计算RSA公钥的JWK指纹(RFC 7638)，用作稳定的kid
+func RSAThumbprint(key *rsa.PublicKey) string {
+	n, e := RSAPublicJWK(key)
+	// 必需成员按字典序排列且不含空白
+	canonical := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, e, n)
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// PublicJWK 解析后的JWK公钥
+type PublicJWK struct {
+	KeyID     string
+	KeyType   string
+	Algorithm string
+	Use       string
+	Key       interface{} // *rsa.PublicKey 或 *ecdsa.PublicKey
+}
+
+// jsonWebKey JWK的JSON表示(RFC 7517)，仅包含公钥参数
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKSet 解析JWK Set中的RSA与EC公钥
+func ParseJWKSet(data []byte) ([]*PublicJWK, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWK set: %w", err)
+	}
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("JWK set contains no keys")
+	}
+
+	keys := make([]*PublicJWK, 0, len(set.Keys))
+	for i, jwk := range set.Keys {
+		key, err := parsePublicJWK(&jwk)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK at index %d: %w", i, err)
+		}
+		keys = append(keys, &PublicJWK{
+			KeyID:     jwk.Kid,
+			KeyType:   jwk.Kty,
+			Algorithm: jwk.Alg,
+			Use:       jwk.Use,
+			Key:       key,
+		})
+	}
+	return keys, nil
+}
+
+// ParsePublicJWK 解析单个JWK公钥，包含私钥参数的JWK视为无效
+func ParsePublicJWK(data []byte) (*PublicJWK, error) {
+	var jwk struct {
+		jsonWebKey
+		D string `json:"d"`
+	}
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, fmt.Errorf("failed to parse JWK: %w", err)
+	}
+	if jwk.D != "" {
+		return nil, fmt.Errorf("JWK must not contain private key parameters")
+	}
+
+	key, err := parsePublicJWK(&jwk.jsonWebKey)
+	if err != nil {
+		return nil, err
+	}
+	return &PublicJWK{
+		KeyID:     jwk.Kid,
+		KeyType:   jwk.Kty,
+		Algorithm: jwk.Alg,
+		Use:       jwk.Use,
+		Key:       key,
+	}, nil
+}
+
+// PublicKeyThumbprint 计算RSA或EC公钥的JWK指纹(RFC 7638)
+func PublicKeyThumbprint(key interface{}) (string, error) {
+	var canonical string
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return RSAThumbprint(k), nil
+	case *ecdsa.PublicKey:
+		// 坐标按曲线长度补齐前导零
+		size := (k.Curve.Params().BitSize + 7) / 8
+		x := base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size)))
+		y := base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size)))
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, k.Curve.Params().Name, x, y)
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", key)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// parsePublicJWK 根据kty解析公钥参数
+func parsePublicJWK(jwk *jsonWebKey) (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", jwk.Crv)
+		}
+		x, err := decodeJWKInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", jwk.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", jwk.Kty)
+	}
+}
+
+// decodeJWKInt 解码base64url编码的大整数参数
+func decodeJWKInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing key parameter")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key parameter: %w", err)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
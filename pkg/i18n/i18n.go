@@ -0,0 +1,74 @@
+package i18n
+
+import (
+	"strings"
+)
+
+const (
+	// LocaleEnglish 英文
+	LocaleEnglish = "en"
+	// LocaleChinese 简体中文
+	LocaleChinese = "zh-CN"
+	// DefaultLocale 无法匹配任何偏好时使用的语言
+	DefaultLocale = LocaleEnglish
+)
+
+// catalogs 各语言的页面文案
+var catalogs = map[string]map[string]string{
+	LocaleEnglish: english,
+	LocaleChinese: chinese,
+}
+
+// Match 按偏好顺序选择支持的语言
+// 每个偏好可以是空格分隔的ui_locales(OIDC Core 3.1.2.1)或逗号分隔的Accept-Language，
+// 语言标签先按完整标签匹配，再按主语言匹配(如zh-TW匹配zh-CN)
+func Match(preferences ...string) string {
+	for _, preference := range preferences {
+		for _, tag := range parseTags(preference) {
+			if locale, ok := matchTag(tag); ok {
+				return locale
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// Messages 获取语言的页面文案，不支持的语言返回默认语言的文案
+func Messages(locale string) map[string]string {
+	if messages, ok := catalogs[locale]; ok {
+		return messages
+	}
+	return catalogs[DefaultLocale]
+}
+
+// parseTags 解析语言偏好中的语言标签，忽略Accept-Language的权重参数
+func parseTags(preference string) []string {
+	fields := strings.FieldsFunc(preference, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+	tags := make([]string, 0, len(fields))
+	for _, field := range fields {
+		tag, _, _ := strings.Cut(field, ";")
+		if tag = strings.TrimSpace(tag); tag != "" && tag != "*" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// matchTag 将语言标签匹配到支持的语言
+func matchTag(tag string) (string, bool) {
+	for locale := range catalogs {
+		if strings.EqualFold(locale, tag) {
+			return locale, true
+		}
+	}
+	primary, _, _ := strings.Cut(tag, "-")
+	for locale := range catalogs {
+		localePrimary, _, _ := strings.Cut(locale, "-")
+		if strings.EqualFold(localePrimary, primary) {
+			return locale, true
+		}
+	}
+	return "", false
+}
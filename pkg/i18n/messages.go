@@ -0,0 +1,79 @@
+package i18n
+
+// english 英文文案
+var english = map[string]string{
+	"login.title":           "Sign in",
+	"login.subtitle":        "to continue to %s",
+	"login.username":        "Username",
+	"login.password":        "Password",
+	"login.submit":          "Sign in",
+	"login.invalid":         "Invalid username or password.",
+	"login.disabled":        "This account has been disabled.",
+	"verify.title":          "Verify your identity",
+	"verify.subtitle":       "Complete the additional verification to continue.",
+	"verify.code":           "Verification code",
+	"verify.submit":         "Verify",
+	"verify.send":           "Send code",
+	"verify.sent":           "A verification code has been sent to your email.",
+	"verify.invalid":        "The verification code is incorrect or has expired.",
+	"verify.plugin.totp":    "Enter the code from your authenticator app.",
+	"verify.plugin.email":   "Enter the code sent to your email.",
+	"consent.title":         "Authorize access",
+	"consent.subtitle":      "%s is requesting access to your account.",
+	"consent.scopes":        "This will allow the application to:",
+	"consent.details":       "Additional authorization details",
+	"consent.approve":       "Allow",
+	"consent.deny":          "Deny",
+	"error.title":           "Something went wrong",
+	"error.invalid_client":  "The application is unknown or has been disabled.",
+	"error.invalid_request": "The authorization request is invalid.",
+	"error.invalid_scope":   "The application requested permissions it is not allowed to use.",
+	"error.login_required":  "You need to sign in as a different user to continue.",
+	"error.session_expired": "Your sign-in session has expired. Return to the application and try again.",
+	"error.server_error":    "An unexpected error occurred. Please try again later.",
+	"redirect.title":        "Redirecting",
+	"redirect.continue":     "Continue",
+	"logout.title":          "Sign out",
+	"logout.subtitle":       "Do you want to sign out of your account?",
+	"logout.submit":         "Sign out",
+	"logout.done":           "You have been signed out",
+}
+
+// chinese 简体中文文案
+var chinese = map[string]string{
+	"login.title":           "登录",
+	"login.subtitle":        "继续访问%s",
+	"login.username":        "用户名",
+	"login.password":        "密码",
+	"login.submit":          "登录",
+	"login.invalid":         "用户名或密码错误。",
+	"login.disabled":        "该账号已被禁用。",
+	"verify.title":          "验证身份",
+	"verify.subtitle":       "请完成额外的身份验证以继续。",
+	"verify.code":           "验证码",
+	"verify.submit":         "验证",
+	"verify.send":           "发送验证码",
+	"verify.sent":           "验证码已发送到您的邮箱。",
+	"verify.invalid":        "验证码错误或已过期。",
+	"verify.plugin.totp":    "请输入身份验证器应用中的验证码。",
+	"verify.plugin.email":   "请输入发送到您邮箱的验证码。",
+	"consent.title":         "授权访问",
+	"consent.subtitle":      "%s 请求访问您的账号。",
+	"consent.scopes":        "授权后该应用将可以：",
+	"consent.details":       "其他授权详情",
+	"consent.approve":       "允许",
+	"consent.deny":          "拒绝",
+	"error.title":           "出错了",
+	"error.invalid_client":  "应用不存在或已被禁用。",
+	"error.invalid_request": "授权请求无效。",
+	"error.invalid_scope":   "应用申请了不允许使用的权限。",
+	"error.login_required":  "需要以其他用户身份登录才能继续。",
+	"error.session_expired": "登录会话已过期，请返回应用重新操作。",
+	"error.server_error":    "发生意外错误，请稍后重试。",
+	"redirect.title":        "正在跳转",
+	"redirect.continue":     "继续",
+	"logout.title":          "退出登录",
+	"logout.subtitle":       "确定要退出当前账号吗？",
+	"logout.submit":         "退出登录",
+	"logout.done":           "您已退出登录",
+}
@@ -24,6 +24,20 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+const (
+	// contextKeyAuditEvent 上下文中处理器指定的审计事件类型的键
+	contextKeyAuditEvent = "audit_event"
+	// contextKeyAuditDetails 上下文中处理器附加的审计详情的键
+	contextKeyAuditDetails = "audit_details"
+)
+
+// SetAuditEvent 由处理器指定本次请求的审计事件类型与附加详情
+// 用于路径无法区分的事件，如授权请求因重定向URI被拒绝
+func SetAuditEvent(c *gin.Context, eventType audit.EventType, details map[string]interface{}) {
+	c.Set(contextKeyAuditEvent, eventType)
+	c.Set(contextKeyAuditDetails, details)
+}
+
 // EventTypeStrategy 事件类型策略接口
 type EventTypeStrategy interface {
 	DetermineEventType(path string, method string) audit.EventType
@@ -146,6 +160,13 @@ func (m *AuditMiddleware) Handle() gin.HandlerFunc {
 				"headers":     getHeaders(c.Request),
 			},
 		}
+		if eventType, ok := c.Get(contextKeyAuditEvent); ok {
+			log.EventType = eventType.(audit.EventType)
+			details, _ := c.Get(contextKeyAuditDetails)
+			for k, v := range details.(map[string]interface{}) {
+				log.Details[k] = v
+			}
+		}
 
 		// 写入审计日志
 		if err := m.writer.Write(log); err != nil {
@@ -16,6 +16,10 @@ import (
 const (
 	// BearerSchema Bearer认证方案
 	BearerSchema = "Bearer "
+	// DPoPSchema DPoP认证方案(RFC 9449)，绑定DPoP公钥的令牌必须使用
+	DPoPSchema = "DPoP "
+	// HeaderDPoP 携带DPoP证明的请求头
+	HeaderDPoP = "DPoP"
 	// ContextKeyUser 上下文中用户信息的键
 	ContextKeyUser = "user"
 	// CookieAccessToken Cookie中访问令牌的键
@@ -25,13 +29,15 @@ const (
 // AuthMiddleware 认证中间件
 type AuthMiddleware struct {
 	tokenService service.TokenService
+	dpopService  service.DPoPService
 	enabled      bool
 }
 
 // NewAuthMiddleware 创建认证中间件实例
-func NewAuthMiddleware(tokenService service.TokenService, enabled bool) *AuthMiddleware {
+func NewAuthMiddleware(tokenService service.TokenService, dpopService service.DPoPService, enabled bool) *AuthMiddleware {
 	return &AuthMiddleware{
 		tokenService: tokenService,
+		dpopService:  dpopService,
 		enabled:      enabled,
 	}
 }
@@ -47,15 +53,23 @@ func (m *AuthMiddleware) HandleAuth() gin.HandlerFunc {
 		}
 
 		// 获取token
-		token := m.extractToken(c)
+		token, dpop := m.extractToken(c)
 		if token == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
 			c.Abort()
 			return
 		}
 
-		// 验证token
-		claims, err := m.tokenService.ValidateToken(c.Request.Context(), token, model.AccessToken)
+		// 验证token，限定了受众的令牌必须面向本服务
+		claims, err := m.tokenService.ValidateTokenForAudience(c.Request.Context(), token, model.AccessToken, "")
+		if err == service.ErrInvalidToken {
+			// 客户端服务令牌不代表任何用户，不能访问用户接口
+			if _, clientErr := m.tokenService.ValidateToken(c.Request.Context(), token, model.ClientAccessToken); clientErr == nil {
+				c.JSON(http.StatusForbidden, gin.H{"error": "client token not allowed"})
+				c.Abort()
+				return
+			}
+		}
 		if err != nil {
 			log.Printf("Token validation failed: %v", err)
 			switch err {
@@ -65,6 +79,8 @@ func (m *AuthMiddleware) HandleAuth() gin.HandlerFunc {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "token expired"})
 			case service.ErrTokenRevoked:
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
+			case service.ErrInvalidAudience:
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid audience"})
 			default:
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate token"})
 			}
@@ -72,6 +88,19 @@ func (m *AuthMiddleware) HandleAuth() gin.HandlerFunc {
 			return
 		}
 
+		// 绑定DPoP公钥的令牌还需验证请求携带的证明
+		if err := m.checkDPoP(c, token, dpop, claims); err != nil {
+			log.Printf("DPoP validation failed: %v", err)
+			if err == service.ErrInvalidDPoPProof {
+				c.Header("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid dpop proof"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate token"})
+			}
+			c.Abort()
+			return
+		}
+
 		// 设置过期时间响应头
 		remainingTime := time.Until(claims.GetExpiresAt())
 		if remainingTime > 0 {
@@ -89,23 +118,100 @@ func (m *AuthMiddleware) HandleAuth() gin.HandlerFunc {
 	}
 }
 
-// extractToken 从请求中提取token
-func (m *AuthMiddleware) extractToken(c *gin.Context) string {
+// HandleOptionalAuth 处理可选认证
+// 携带有效的访问令牌时将用户信息存入上下文，未携带或令牌无效时以未登录身份继续处理
+func (m *AuthMiddleware) HandleOptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.enabled {
+			c.Next()
+			return
+		}
+
+		token, dpop := m.extractToken(c)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		claims, err := m.tokenService.ValidateTokenForAudience(c.Request.Context(), token, model.AccessToken, "")
+		if err == nil {
+			err = m.checkDPoP(c, token, dpop, claims)
+		}
+		if err != nil {
+			log.Printf("Optional token validation failed: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Set(ContextKeyUser, claims)
+		c.Set("user_id", claims.UserID)
+		c.Set("app_id", claims.AppID)
+		c.Set("username", claims.Username)
+		c.Next()
+	}
+}
+
+// extractToken 从请求中提取token，dpop表示令牌以DPoP方案携带
+func (m *AuthMiddleware) extractToken(c *gin.Context) (token string, dpop bool) {
 	// 1. 尝试从Authorization头获取
 	auth := c.GetHeader("Authorization")
 	if auth != "" && strings.HasPrefix(auth, BearerSchema) {
 		log.Printf("Found token in Authorization header: %s", auth[len(BearerSchema):])
-		return auth[len(BearerSchema):]
+		return auth[len(BearerSchema):], false
+	}
+	if auth != "" && strings.HasPrefix(auth, DPoPSchema) {
+		log.Printf("Found DPoP token in Authorization header: %s", auth[len(DPoPSchema):])
+		return auth[len(DPoPSchema):], true
 	}
 
 	// 2. 尝试从Cookie获取
 	if cookie, err := c.Cookie(CookieAccessToken); err == nil {
 		log.Printf("Found token in Cookie: %s", cookie)
-		return cookie
+		return cookie, false
 	} else {
 		log.Printf("No token found in Cookie, error: %v", err)
 	}
 
 	log.Printf("No token found in request")
-	return ""
+	return "", false
+}
+
+// checkDPoP 校验令牌的DPoP绑定(RFC 9449 7)
+func (m *AuthMiddleware) checkDPoP(c *gin.Context, token string, dpop bool, claims *model.TokenClaims) error {
+	var presentation *model.DPoPPresentation
+	if dpop {
+		presentation = NewDPoPPresentation(c)
+	}
+	return m.dpopService.VerifyTokenBinding(c.Request.Context(), token, claims, presentation)
+}
+
+// NewDPoPPresentation 根据请求构建令牌的DPoP携带信息
+func NewDPoPPresentation(c *gin.Context) *model.DPoPPresentation {
+	return &model.DPoPPresentation{
+		Proof:  DPoPProof(c),
+		Method: c.Request.Method,
+		URL:    RequestURL(c),
+	}
+}
+
+// DPoPProof 获取请求携带的DPoP证明，出现多个DPoP请求头时合并返回，由证明验证拒绝
+func DPoPProof(c *gin.Context) string {
+	return strings.Join(c.Request.Header.Values(HeaderDPoP), ",")
+}
+
+// RequestURL 获取请求的URL(不含查询参数)，用于与DPoP证明的htu比较
+// 部署在反向代理之后时按X-Forwarded-Proto与X-Forwarded-Host还原客户端访问的地址
+func RequestURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	host := c.Request.Host
+	if forwardedHost := c.GetHeader("X-Forwarded-Host"); forwardedHost != "" {
+		host = forwardedHost
+	}
+	return scheme + "://" + host + c.Request.URL.Path
 }
@@ -54,7 +54,7 @@ func (m *SuperAdminMiddleware) CheckSuperAdmin() gin.HandlerFunc {
 		}
 
 		// 验证token
-		claims, err := m.tokenService.ValidateToken(context.Background(), token, model.AccessToken)
+		claims, err := m.tokenService.ValidateTokenForAudience(context.Background(), token, model.AccessToken, "")
 		if err != nil {
 			api.Error(c, http.StatusUnauthorized, "访问令牌验证失败", err)
 			c.Abort()
@@ -23,6 +23,8 @@ type Router struct {
 	ruleHandler               *v1.RuleHandler
 	oauthClientHandler        *v1.OAuthClientHandler
 	authzHandler              *v1.AuthorizationHandler
+	hostedHandler             *v1.HostedHandler
+	clientRegistrationHandler *v1.ClientRegistrationHandler
 	profileHandler            *v1.ProfileHandler
 	fileHandler               *v1.FileHandler
 	oidcHandler               *v1.OIDCHandler
@@ -46,6 +48,8 @@ func NewRouter(
 	ruleHandler *v1.RuleHandler,
 	oauthClientHandler *v1.OAuthClientHandler,
 	authzHandler *v1.AuthorizationHandler,
+	hostedHandler *v1.HostedHandler,
+	clientRegistrationHandler *v1.ClientRegistrationHandler,
 	profileHandler *v1.ProfileHandler,
 	fileHandler *v1.FileHandler,
 	oidcHandler *v1.OIDCHandler,
@@ -67,6 +71,8 @@ func NewRouter(
 		ruleHandler:               ruleHandler,
 		oauthClientHandler:        oauthClientHandler,
 		authzHandler:              authzHandler,
+		hostedHandler:             hostedHandler,
+		clientRegistrationHandler: clientRegistrationHandler,
 		profileHandler:            profileHandler,
 		fileHandler:               fileHandler,
 		oidcHandler:               oidcHandler,
@@ -105,6 +111,8 @@ func (r *Router) RegisterRoutes() {
 		r.registerOAuthRoutes(api)
 		// 注册OAuth授权相关路由
 		r.registerAuthorizationRoutes(api)
+		// 注册动态客户端注册相关路由
+		r.registerClientRegistrationRoutes(api)
 		// 注册Profile相关路由
 		r.registerProfileRoutes(api)
 		// 注册文件相关路由
@@ -124,6 +132,9 @@ func (r *Router) RegisterRoutes() {
 	// OIDC发现端点（必须在根路径）
 	r.engine.GET("/.well-known/openid-configuration", r.oidcHandler.GetConfiguration)
 	r.engine.GET("/.well-known/jwks.json", r.oidcHandler.GetJWKS)
+
+	// 浏览器授权端点与托管登录页面（发现文档中的authorization_endpoint）
+	r.hostedHandler.Register(r.engine, r.authMiddleware)
 }
 
 // registerAuthRoutes 注册认证相关路由
@@ -206,6 +217,11 @@ func (r *Router) registerAuthorizationRoutes(group *gin.RouterGroup) {
 	r.authzHandler.Register(group, r.authMiddleware)
 }
 
+// registerClientRegistrationRoutes 注册动态客户端注册相关路由
+func (r *Router) registerClientRegistrationRoutes(group *gin.RouterGroup) {
+	r.clientRegistrationHandler.Register(group, r.authMiddleware)
+}
+
 // registerProfileRoutes 注册Profile相关路由
 func (r *Router) registerProfileRoutes(group *gin.RouterGroup) {
 	r.profileHandler.Register(group, r.authMiddleware)
@@ -219,6 +235,11 @@ func (r *Router) registerFileRoutes(group *gin.RouterGroup) {
 // registerOIDCRoutes 注册OIDC相关路由
 func (r *Router) registerOIDCRoutes(group *gin.RouterGroup) {
 	r.oidcHandler.Register(group, r.authMiddleware)
+
+	// 签名密钥管理需要超级管理员权限
+	keys := group.Group("/oidc/keys")
+	keys.Use(r.superAdminMiddleware.CheckSuperAdmin())
+	r.oidcHandler.RegisterKeyRoutes(keys)
 }
 
 // registerAuditRoutes 注册审计相关路由